@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"slices"
 	"strings"
 
 	"github.com/ctrl-alt-boop/dribble/datasource"
@@ -28,13 +29,32 @@ var (
 )
 
 type Client struct {
-	targets map[string]*target.Target
+	targets    map[string]*target.Target
+	middleware []target.Middleware
+	// layers send requests to the targets through the middleware of the client, when it has any.
+	layers map[string]*target.Layer
 }
 
-func NewClient() *Client {
-	return &Client{
+type ClientOption func(*Client)
+
+// WithMiddleware adds middleware to the requests the client sends to its targets, seeing each call
+// before the middleware of the target itself. The targets are left as they are, requests sent to
+// them directly or through another client do not see it.
+func WithMiddleware(middleware ...target.Middleware) ClientOption {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
 		targets: make(map[string]*target.Target),
+		layers:  make(map[string]*target.Layer),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 func (c *Client) String() string {
@@ -64,6 +84,9 @@ func (c *Client) OpenTarget(ctx context.Context, t *target.Target) error {
 		return fmt.Errorf("error opening target: %w", err)
 	}
 	c.targets[t.Name] = t
+	if len(c.middleware) > 0 {
+		c.layers[t.Name] = t.Through(c.middleware...)
+	}
 
 	return nil
 }
@@ -125,6 +148,7 @@ func (c *Client) CloseTarget(ctx context.Context, targetName string) error {
 		return fmt.Errorf("error closing executor for target: %s", targetName)
 	}
 	delete(c.targets, targetName)
+	delete(c.layers, targetName)
 	return nil
 }
 
@@ -142,17 +166,77 @@ func (c *Client) CloseTargets(ctx context.Context, targets ...string) error {
 
 type ResponseHandler func(*request.Response)
 
+// sender is a target as the client sends requests to it, either a *target.Target or
+// a *target.Layer adding the middleware of the client.
+type sender interface {
+	Begin(ctx context.Context, opts datasource.TxOptions) (*target.Tx, error)
+	Request(ctx context.Context, req datasource.Request) (chan *request.Response, error)
+	PerformWithHandler(ctx context.Context, handler func(*request.Response), req datasource.Request) error
+	RequestWithHandler(ctx context.Context, handler func(*request.Response), req datasource.Request) error
+}
+
+func (c *Client) sender(targetName string) (sender, bool) {
+	if layer, ok := c.layers[targetName]; ok {
+		return layer, true
+	}
+	t, ok := c.targets[targetName]
+	if !ok || t == nil {
+		return nil, false
+	}
+	return t, true
+}
+
+// senders returns the senders of every target.
+func (c *Client) senders() []sender {
+	senders := make([]sender, 0, len(c.targets))
+	for name := range c.targets {
+		if sender, ok := c.sender(name); ok {
+			senders = append(senders, sender)
+		}
+	}
+	return senders
+}
+
+func (c *Client) Begin(ctx context.Context, targetName string, opts datasource.TxOptions) (*target.Tx, error) {
+	requestTarget, ok := c.sender(targetName)
+	if !ok {
+		return nil, ErrTargetNotFound(targetName)
+	}
+	return requestTarget.Begin(ctx, opts)
+}
+
 func (c *Client) Request(ctx context.Context, targetName string, request datasource.Request) (chan *request.Response, error) {
-	requestTarget, ok := c.targets[targetName]
-	if !ok || requestTarget == nil {
+	requestTarget, ok := c.sender(targetName)
+	if !ok {
 		return nil, ErrTargetNotFound(targetName)
 	}
 	return requestTarget.Request(ctx, request)
 }
 
+// Cancel cancels the running request with requestID on the target.
+func (c *Client) Cancel(targetName string, requestID int64) error {
+	requestTarget, ok := c.targets[targetName]
+	if !ok || requestTarget == nil {
+		return ErrTargetNotFound(targetName)
+	}
+	return requestTarget.Cancel(requestID)
+}
+
+// ActiveRequests returns the requests running on every target, in the order they started.
+func (c *Client) ActiveRequests() []target.ActiveRequest {
+	var requests []target.ActiveRequest
+	for _, t := range c.targets {
+		requests = append(requests, t.ActiveRequests()...)
+	}
+	slices.SortFunc(requests, func(a, b target.ActiveRequest) int {
+		return a.Started.Compare(b.Started)
+	})
+	return requests
+}
+
 // Non-Blocking
 func (c *Client) RequestWithHandler(ctx context.Context, handler ResponseHandler, targetName string, req datasource.Request) error {
-	requestTarget, ok := c.targets[targetName]
+	requestTarget, ok := c.sender(targetName)
 	if !ok {
 		return ErrTargetNotFound(targetName)
 	}
@@ -169,7 +253,7 @@ func (c *Client) RequestForAll(ctx context.Context, req datasource.Request) (cha
 	responseChan := make(chan *request.Response, len(c.targets))
 	defer close(responseChan)
 
-	for _, target := range c.targets {
+	for _, target := range c.senders() {
 		respChan, err := target.Request(ctx, req)
 		if err != nil {
 			errs = errors.Join(errs, err)
@@ -182,7 +266,7 @@ func (c *Client) RequestForAll(ctx context.Context, req datasource.Request) (cha
 
 // Blocking
 func (c *Client) PerformWithHandler(ctx context.Context, handler ResponseHandler, targetName string, req datasource.Request) error {
-	requestTarget, ok := c.targets[targetName]
+	requestTarget, ok := c.sender(targetName)
 	if !ok {
 		return ErrTargetNotFound(targetName)
 	}
@@ -199,7 +283,7 @@ func (c *Client) PerformForAll(ctx context.Context, handler ResponseHandler, req
 	responseChan := make(chan *request.Response, len(c.targets))
 	defer close(responseChan)
 
-	for _, target := range c.targets {
+	for _, target := range c.senders() {
 		respChan, err := target.Request(ctx, req)
 		if err != nil {
 			errs = errors.Join(errs, err)
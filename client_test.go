@@ -1,17 +1,33 @@
 package dribble_test
 
 import (
+	"bytes"
 	"context"
+	gosql "database/sql"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/ctrl-alt-boop/dribble"
+	"github.com/ctrl-alt-boop/dribble/copy"
+	"github.com/ctrl-alt-boop/dribble/datasource"
 	"github.com/ctrl-alt-boop/dribble/dsn"
+	"github.com/ctrl-alt-boop/dribble/importer"
+	"github.com/ctrl-alt-boop/dribble/migrate"
 	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/ctrl-alt-boop/dribble/result"
+	"github.com/ctrl-alt-boop/dribble/schema"
 	"github.com/ctrl-alt-boop/dribble/sql"
 	"github.com/ctrl-alt-boop/dribble/target"
+	gosqlite3 "github.com/mattn/go-sqlite3"
 )
 
 // type MockDriver struct{}
@@ -237,3 +253,1525 @@ func TestGetCount(t *testing.T) {
 		fmt.Printf("%s, %T\n%s", response.Status, response.Body, response.Body)
 	}
 }
+
+func TestStream(t *testing.T) {
+	sqlite3Target, err := target.New("sqlite_stream", dsn.SQLite3DSN("dribble_test.db", dsn.SQLite3ReadOnly()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sqlite3Target.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite3Target.Close(context.Background())
+
+	respChan, err := sqlite3Target.Request(context.Background(), request.Stream(request.NewReadTableNames(), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chunks []*result.Chunk
+	for response := range respChan {
+		if response.Error != nil {
+			t.Fatal(response.Error)
+		}
+		chunk, ok := response.Body.(*result.Chunk)
+		if !ok {
+			t.Fatalf("response body is %T, not *result.Chunk", response.Body)
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.Index != i {
+			t.Errorf("chunk %d has index %d", i, chunk.Index)
+		}
+		if len(chunk.Columns) != 1 {
+			t.Errorf("chunk %d has %d columns, want 1", i, len(chunk.Columns))
+		}
+	}
+	if !chunks[2].Final || len(chunks[2].Rows) != 0 {
+		t.Errorf("last chunk should be final and empty, got %+v", chunks[2])
+	}
+}
+
+func newScratchTarget(t *testing.T, schema string) (*target.Target, *gosql.DB) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scratch.db")
+	db, err := gosql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	scratchTarget, err := target.New("scratch", dsn.SQLite3DSN(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scratchTarget.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { scratchTarget.Close(context.Background()) })
+	return scratchTarget, db
+}
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, db := newScratchTarget(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+
+	first, _ := sql.FromString("INSERT INTO users (id, name) VALUES (?, ?)", 1, "first")
+	duplicate, _ := sql.FromString("INSERT INTO users (id, name) VALUES (?, ?)", 1, "duplicate")
+
+	err := scratchTarget.PerformWithHandler(ctx, func(*request.Response) {}, request.Transaction(first, duplicate))
+	if err == nil {
+		t.Fatal("expected the transaction to fail")
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("transaction was not rolled back, %d rows", count)
+	}
+
+	tx, err := scratchTarget.Begin(ctx, datasource.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.PerformWithHandler(ctx, func(*request.Response) {}, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("got %d rows after commit, want 1", count)
+	}
+
+	// The members of a batch in a transaction are sent one after another.
+	var (
+		mu               sync.Mutex
+		running, overlap int
+	)
+	err = scratchTarget.Update(ctx, target.WithMiddleware(func(next target.Handler) target.Handler {
+		return func(ctx context.Context, call *target.Call) (any, error) {
+			mu.Lock()
+			running++
+			overlap = max(overlap, running)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			defer func() {
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}()
+			return next(ctx, call)
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err = scratchTarget.Begin(ctx, datasource.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	var batch request.BatchRequest
+	for id := 2; id <= 4; id++ {
+		insert, _ := sql.FromString("INSERT INTO users (id, name) VALUES (?, ?)", id, "batched")
+		batch = append(batch, insert)
+	}
+	if err := tx.PerformWithHandler(ctx, func(*request.Response) {}, batch); err != nil {
+		t.Fatal(err)
+	}
+	if overlap != 1 {
+		t.Errorf("%d members of the batch ran at once, want 1", overlap)
+	}
+}
+
+func TestSelectBuilder(t *testing.T) {
+	sqlite3Target, err := target.New("sqlite_select", dsn.SQLite3DSN("dribble_test.db", dsn.SQLite3ReadOnly()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sqlite3Target.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite3Target.Close(context.Background())
+
+	r := sql.Select("id", "name").From("users").OrderBy("id", false).Limit(2).ToRequest()
+	err = sqlite3Target.PerformWithHandler(context.Background(), func(response *request.Response) {
+		table, ok := response.Body.(*result.Table)
+		if !ok {
+			t.Fatalf("response body is %T, not *result.Table", response.Body)
+		}
+		if table.NumRows() != 2 || table.NumColumns() != 2 {
+			t.Errorf("got %dx%d table, want 2x2", table.NumRows(), table.NumColumns())
+		}
+	}, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSelectCompound(t *testing.T) {
+	sqlite3Target, err := target.New("sqlite_compound", dsn.SQLite3DSN("dribble_test.db", dsn.SQLite3ReadOnly()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sqlite3Target.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite3Target.Close(context.Background())
+
+	r := sql.Select("id", sql.Window("ROW_NUMBER()").OrderBy("age", true).As("position")).From("adults").
+		With("adults", sql.SelectAll().From("users").Where(sql.Ge("age", 0))).
+		UnionAll(sql.Select("id", "id").From("users")).
+		ToRequest()
+	err = sqlite3Target.PerformWithHandler(context.Background(), func(response *request.Response) {
+		table, ok := response.Body.(*result.Table)
+		if !ok {
+			t.Fatalf("response body is %T, not *result.Table", response.Body)
+		}
+		if table.NumRows() != 6 || table.NumColumns() != 2 {
+			t.Errorf("got %dx%d table, want 6x2", table.NumRows(), table.NumColumns())
+		}
+	}, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpsertReturning(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, db := newScratchTarget(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+
+	upsert := func(name string) *result.Table {
+		t.Helper()
+		var table *result.Table
+		r := sql.Insert("users").Columns("id", "name").Values(1, name).
+			OnConflict("id").DoUpdate().
+			Returning("name").
+			ToRequest()
+		err := scratchTarget.PerformWithHandler(ctx, func(response *request.Response) {
+			write, ok := response.Body.(*result.Write)
+			if !ok {
+				t.Fatalf("response body is %T, not *result.Write", response.Body)
+			}
+			if write.RowsAffected != 1 {
+				t.Errorf("rows affected %d, want 1", write.RowsAffected)
+			}
+			table = write.Returning
+		}, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return table
+	}
+
+	upsert("first")
+	table := upsert("second")
+	if table.NumRows() != 1 || table.NumColumns() != 1 {
+		t.Fatalf("got %dx%d table, want 1x1", table.NumRows(), table.NumColumns())
+	}
+
+	var count int
+	var name string
+	if err := db.QueryRow("SELECT COUNT(*), MAX(name) FROM users").Scan(&count, &name); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || name != "second" {
+		t.Fatalf("got %d rows named %q, want 1 named second", count, name)
+	}
+}
+
+func TestWriteResult(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, _ := newScratchTarget(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER)")
+
+	write := func(req datasource.Request) *result.Write {
+		t.Helper()
+		var write *result.Write
+		err := scratchTarget.PerformWithHandler(ctx, func(response *request.Response) {
+			body, ok := response.Body.(*result.Write)
+			if !ok {
+				t.Fatalf("response body is %T, not *result.Write", response.Body)
+			}
+			write = body
+		}, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return write
+	}
+
+	inserted := write(sql.Insert("users").Columns("name", "age").Values("ada", 36).Values("bob", 41).ToRequest())
+	if inserted.RowsAffected != 2 || !inserted.LastInsertID.Valid || inserted.LastInsertID.V != 2 {
+		t.Errorf("insert = %+v", inserted)
+	}
+	if !strings.HasPrefix(inserted.Statement, `INSERT INTO "users"`) || !slices.Equal(inserted.Args, []any{"ada", 36, "bob", 41}) {
+		t.Errorf("insert ran %q with %v", inserted.Statement, inserted.Args)
+	}
+	if inserted.Elapsed <= 0 || inserted.Returning != nil {
+		t.Errorf("insert = %+v", inserted)
+	}
+
+	updated := write(sql.Update("users").Set("age", 37).Where(sql.Eq("name", "ada")).ToRequest())
+	if updated.RowsAffected != 1 || updated.LastInsertID.Valid {
+		t.Errorf("update = %+v", updated)
+	}
+
+	deleted := write(sql.DeleteFrom("users").Where(sql.Gt("age", 0)).Returning("id").ToRequest())
+	if deleted.RowsAffected != 2 || deleted.Returning == nil || deleted.Returning.NumRows() != 2 {
+		t.Errorf("delete = %+v", deleted)
+	}
+
+	executed := write(request.Exec("CREATE INDEX users_name ON users (name)"))
+	if executed.Statement != "CREATE INDEX users_name ON users (name)" || executed.LastInsertID.Valid {
+		t.Errorf("exec = %+v", executed)
+	}
+}
+
+func TestReadSchema(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, _ := newScratchTarget(t, `CREATE TABLE accounts (
+	id INTEGER PRIMARY KEY,
+	email VARCHAR(120) NOT NULL UNIQUE COLLATE NOCASE,
+	balance DECIMAL(10, 2) DEFAULT 0,
+	doubled DECIMAL(10, 2) GENERATED ALWAYS AS (balance * 2) VIRTUAL
+);
+CREATE TABLE memberships (
+	account_id INTEGER NOT NULL,
+	group_id INTEGER NOT NULL,
+	PRIMARY KEY (group_id, account_id)
+)`)
+
+	read := func(req datasource.Request) any {
+		t.Helper()
+		var body any
+		if err := scratchTarget.PerformWithHandler(ctx, func(response *request.Response) { body = response.Body }, req); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	table, ok := read(request.NewReadTableSchema("", "accounts")).(*schema.Table)
+	if !ok {
+		t.Fatalf("response body is not a *schema.Table")
+	}
+	if len(table.Fields) != 4 {
+		t.Fatalf("got %d fields, want 4", len(table.Fields))
+	}
+	id := table.Field("id").Properties
+	if !id.PrimaryKey || !id.IsAutoIncrement() || id.Nullable {
+		t.Errorf("id: %+v", id)
+	}
+	email := table.Field("email").Properties
+	if email.Type != "VARCHAR" || email.Length == nil || *email.Length != 120 || email.Nullable || !email.Unique || email.Overrides.Collation != "NOCASE" {
+		t.Errorf("email: %+v", email)
+	}
+	balance := table.Field("balance").Properties
+	if balance.Precision == nil || *balance.Precision != 10 || *balance.Scale != 2 || balance.Default == nil || *balance.Default != "0" {
+		t.Errorf("balance: %+v", balance)
+	}
+	doubled := table.Field("doubled").Properties
+	if !doubled.Virtual || doubled.Generated == nil || *doubled.Generated != "balance * 2" {
+		t.Errorf("doubled: %+v", doubled)
+	}
+
+	column, ok := read(request.NewReadColumnSchema("", "memberships", "account_id")).(*schema.Field)
+	if !ok || !column.Properties.PrimaryKey || column.Properties.IsAutoIncrement() {
+		t.Errorf("account_id: %+v", column)
+	}
+
+	database, ok := read(request.NewReadDatabaseSchema("")).(*schema.Database)
+	if !ok || len(database.Tables) != 2 {
+		t.Fatalf("got %+v, want a database of 2 tables", database)
+	}
+	if keys := database.Tables.AsMap()["memberships"].Properties.PrimaryKeys; fmt.Sprint(keys) != "[group_id account_id]" {
+		t.Errorf("got primary keys %v, want [group_id account_id]", keys)
+	}
+	if indexes := database.Tables.AsMap()["accounts"].Properties.Indexes; len(indexes) != 1 || !indexes[0].Unique {
+		t.Errorf("got indexes %+v, want the unique index of email", indexes)
+	}
+}
+
+func TestReadKeys(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, _ := newScratchTarget(t, `CREATE TABLE groups (
+	id INTEGER NOT NULL,
+	region TEXT NOT NULL,
+	name TEXT UNIQUE,
+	PRIMARY KEY (region, id)
+);
+CREATE TABLE members (
+	id INTEGER PRIMARY KEY,
+	region TEXT NOT NULL,
+	group_id INTEGER NOT NULL,
+	FOREIGN KEY (region, group_id) REFERENCES groups ON DELETE CASCADE
+);
+CREATE INDEX members_group ON members (group_id, region)`)
+
+	read := func(req datasource.Request) any {
+		t.Helper()
+		var body any
+		if err := scratchTarget.PerformWithHandler(ctx, func(response *request.Response) { body = response.Body }, req); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	indexes, ok := read(request.NewReadIndexes("", "members")).([]*schema.Index)
+	if !ok || len(indexes) != 1 {
+		t.Fatalf("got indexes %+v, want 1", indexes)
+	}
+	if index := indexes[0]; index.Name != "members_group" || index.Unique || fmt.Sprint(index.Fields) != "[group_id region]" {
+		t.Errorf("got index %+v", index)
+	}
+
+	foreignKeys, ok := read(request.NewReadForeignKeys("", "members")).([]*schema.ForeignKey)
+	if !ok || len(foreignKeys) != 1 {
+		t.Fatalf("got foreign keys %+v, want 1", foreignKeys)
+	}
+	foreignKey := foreignKeys[0]
+	if foreignKey.ToTable != "groups" || fmt.Sprint(foreignKey.Fields) != "[region group_id]" ||
+		fmt.Sprint(foreignKey.ToFields) != "[region id]" || foreignKey.OnDelete != "CASCADE" || foreignKey.OnUpdate != "NO ACTION" {
+		t.Errorf("got foreign key %+v", foreignKey)
+	}
+
+	constraints, ok := read(request.NewReadConstraints("", "groups")).([]*schema.Constraint)
+	if !ok {
+		t.Fatalf("response body is not []*schema.Constraint")
+	}
+	types := map[string][]string{}
+	for _, constraint := range constraints {
+		types[constraint.Type] = constraint.Fields
+	}
+	if fmt.Sprint(types["PRIMARY KEY"]) != "[region id]" || fmt.Sprint(types["UNIQUE"]) != "[name]" {
+		t.Errorf("got constraints %v", types)
+	}
+}
+
+func TestReadViewsAndTriggers(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, _ := newScratchTarget(t, `CREATE TABLE accounts (id INTEGER PRIMARY KEY, email TEXT, updated TEXT);
+CREATE VIEW emails AS SELECT email FROM accounts;
+CREATE TRIGGER touch
+AFTER UPDATE OF email ON accounts
+BEGIN
+	UPDATE accounts SET updated = 'now' WHERE id = NEW.id;
+END;
+CREATE TRIGGER IF NOT EXISTS "on insert after" -- runs AFTER nothing
+/* INSTEAD OF */ DELETE ON accounts
+BEGIN
+	SELECT 1;
+END;
+CREATE TRIGGER main.[redirect] INSTEAD
+	OF INSERT ON emails
+BEGIN
+	INSERT INTO accounts (email) VALUES (NEW.email);
+END`)
+
+	read := func(req datasource.Request) any {
+		t.Helper()
+		var body any
+		if err := scratchTarget.PerformWithHandler(ctx, func(response *request.Response) { body = response.Body }, req); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	views, ok := read(request.NewReadViews("")).(schema.Views)
+	if !ok || len(views) != 1 {
+		t.Fatalf("got views %+v, want 1", views)
+	}
+	if view := views[0]; view.Name != "emails" || view.Properties.Materialized ||
+		view.Properties.Definition != "CREATE VIEW emails AS SELECT email FROM accounts" {
+		t.Errorf("got view %+v", view)
+	}
+
+	triggers, ok := read(request.NewReadTriggers("")).(schema.Triggers)
+	if !ok || len(triggers) != 3 {
+		t.Fatalf("got triggers %+v, want 3", triggers)
+	}
+	want := []string{
+		"on insert after accounts BEFORE DELETE",
+		"redirect emails INSTEAD OF INSERT",
+		"touch accounts AFTER UPDATE",
+	}
+	for i, trigger := range triggers {
+		if got := fmt.Sprintf("%s %s %s %s", trigger.Name, trigger.Properties.Table, trigger.Properties.Timing, trigger.Properties.Event); got != want[i] {
+			t.Errorf("got trigger %q, want %q", got, want[i])
+		}
+	}
+
+	if routines, ok := read(request.NewReadRoutines("")).(schema.Functions); !ok || len(routines) != 0 {
+		t.Errorf("got routines %+v, want none", routines)
+	}
+}
+
+func TestSchemaDiff(t *testing.T) {
+	ctx := context.Background()
+	client := dribble.NewClient()
+	open := func(name, ddl string) {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), name+".db")
+		db, err := gosql.Open("sqlite3", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		if _, err := db.Exec(ddl); err != nil {
+			t.Fatal(err)
+		}
+		diffTarget, err := target.New(name, dsn.SQLite3DSN(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.OpenTarget(ctx, diffTarget); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { client.CloseTarget(ctx, name) })
+	}
+	open("staging", `CREATE TABLE groups (id INTEGER PRIMARY KEY);
+CREATE TABLE accounts (
+	id INTEGER PRIMARY KEY,
+	email VARCHAR(100) NOT NULL,
+	nickname TEXT,
+	group_id INTEGER REFERENCES groups (id)
+);
+CREATE INDEX accounts_email ON accounts (email);
+CREATE TABLE legacy (id INTEGER)`)
+	open("prod", `CREATE TABLE groups (id INTEGER PRIMARY KEY);
+CREATE TABLE accounts (
+	id INTEGER PRIMARY KEY,
+	email VARCHAR(255),
+	status TEXT DEFAULT 'active',
+	group_id INTEGER REFERENCES groups (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX accounts_email ON accounts (email);
+CREATE TABLE orders (id INTEGER PRIMARY KEY)`)
+
+	report, err := schema.Diff(ctx, client, "staging", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `--- staging
++++ prod
+~ column accounts.email type: VARCHAR(100) -> VARCHAR(255)
+~ column accounts.email nullable: false -> true
+- column accounts.nickname
++ column accounts.status
+~ index accounts.accounts_email definition: (email) -> UNIQUE (email)
+~ foreign key accounts.fk_0 on delete: NO ACTION -> CASCADE
+- table legacy
++ table orders
+`
+	if got := report.String(); got != want {
+		t.Errorf("got report\n%s\nwant\n%s", got, want)
+	}
+
+	same, err := schema.Diff(ctx, client, "staging", "staging", schema.WithTables("accounts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !same.Empty() {
+		t.Errorf("got changes %v comparing a target with itself", same.Changes)
+	}
+}
+
+func TestSchemaDiffTargets(t *testing.T) {
+	ctx := context.Background()
+	staging, _ := newScratchTarget(t, `CREATE TABLE users (id INTEGER PRIMARY KEY);
+CREATE TABLE transfers (
+	id INTEGER,
+	source INTEGER REFERENCES users (id),
+	target INTEGER REFERENCES users (id),
+	PRIMARY KEY (id)
+)`)
+	prod, _ := newScratchTarget(t, `CREATE TABLE users (id INTEGER PRIMARY KEY);
+CREATE TABLE transfers (
+	id INTEGER,
+	source INTEGER REFERENCES users (id),
+	target INTEGER REFERENCES users (id) ON DELETE CASCADE,
+	PRIMARY KEY (id, source)
+)`)
+	if err := prod.Update(ctx, target.WithName("prod")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both foreign keys reference users, they are told apart by their columns.
+	report, err := schema.DiffTargets(ctx, staging, prod, schema.WithTables("transfers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `--- scratch
++++ prod
+~ column transfers.source nullable: true -> false
+~ primary key transfers columns: (id) -> (id, source)
++ index transfers.sqlite_autoindex_transfers_1
+~ foreign key transfers.fk_0 on delete: NO ACTION -> CASCADE
+`
+	if got := report.String(); got != want {
+		t.Errorf("got report\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateDDLRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source, _ := newScratchTarget(t, `CREATE TABLE groups (id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE);
+CREATE TABLE accounts (
+	id INTEGER PRIMARY KEY,
+	email VARCHAR(255) NOT NULL,
+	status TEXT NOT NULL DEFAULT 'active',
+	balance REAL DEFAULT 0,
+	group_id INTEGER REFERENCES groups (id) ON DELETE SET NULL
+);
+CREATE INDEX accounts_email ON accounts (email)`)
+
+	var database *schema.Database
+	err := source.PerformWithHandler(ctx, func(response *request.Response) {
+		database, _ = response.Body.(*schema.Database)
+	}, request.NewReadDatabaseSchema(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddl, err := sql.GenerateDDL(datasource.SQLite3, database.Tables...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ddl.Warnings) != 0 {
+		t.Errorf("got warnings %v, want none", ddl.Warnings)
+	}
+
+	rebuilt, _ := newScratchTarget(t, ddl.String())
+	if err := source.Update(ctx, target.WithName("source")); err != nil {
+		t.Fatal(err)
+	}
+	client := dribble.NewClient()
+	for _, scratchTarget := range []*target.Target{source, rebuilt} {
+		if err := client.OpenTarget(ctx, scratchTarget); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report, err := schema.Diff(ctx, client, "source", "scratch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Empty() {
+		t.Errorf("rebuilt schema differs:\n%s\nfrom\n%s", report, ddl)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, db := newScratchTarget(t, "")
+	migrations := fstest.MapFS{
+		"1_users.up.sql":        {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL);")},
+		"1_users.down.sql":      {Data: []byte("DROP TABLE users;")},
+		"2_user_email.up.sql":   {Data: []byte("ALTER TABLE users ADD COLUMN email TEXT;\nCREATE INDEX users_email ON users (email);")},
+		"2_user_email.down.sql": {Data: []byte("DROP INDEX users_email;\nALTER TABLE users DROP COLUMN email;")},
+		"3_posts.up.sql":        {Data: []byte("CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id));")},
+		"README.md":             {Data: []byte("ignored")},
+	}
+	migrator, err := migrate.New(scratchTarget, migrations, migrate.WithLockTimeout(200*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tableExists := func(name string) bool {
+		var count int
+		if err := db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		return count == 1
+	}
+	applied := func() []uint64 {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var versions []uint64
+		for _, status := range statuses {
+			if status.Applied {
+				versions = append(versions, status.Version)
+			}
+		}
+		return versions
+	}
+
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := applied(); !slices.Equal(got, []uint64{1, 2, 3}) {
+		t.Fatalf("applied after Up = %v", got)
+	}
+	if !tableExists("posts") {
+		t.Fatal("posts was not created")
+	}
+
+	// 3 has no down file.
+	if err := migrator.Down(ctx, 1); !errors.Is(err, migrate.ErrNoDownMigration) {
+		t.Fatalf("Down(1) error = %v, want ErrNoDownMigration", err)
+	}
+	if _, err := db.Exec("DROP TABLE posts; DELETE FROM dribble_migrations WHERE version = 3"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrator.Down(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := applied(); !slices.Equal(got, []uint64{1}) {
+		t.Fatalf("applied after Down(1) = %v", got)
+	}
+	if err := migrator.Goto(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := applied(); !slices.Equal(got, []uint64{1, 2}) {
+		t.Fatalf("applied after Goto(2) = %v", got)
+	}
+	if err := migrator.Goto(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := applied(); len(got) != 0 || tableExists("users") {
+		t.Fatalf("applied after Goto(0) = %v, users exists: %v", got, tableExists("users"))
+	}
+	if err := migrator.Goto(ctx, 7); !errors.Is(err, migrate.ErrUnknownVersion) {
+		t.Fatalf("Goto(7) error = %v, want ErrUnknownVersion", err)
+	}
+
+	// Another runner holding the lock.
+	if _, err := db.Exec("INSERT INTO dribble_migrations_lock (id, locked_at) VALUES (1, CURRENT_TIMESTAMP)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); !errors.Is(err, migrate.ErrLocked) {
+		t.Fatalf("Up error = %v, want ErrLocked", err)
+	}
+	if err := migrator.Down(ctx, -1); err == nil {
+		t.Fatal("Down(-1) succeeded, want an error")
+	}
+
+	// A runner that crashed an hour ago.
+	if _, err := db.Exec("UPDATE dribble_migrations_lock SET locked_at = datetime('now', '-1 hour')"); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Goto(ctx, 1); !errors.Is(err, migrate.ErrLocked) {
+		t.Fatalf("Goto(1) error = %v, want ErrLocked without WithStaleLockAfter", err)
+	}
+	stale, err := migrate.New(scratchTarget, migrations, migrate.WithLockTimeout(200*time.Millisecond), migrate.WithStaleLockAfter(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stale.Goto(ctx, 1); err != nil {
+		t.Fatalf("Goto(1) with a stale lock: %v", err)
+	}
+
+	if _, err := db.Exec("INSERT INTO dribble_migrations_lock (id, locked_at) VALUES (1, CURRENT_TIMESTAMP)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := stale.Goto(ctx, 0); !errors.Is(err, migrate.ErrLocked) {
+		t.Fatalf("Goto(0) error = %v, want ErrLocked for a fresh lock", err)
+	}
+	if err := migrator.ForceUnlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Goto(ctx, 0); err != nil {
+		t.Fatalf("Goto(0) after ForceUnlock: %v", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, db := newScratchTarget(t, "CREATE TABLE events (name TEXT, payload TEXT, score REAL)")
+
+	input := strings.Join([]string{
+		"id,name,joined,active,score,note",
+		"1,Ada,2024-01-02,true,9.5,",
+		`2,"Smith, Bob",2024-01-03 10:00:00,false,7,"says ""hi"""`,
+		"3,Cy,2024-01-04,TRUE,8.25,",
+		"four,Dee,2024-01-05,false,1,",
+		"5,Eve,2024-01-06,false,2",
+		"6,Fay,2024-01-07,true,3,",
+	}, "\n")
+	var progress []importer.Progress
+	users, err := importer.New(scratchTarget, "users",
+		importer.WithCreateTable(),
+		importer.WithSampleSize(3),
+		importer.WithBatchSize(2),
+		importer.WithErrorBudget(2),
+		importer.WithProgress(func(p importer.Progress) { progress = append(progress, p) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := users.Import(ctx, strings.NewReader(input), importer.CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	for _, field := range report.Fields {
+		types = append(types, field.Name+" "+field.Properties.Type)
+	}
+	wantTypes := []string{"id bigint", "name text", "joined timestamp", "active boolean", "score double precision", "note text"}
+	if !slices.Equal(types, wantTypes) {
+		t.Errorf("inferred %v, want %v", types, wantTypes)
+	}
+	if report.Read != 6 || report.Inserted != 4 || len(report.Failed) != 2 {
+		t.Fatalf("read %d, inserted %d, failed %v", report.Read, report.Inserted, report.Failed)
+	}
+	if report.Failed[0].Line != 5 || report.Failed[1].Line != 6 {
+		t.Errorf("failed lines %d and %d, want 5 and 6", report.Failed[0].Line, report.Failed[1].Line)
+	}
+	if len(progress) == 0 || progress[len(progress)-1].Inserted != 4 {
+		t.Errorf("progress %+v", progress)
+	}
+
+	var name, note string
+	var active bool
+	if err := db.QueryRow("SELECT name, note, active FROM users WHERE id = 2").Scan(&name, &note, &active); err != nil {
+		t.Fatal(err)
+	}
+	if name != "Smith, Bob" || note != `says "hi"` || active {
+		t.Errorf("row 2 = %q, %q, %v", name, note, active)
+	}
+
+	// A third failure is over the budget, the table exists now.
+	users, err = importer.New(scratchTarget, "users", importer.WithSampleSize(3), importer.WithErrorBudget(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = users.Import(ctx, strings.NewReader(input+"\nseven,Gus,2024-01-08,true,4,"), importer.CSV)
+	if !errors.Is(err, importer.ErrErrorBudget) {
+		t.Fatalf("error = %v, want ErrErrorBudget", err)
+	}
+
+	events, err := importer.New(scratchTarget, "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ndjson := `{"name":"signup","payload":{"plan":"pro","seats":[1,2]},"score":1}
+{"score":2.5,"name":"login"}
+
+{"name":"logout","payload":null}
+`
+	report, err = events.Import(ctx, strings.NewReader(ndjson), importer.NDJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Inserted != 3 {
+		t.Fatalf("inserted %d events, failed %v", report.Inserted, report.Failed)
+	}
+	var payload string
+	var score float64
+	if err := db.QueryRow("SELECT payload, score FROM events WHERE name = 'signup'").Scan(&payload, &score); err != nil {
+		t.Fatal(err)
+	}
+	if payload != `{"plan":"pro","seats":[1,2]}` || score != 1 {
+		t.Errorf("signup = %q, %v", payload, score)
+	}
+}
+
+func TestImportNumbers(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, db := newScratchTarget(t, "")
+
+	input := strings.Join([]string{
+		"code,ratio,big,amount,count",
+		"007,NaN,99999999999999999999,1.5,1",
+		"12,0.5,1,-2e3,2",
+		"3,Infinity,2,.25,3",
+		"4,0.25,3,4,0x10",
+	}, "\n")
+	numbers, err := importer.New(scratchTarget, "numbers", importer.WithCreateTable(), importer.WithSampleSize(3), importer.WithErrorBudget(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := numbers.Import(ctx, strings.NewReader(input), importer.CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	for _, field := range report.Fields {
+		types = append(types, field.Name+" "+field.Properties.Type)
+	}
+	wantTypes := []string{"code text", "ratio text", "big text", "amount double precision", "count bigint"}
+	if !slices.Equal(types, wantTypes) {
+		t.Errorf("inferred %v, want %v", types, wantTypes)
+	}
+	// Hexadecimal is not read as a number after the sample either
+	if report.Inserted != 3 || len(report.Failed) != 1 || report.Failed[0].Line != 5 {
+		t.Fatalf("inserted %d, failed %v", report.Inserted, report.Failed)
+	}
+	var code, big string
+	if err := db.QueryRow("SELECT code, big FROM numbers WHERE count = 1").Scan(&code, &big); err != nil {
+		t.Fatal(err)
+	}
+	if code != "007" || big != "99999999999999999999" {
+		t.Errorf("row 1 = %q, %q", code, big)
+	}
+}
+
+func TestImportStringsAndZones(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, _ := newScratchTarget(t, "")
+
+	ndjson := `{"code":"123","count":1,"at":"2024-01-02T10:00:00","zoned":"2024-01-02T10:00:00Z"}
+{"code":"124","count":2,"at":"2024-01-03T10:00:00","zoned":"2024-01-03 10:00:00+02:00"}
+{"code":"125","count":"3","at":"2024-01-04T10:00:00","zoned":"2024-01-04"}
+`
+	events, err := importer.New(scratchTarget, "events", importer.WithCreateTable(), importer.WithSampleSize(2), importer.WithErrorBudget(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := events.Import(ctx, strings.NewReader(ndjson), importer.NDJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, field := range report.Fields {
+		types = append(types, field.Name+" "+field.Properties.Type)
+	}
+	// JSON strings are text, whatever they hold
+	wantTypes := []string{"code text", "count bigint", "at text", "zoned text"}
+	if !slices.Equal(types, wantTypes) {
+		t.Errorf("inferred %v, want %v", types, wantTypes)
+	}
+	// A string among the numbers after the sample is not converted
+	if report.Inserted != 2 || len(report.Failed) != 1 || report.Failed[0].Line != 3 {
+		t.Errorf("inserted %d, failed %v", report.Inserted, report.Failed)
+	}
+
+	input := strings.Join([]string{
+		"at,zoned",
+		"2024-01-02 10:00:00,2024-01-02T10:00:00Z",
+		"2024-01-03,2024-01-03 10:00:00",
+	}, "\n")
+	times, err := importer.New(scratchTarget, "times", importer.WithCreateTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err = times.Import(ctx, strings.NewReader(input), importer.CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	types = types[:0]
+	for _, field := range report.Fields {
+		types = append(types, field.Name+" "+field.Properties.Type)
+	}
+	// Any offset in the sample makes a timestamptz
+	wantTypes = []string{"at timestamp", "zoned timestamptz"}
+	if !slices.Equal(types, wantTypes) {
+		t.Errorf("inferred %v, want %v", types, wantTypes)
+	}
+}
+
+func TestCopyTable(t *testing.T) {
+	ctx := context.Background()
+	src, _ := newScratchTarget(t, `CREATE TABLE users (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	score REAL DEFAULT 0,
+	avatar BLOB,
+	upper_name TEXT GENERATED ALWAYS AS (upper(name)) VIRTUAL
+);
+INSERT INTO users (id, name, score, avatar) VALUES
+	(1, 'ada', 9.5, x'89504e47'),
+	(2, 'bob', 7, NULL),
+	(3, 'cy', 8.25, NULL),
+	(4, 'dee', 1, x'00'),
+	(5, 'eve', 2, NULL);`)
+	dst, db := newScratchTarget(t, "")
+
+	report, err := copy.Table(ctx, src, "users", dst, "people",
+		copy.WithColumns(map[string]string{"id": "", "name": "full_name", "avatar": ""}),
+		copy.WithWhere(sql.Ne("name", "bob")),
+		copy.WithBatchSize(2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Created || report.Copied != 4 {
+		t.Fatalf("report = %+v", report)
+	}
+	var name string
+	var avatar []byte
+	if err := db.QueryRow("SELECT full_name, avatar FROM people WHERE id = 1").Scan(&name, &avatar); err != nil {
+		t.Fatal(err)
+	}
+	if name != "ada" || !bytes.Equal(avatar, []byte{0x89, 'P', 'N', 'G'}) {
+		t.Errorf("people 1 = %q, %x", name, avatar)
+	}
+
+	// Resume after 3 into an emptied table, the checkpoint is cleared once done.
+	checkpoint := copy.FileCheckpoint(filepath.Join(t.TempDir(), "users.checkpoint"))
+	if err := checkpoint.Save("3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("DELETE FROM people"); err != nil {
+		t.Fatal(err)
+	}
+	report, err = copy.Table(ctx, src, "users", dst, "people",
+		copy.WithColumns(map[string]string{"id": "", "name": "full_name"}),
+		copy.WithCheckpoint(checkpoint),
+		copy.WithTruncate(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Resumed != "3" || report.Copied != 2 || report.Created {
+		t.Fatalf("report = %+v", report)
+	}
+	if _, ok, _ := checkpoint.Load(); ok {
+		t.Error("checkpoint was not cleared")
+	}
+
+	// A batch failing in the middle of a copy is rolled back along with its checkpoint, the copy
+	// resumes after the batch before it.
+	if _, err := db.Exec("DELETE FROM people; INSERT INTO people (id, full_name) VALUES (3, 'taken')"); err != nil {
+		t.Fatal(err)
+	}
+	copyPeople := func() (*copy.Report, error) {
+		return copy.Table(ctx, src, "users", dst, "people",
+			copy.WithColumns(map[string]string{"id": "", "name": "full_name"}),
+			copy.WithCheckpointTable("copy_checkpoints"),
+			copy.WithBatchSize(2),
+		)
+	}
+	if _, err := copyPeople(); err == nil {
+		t.Fatal("copy into a taken key succeeded")
+	}
+	var last string
+	if err := db.QueryRow("SELECT last_key FROM copy_checkpoints WHERE name = 'people'").Scan(&last); err != nil || last != "2" {
+		t.Fatalf("checkpoint = %q, %v, want 2", last, err)
+	}
+	if _, err := db.Exec("DELETE FROM people WHERE id = 3"); err != nil {
+		t.Fatal(err)
+	}
+	report, err = copyPeople()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Resumed != "2" || report.Copied != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+	if err := db.QueryRow("SELECT last_key FROM copy_checkpoints").Scan(&last); !errors.Is(err, gosql.ErrNoRows) {
+		t.Errorf("checkpoint %q was not cleared, %v", last, err)
+	}
+
+	// Without a checkpoint the copy starts over, truncating first.
+	report, err = copy.Table(ctx, src, "users", dst, "people",
+		copy.WithColumns(map[string]string{"id": "", "name": "full_name"}),
+		copy.WithTruncate(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT count(*) FROM people").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if report.Copied != 5 || count != 5 {
+		t.Errorf("copied %d, people has %d rows, want 5", report.Copied, count)
+	}
+
+	// A timestamp key resumes after the saved time, not after its text.
+	events, _ := newScratchTarget(t, `CREATE TABLE events (at DATETIME PRIMARY KEY, name TEXT);
+INSERT INTO events VALUES ('2024-01-02 10:00:00', 'first'), ('2024-01-02 11:00:00', 'second')`)
+	eventsCheckpoint := copy.FileCheckpoint(filepath.Join(t.TempDir(), "events.checkpoint"))
+	if err := eventsCheckpoint.Save("2024-01-02T10:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+	report, err = copy.Table(ctx, events, "events", dst, "events", copy.WithCheckpoint(eventsCheckpoint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow("SELECT name FROM events").Scan(&name); err != nil || report.Copied != 1 || name != "second" {
+		t.Errorf("copied %d events, %q, %v", report.Copied, name, err)
+	}
+
+	// The generated column is left out of a full copy.
+	report, err = copy.Table(ctx, src, "users", dst, "users_copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Copied != 5 || len(report.Warnings) == 0 {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+type upperName string
+
+func (u *upperName) Scan(value any) error {
+	text, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("unexpected %T", value)
+	}
+	*u = upperName(strings.ToUpper(text))
+	return nil
+}
+
+type Audited struct {
+	Created time.Time `db:"created_at"`
+}
+
+type scannedUser struct {
+	*Audited
+	ID       int64
+	Name     upperName
+	Email    gosql.NullString
+	Score    *float64
+	Admin    bool   `db:"is_admin"`
+	Ignored  string `db:"-"`
+	Nickname string
+}
+
+func TestScan(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, _ := newScratchTarget(t, `CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT, score REAL, is_admin BOOLEAN, created_at TIMESTAMP, ignored TEXT);
+INSERT INTO users VALUES
+	(1, 'ada', 'ada@example.com', 9.5, 1, '2024-03-01 12:30:00', 'x'),
+	(2, 'bob', NULL, NULL, 0, '2024-03-02 08:00:00', 'y');`)
+
+	read := func(req datasource.Request) *request.Response {
+		t.Helper()
+		var response *request.Response
+		if err := scratchTarget.PerformWithHandler(ctx, func(r *request.Response) { response = r }, req); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+	all := sql.SelectAll().From("users").OrderBy("id", false).ToRequest()
+
+	users, err := result.ScanAll[scannedUser](read(all))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("scanned %d users", len(users))
+	}
+	ada, bob := users[0], users[1]
+	if ada.ID != 1 || ada.Name != "ADA" || ada.Email.String != "ada@example.com" || *ada.Score != 9.5 || !ada.Admin || ada.Ignored != "" {
+		t.Errorf("ada = %+v", ada)
+	}
+	if want := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC); !ada.Created.Equal(want) {
+		t.Errorf("ada created %v, want %v", ada.Created, want)
+	}
+	if bob.Email.Valid || bob.Score != nil || bob.Admin {
+		t.Errorf("bob = %+v", bob)
+	}
+
+	names, err := result.ScanAll[string](read(sql.Select("name").From("users").OrderBy("id", false).ToRequest()))
+	if err != nil || !slices.Equal(names, []string{"ada", "bob"}) {
+		t.Errorf("names = %v, %v", names, err)
+	}
+
+	one, err := result.ScanOne[scannedUser](read(sql.SelectAll().From("users").Where(sql.Eq("id", 2)).ToRequest()))
+	if err != nil || one.ID != 2 {
+		t.Errorf("one = %+v, %v", one, err)
+	}
+	if _, err := result.ScanOne[scannedUser](read(sql.SelectAll().From("users").Where(sql.Eq("id", 3)).ToRequest())); !errors.Is(err, gosql.ErrNoRows) {
+		t.Errorf("ScanOne of no rows error = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := result.ScanAll[int](read(all)); err == nil {
+		t.Error("expected an error scanning many columns into an int")
+	}
+
+	responses, err := scratchTarget.Request(ctx, request.Stream(all, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for user, err := range result.Iter[scannedUser](responses) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, user.ID)
+	}
+	if !slices.Equal(ids, []int64{1, 2}) {
+		t.Errorf("streamed ids %v", ids)
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, _ := newScratchTarget(t, `CREATE TABLE accounts (id INTEGER PRIMARY KEY, name TEXT, balance REAL, avatar BLOB, created TIMESTAMP, opened DATE);
+INSERT INTO accounts VALUES
+	(1, 'Ada Lovelace', 1234567.891, X'00FF', '2024-03-01 12:30:00', '2024-03-01'),
+	(2, NULL, NULL, NULL, NULL, NULL);`)
+
+	read := func(req datasource.Request) result.Body {
+		t.Helper()
+		var response *request.Response
+		if err := scratchTarget.PerformWithHandler(ctx, func(r *request.Response) { response = r }, req); err != nil {
+			t.Fatal(err)
+		}
+		return response.Body.(result.Body)
+	}
+	table, ok := read(sql.SelectAll().From("accounts").OrderBy("id", false).ToRequest()).(*result.Table)
+	if !ok {
+		t.Fatal("expected a table")
+	}
+
+	if got, want := table.GetRowStringsAll(), [][]string{
+		{"1", "Ada Lovelace", "1234567.891", "00ff", "2024-03-01 12:30:00.000000+00", "2024-03-01"},
+		{"2", "NULL", "NULL", "NULL", "NULL", "NULL"},
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("default formatting\n got: %q\nwant: %q", got, want)
+	}
+
+	table.Formatter = result.NewFormatter(
+		result.WithLocation(time.FixedZone("CET", 3600)),
+		result.WithTimeLayout(time.RFC3339),
+		result.WithDateLayout("02/01/2006"),
+		result.WithNullText("-"),
+		result.WithFloatPrecision(2),
+		result.WithThousandsSeparator(","),
+		result.WithBinaryEncoding(result.Base64),
+		result.WithTruncate(6),
+		result.WithRule(func(column *result.Column, value any) (string, bool) {
+			if column.Name == "id" {
+				return fmt.Sprintf("#%v", value), true
+			}
+			return "", false
+		}),
+	)
+	if got, want := table.GetRowStringsAll(), [][]string{
+		{"#1", "Ada L…", "1,234,567.89", "AP8=", "2024-03-01T13:30:00+01:00", "01/03/2024"},
+		{"#2", "-", "-", "-", "-", "-"},
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("custom formatting\n got: %q\nwant: %q", got, want)
+	}
+
+	// Dates stay on their day west of UTC, where midnight UTC is the day before.
+	table.Formatter = result.NewFormatter(result.WithLocation(time.FixedZone("EST", -5*3600)))
+	if got, want := table.GetRowStrings(0)[4:], []string{"2024-03-01 07:30:00.000000-05", "2024-03-01"}; !slices.Equal(got, want) {
+		t.Errorf("formatting west of UTC\n got: %q\nwant: %q", got, want)
+	}
+
+	if value, err := table.GetRowValue(0, 2); err != nil || value != 1234567.891 {
+		t.Errorf("GetRowValue = %#v, %v", value, err)
+	}
+
+	list, ok := read(sql.Select("name").From("accounts").OrderBy("id", false).ToRequest()).(result.List)
+	if !ok {
+		t.Fatal("expected a list")
+	}
+	list.Formatter = result.NewFormatter(result.WithNullText("(none)"))
+	if got := list.String(); got != "Ada Lovelace\n(none)" {
+		t.Errorf("list = %q", got)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, _ := newScratchTarget(t, "CREATE TABLE users (id INTEGER PRIMARY KEY)")
+	endless, err := sql.FromString("WITH RECURSIVE numbers(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM numbers) SELECT COUNT(*) FROM numbers")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitActive := func() target.ActiveRequest {
+		t.Helper()
+		for range 100 {
+			if active := scratchTarget.ActiveRequests(); len(active) == 1 {
+				return active[0]
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("request never became active")
+		return target.ActiveRequest{}
+	}
+	await := func(responses chan *request.Response) *request.Response {
+		t.Helper()
+		select {
+		case response := <-responses:
+			return response
+		case <-time.After(5 * time.Second):
+			t.Fatal("request was not cancelled")
+			return nil
+		}
+	}
+
+	client := dribble.NewClient()
+	if err := client.OpenTarget(ctx, scratchTarget); err != nil {
+		t.Fatal(err)
+	}
+	responses, err := client.Request(ctx, scratchTarget.Name, endless)
+	if err != nil {
+		t.Fatal(err)
+	}
+	active := waitActive()
+	if !strings.Contains(active.Statement, "WITH RECURSIVE numbers") || active.Target != scratchTarget.Name || active.Started.IsZero() {
+		t.Errorf("active request = %+v", active)
+	}
+	if listed := client.ActiveRequests(); len(listed) != 1 || listed[0].RequestID != active.RequestID {
+		t.Errorf("client active requests = %+v", listed)
+	}
+	if err := client.Cancel(scratchTarget.Name, active.RequestID); err != nil {
+		t.Fatal(err)
+	}
+	if response := await(responses); response.Error == nil || response.RequestID != active.RequestID {
+		t.Errorf("cancelled response = %+v", response)
+	}
+	if active := scratchTarget.ActiveRequests(); len(active) != 0 {
+		t.Errorf("still active: %+v", active)
+	}
+	if err := scratchTarget.Cancel(active.RequestID); !errors.Is(err, target.ErrRequestNotActive) {
+		t.Errorf("Cancel of a finished request = %v, want ErrRequestNotActive", err)
+	}
+	if err := client.Cancel("missing", 1); err == nil {
+		t.Error("expected an error cancelling on a missing target")
+	}
+
+	responses, err = scratchTarget.Request(ctx, request.Timeout(endless, 50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response := await(responses); response.Error == nil || response.Status != request.ErrorRead {
+		t.Errorf("timed out response = %+v", response)
+	}
+
+	if err := scratchTarget.Update(ctx, target.WithRequestTimeout(50*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	responses, err = scratchTarget.Request(ctx, request.Chain(endless))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response := await(responses); response.Error == nil {
+		t.Errorf("chain past the target timeout = %+v", response)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, db := newScratchTarget(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL); INSERT INTO users VALUES (1, 'ada')")
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) target.Middleware {
+		return func(next target.Handler) target.Handler {
+			return func(ctx context.Context, call *target.Call) (any, error) {
+				_, _, hasSQL := call.SQL()
+				body, err := next(ctx, call)
+				mu.Lock()
+				calls = append(calls, fmt.Sprintf("%s %d sql:%t failed:%t", name, call.RequestID, hasSQL, err != nil))
+				mu.Unlock()
+				return body, err
+			}
+		}
+	}
+	errReadOnly := errors.New("read only")
+	readOnly := func(next target.Handler) target.Handler {
+		return func(ctx context.Context, call *target.Call) (any, error) {
+			if intent, ok := call.Request.(*request.Intent); ok && intent.Type != datasource.Read {
+				return nil, errReadOnly
+			}
+			return next(ctx, call)
+		}
+	}
+	if err := scratchTarget.Update(ctx, target.WithMiddleware(record("target"), readOnly)); err != nil {
+		t.Fatal(err)
+	}
+	client := dribble.NewClient(dribble.WithMiddleware(record("client")))
+	if err := client.OpenTarget(ctx, scratchTarget); err != nil {
+		t.Fatal(err)
+	}
+
+	names := sql.Select("name").From("users").ToRequest()
+	if err := client.PerformWithHandler(ctx, func(*request.Response) {}, scratchTarget.Name, request.Chain(names, names)); err != nil {
+		t.Fatal(err)
+	}
+	err := client.PerformWithHandler(ctx, func(*request.Response) {}, scratchTarget.Name, sql.Insert("users").Columns("name").Values("bob").ToRequest())
+	if !errors.Is(err, errReadOnly) {
+		t.Errorf("insert error = %v, want the read only error", err)
+	}
+	want := []string{
+		"target 1 sql:true failed:false",
+		"client 1 sql:true failed:false",
+		"target 1 sql:true failed:false",
+		"client 1 sql:true failed:false",
+		"target 2 sql:true failed:true",
+		"client 2 sql:true failed:true",
+	}
+	if !slices.Equal(calls, want) {
+		t.Errorf("calls\n got: %q\nwant: %q", calls, want)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil || count != 1 {
+		t.Errorf("users = %d, %v", count, err)
+	}
+
+	calls = nil
+	if err := client.PerformWithHandler(ctx, func(*request.Response) {}, scratchTarget.Name, request.Batch(names, request.NewReadTableSchema("", "users"))); err != nil {
+		t.Fatal(err)
+	}
+	// The requests of a batch run concurrently, which takes which RequestID varies.
+	for i, call := range calls {
+		fields := strings.Fields(call)
+		calls[i] = strings.Join(slices.Delete(fields, 1, 2), " ")
+	}
+	slices.Sort(calls)
+	want = []string{
+		"client sql:false failed:false",
+		"client sql:true failed:false",
+		"target sql:false failed:false",
+		"target sql:true failed:false",
+	}
+	if !slices.Equal(calls, want) {
+		t.Errorf("batch calls\n got: %q\nwant: %q", calls, want)
+	}
+
+	// A second client sharing the target keeps its middleware to itself.
+	calls = nil
+	other := dribble.NewClient(dribble.WithMiddleware(record("other")))
+	if err := other.OpenTarget(ctx, scratchTarget); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*dribble.Client{client, other} {
+		if err := c.PerformWithHandler(ctx, func(*request.Response) {}, scratchTarget.Name, names); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tx, err := other.Begin(ctx, scratchTarget.Name, datasource.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.PerformWithHandler(ctx, func(*request.Response) {}, names); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := scratchTarget.PerformWithHandler(ctx, func(*request.Response) {}, names); err != nil {
+		t.Fatal(err)
+	}
+	for i, call := range calls {
+		calls[i] = strings.Fields(call)[0]
+	}
+	want = []string{"target", "client", "target", "other", "target", "other", "target"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("shared target calls\n got: %q\nwant: %q", calls, want)
+	}
+
+	// Streams go through middleware too, and cannot get around it.
+	calls = nil
+	if err := client.PerformWithHandler(ctx, func(*request.Response) {}, scratchTarget.Name, request.Stream(names, 1)); err != nil {
+		t.Fatal(err)
+	}
+	plain, err := target.New("plain", scratchTarget.DSN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close(ctx)
+	blocked := errors.New("streams are blocked")
+	noStreams := dribble.NewClient(dribble.WithMiddleware(record("noStreams"), func(next target.Handler) target.Handler {
+		return func(ctx context.Context, call *target.Call) (any, error) {
+			if call.ChunkSize > 0 {
+				return nil, blocked
+			}
+			return next(ctx, call)
+		}
+	}))
+	if err := noStreams.OpenTarget(ctx, plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := noStreams.PerformWithHandler(ctx, func(*request.Response) {}, plain.Name, request.Stream(names, 1)); !errors.Is(err, blocked) {
+		t.Errorf("stream error = %v, want the middleware error", err)
+	}
+	want = []string{
+		"target 9 sql:true failed:false",
+		"client 9 sql:true failed:false",
+		"noStreams 1 sql:true failed:true",
+	}
+	if !slices.Equal(calls, want) {
+		t.Errorf("stream calls\n got: %q\nwant: %q", calls, want)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	scratchTarget, db := newScratchTarget(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL); INSERT INTO users VALUES (1, 'ada')")
+
+	var mu sync.Mutex
+	var calls, failures int
+	busy := func(next target.Handler) target.Handler {
+		return func(ctx context.Context, call *target.Call) (any, error) {
+			mu.Lock()
+			calls++
+			fail := calls <= failures
+			mu.Unlock()
+			if fail {
+				return nil, gosqlite3.Error{Code: gosqlite3.ErrBusy}
+			}
+			return next(ctx, call)
+		}
+	}
+	policy := target.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	if err := scratchTarget.Update(ctx, target.WithMiddleware(busy), target.WithRetry(policy)); err != nil {
+		t.Fatal(err)
+	}
+	perform := func(failing int, req datasource.Request) (int, error) {
+		mu.Lock()
+		calls, failures = 0, failing
+		mu.Unlock()
+		err := scratchTarget.PerformWithHandler(ctx, func(*request.Response) {}, req)
+		return calls, err
+	}
+
+	names := sql.Select("name").From("users").ToRequest()
+	namedNames, err := sql.FromString("WITH named AS (SELECT name FROM users) SELECT name FROM named")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		failing   int
+		req       datasource.Request
+		wantCalls int
+		want      datasource.ErrorCategory
+		wantErr   bool
+	}{
+		{"read succeeds on retry", 2, names, 3, datasource.ErrorUnknown, false},
+		{"read runs out of attempts", 3, names, 3, datasource.ErrorTransient, true},
+		{"prefab succeeds on retry", 1, request.NewReadTableSchema("", "users"), 2, datasource.ErrorUnknown, false},
+		{"read from a string is not retried", 1, namedNames, 1, datasource.ErrorTransient, true},
+		{"write is not retried", 1, sql.Insert("users").Columns("name").Values("bob").ToRequest(), 1, datasource.ErrorTransient, true},
+		{"syntax is not retried", 0, sql.Select("name").From("missing").ToRequest(), 1, datasource.ErrorSyntax, true},
+		{"constraint is not retried", 0, sql.Insert("users").Columns("id", "name").Values(1, "ada").ToRequest(), 1, datasource.ErrorConstraint, true},
+		{"transaction is retried whole", 1, request.Transaction(names), 2, datasource.ErrorUnknown, false},
+		{"transaction succeeds on retry", 1, request.Transaction(names, sql.Insert("users").Columns("name").Values("cy").ToRequest()), 3, datasource.ErrorUnknown, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotCalls, err := perform(tc.failing, tc.req)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tc.wantErr)
+			}
+			if gotCalls != tc.wantCalls {
+				t.Errorf("calls = %d, want %d", gotCalls, tc.wantCalls)
+			}
+			if got := datasource.Classify(err); got != tc.want {
+				t.Errorf("Classify(%v) = %v, want %v", err, got, tc.want)
+			}
+		})
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users WHERE name = 'cy'").Scan(&count); err != nil || count != 1 {
+		t.Errorf("users named cy = %d, %v, want the retried transaction applied once", count, err)
+	}
+}
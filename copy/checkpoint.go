@@ -0,0 +1,182 @@
+package copy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/ctrl-alt-boop/dribble/result"
+	"github.com/ctrl-alt-boop/dribble/sql"
+	"github.com/ctrl-alt-boop/dribble/target"
+)
+
+// Checkpoint stores the key of the last row copied, for an interrupted copy to resume after it.
+type Checkpoint interface {
+	// Load returns the stored key, ok is false when there is none.
+	Load() (key string, ok bool, err error)
+	Save(key string) error
+	// Clear removes the stored key once a copy completes.
+	Clear() error
+}
+
+var _ Checkpoint = FileCheckpoint("")
+
+// FileCheckpoint stores the key in the file at its path.
+type FileCheckpoint string
+
+// Load implements Checkpoint.
+func (f FileCheckpoint) Load() (string, bool, error) {
+	content, err := os.ReadFile(string(f))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSuffix(string(content), "\n"), true, nil
+}
+
+// Save implements Checkpoint, replacing the file whole so a crash never leaves it half written.
+func (f FileCheckpoint) Save(key string) error {
+	temp, err := os.CreateTemp(filepath.Dir(string(f)), filepath.Base(string(f))+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.WriteString(key + "\n"); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), string(f))
+}
+
+// Clear implements Checkpoint.
+func (f FileCheckpoint) Clear() error {
+	if err := os.Remove(string(f)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+type (
+	// checkpointStore saves the key of the last row of each batch as the batch is inserted.
+	checkpointStore interface {
+		load(ctx context.Context) (key string, ok bool, err error)
+		// insert inserts the rows of req and saves key, that of the last of them.
+		insert(ctx context.Context, req request.InsertRowsRequest, key string) (int64, error)
+		clear(ctx context.Context) error
+	}
+
+	// externalCheckpoint saves the key to a Checkpoint once its batch is inserted.
+	externalCheckpoint struct {
+		checkpoint Checkpoint
+		dst        *target.Target
+	}
+
+	// tableCheckpoint saves the key to a row of a table of the destination, named after the
+	// destination table, in the transaction inserting its batch.
+	tableCheckpoint struct {
+		dst   *target.Target
+		table string
+		name  string
+	}
+
+	performer interface {
+		PerformWithHandler(ctx context.Context, handler func(*request.Response), req datasource.Request) error
+	}
+)
+
+func (s *externalCheckpoint) load(context.Context) (string, bool, error) {
+	return s.checkpoint.Load()
+}
+
+func (s *externalCheckpoint) insert(ctx context.Context, req request.InsertRowsRequest, key string) (int64, error) {
+	inserted, err := insertRows(ctx, s.dst, req)
+	if err != nil {
+		return inserted, err
+	}
+	if err := s.checkpoint.Save(key); err != nil {
+		return inserted, fmt.Errorf("error saving checkpoint: %w", err)
+	}
+	return inserted, nil
+}
+
+func (s *externalCheckpoint) clear(context.Context) error {
+	return s.checkpoint.Clear()
+}
+
+// create creates the checkpoint table when it is missing.
+func (s *tableCheckpoint) create(ctx context.Context, adapter datasource.SQLAdapter) error {
+	statement := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name VARCHAR(255) NOT NULL PRIMARY KEY, last_key TEXT NOT NULL)",
+		sql.NewRenderer(adapter).Ident(s.table))
+	if err := s.dst.PerformWithHandler(ctx, func(*request.Response) {}, request.Exec(statement)); err != nil {
+		return fmt.Errorf("error creating checkpoint table %s: %w", s.table, err)
+	}
+	return nil
+}
+
+func (s *tableCheckpoint) load(ctx context.Context) (string, bool, error) {
+	// Two columns, a read of a single column is answered with a result.List.
+	var table *result.Table
+	err := s.dst.PerformWithHandler(ctx, func(response *request.Response) {
+		table, _ = response.Body.(*result.Table)
+	}, sql.Select("name", "last_key").From(s.table).Where(sql.Eq("name", s.name)).ToRequest())
+	if err != nil {
+		return "", false, err
+	}
+	if table == nil || table.NumRows() == 0 {
+		return "", false, nil
+	}
+	// The MySQL driver returns text as bytes
+	switch key := table.Rows()[0].Values[1].(type) {
+	case []byte:
+		return string(key), true, nil
+	default:
+		return fmt.Sprint(key), true, nil
+	}
+}
+
+func (s *tableCheckpoint) insert(ctx context.Context, req request.InsertRowsRequest, key string) (int64, error) {
+	tx, err := s.dst.Begin(ctx, datasource.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	inserted, err := insertRows(ctx, tx, req)
+	if err == nil {
+		err = s.save(ctx, tx, key)
+	}
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+func (s *tableCheckpoint) save(ctx context.Context, tx *target.Tx, key string) error {
+	for _, req := range []datasource.Request{
+		sql.DeleteFrom(s.table).Where(sql.Eq("name", s.name)).ToRequest(),
+		sql.Insert(s.table).Columns("name", "last_key").Values(s.name, key).ToRequest(),
+	} {
+		if err := tx.PerformWithHandler(ctx, func(*request.Response) {}, req); err != nil {
+			return fmt.Errorf("error saving checkpoint: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *tableCheckpoint) clear(ctx context.Context) error {
+	return s.dst.PerformWithHandler(ctx, func(*request.Response) {}, sql.DeleteFrom(s.table).Where(sql.Eq("name", s.name)).ToRequest())
+}
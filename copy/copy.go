@@ -0,0 +1,390 @@
+// Package copy copies the rows of a table from one target into another, which may be of
+// another dialect.
+package copy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/ctrl-alt-boop/dribble/result"
+	"github.com/ctrl-alt-boop/dribble/schema"
+	"github.com/ctrl-alt-boop/dribble/sql"
+	"github.com/ctrl-alt-boop/dribble/target"
+)
+
+const DefaultBatchSize = 1000
+
+var (
+	ErrNoKey              = errors.New("checkpoints take a key of a single copied column")
+	ErrUnsupportedDialect = errors.New("copies are unsupported for this data source")
+)
+
+type (
+	// Report is what a copy did.
+	Report struct {
+		Copied   int64
+		Created  bool   // Whether the destination table was created
+		Resumed  string // The key the copy resumed after, empty when it started over
+		Warnings []string
+	}
+
+	Option func(*options)
+
+	options struct {
+		columns         map[string]string
+		where           []sql.Expr
+		truncate        bool
+		key             string
+		checkpoint      Checkpoint
+		checkpointTable string
+		batchSize       int
+	}
+
+	// column is a copied column, named in the source and in the destination.
+	column struct {
+		source, destination string
+	}
+)
+
+// WithColumns copies only the source columns of mapping, each into the destination column
+// it maps to, or one of the same name when it maps to an empty name.
+func WithColumns(mapping map[string]string) Option {
+	return func(o *options) {
+		o.columns = mapping
+	}
+}
+
+// WithWhere copies only the rows matching the expressions.
+func WithWhere(exprs ...sql.Expr) Option {
+	return func(o *options) {
+		o.where = exprs
+	}
+}
+
+// WithTruncate empties the destination table first, unless the copy resumes from a checkpoint.
+func WithTruncate() Option {
+	return func(o *options) {
+		o.truncate = true
+	}
+}
+
+// WithKey sets the source column the rows are copied in the order of, by default the primary
+// key of the source when it is a single column.
+func WithKey(column string) Option {
+	return func(o *options) {
+		o.key = column
+	}
+}
+
+// WithCheckpoint saves the key of the last row of every batch to checkpoint, and resumes
+// after the saved key. The checkpoint is cleared when the copy completes.
+//
+// The key is saved once its batch is inserted, a copy interrupted in between inserts the batch
+// again when it resumes. The destination must tolerate that, having no unique key the batch
+// would violate, or the checkpoint be kept in it with WithCheckpointTable instead.
+func WithCheckpoint(checkpoint Checkpoint) Option {
+	return func(o *options) {
+		o.checkpoint = checkpoint
+		o.checkpointTable = ""
+	}
+}
+
+// WithCheckpointTable keeps the checkpoint in table of the destination, which is created when
+// missing, in a row named after the destination table. The key is saved in the transaction
+// inserting its batch, so a resumed copy never inserts a row twice.
+func WithCheckpointTable(table string) Option {
+	return func(o *options) {
+		o.checkpointTable = table
+		o.checkpoint = nil
+	}
+}
+
+// WithBatchSize sets the number of rows read and inserted at a time, DefaultBatchSize by default.
+func WithBatchSize(rows int) Option {
+	return func(o *options) {
+		o.batchSize = max(rows, 1)
+	}
+}
+
+// Table streams the rows of srcTable into dstTable, creating dstTable from the schema of
+// srcTable when it is missing. Either name may be qualified by a database or schema.
+// Generated columns are not copied, and a created table has the columns and primary key
+// of the source but none of its indexes, foreign keys or constraints.
+func Table(ctx context.Context, src *target.Target, srcTable string, dst *target.Target, dstTable string, opts ...Option) (*Report, error) {
+	o := &options{batchSize: DefaultBatchSize}
+	for _, opt := range opts {
+		opt(o)
+	}
+	dstDialect, ok := dst.DBType.(datasource.SQLDialectType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedDialect, dst.DBType)
+	}
+	dstAdapter, _ := dst.SQLAdapter()
+
+	report := &Report{}
+	source, err := readTable(ctx, src, srcTable)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := o.copiedColumns(source, report)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := readTable(ctx, dst, dstTable); errors.Is(err, schema.ErrTableNotFound) {
+		if err := createTable(ctx, dst, dstDialect, source, dstTable, columns, report); err != nil {
+			return report, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	key := o.key
+	if key == "" && len(source.Properties.PrimaryKeys) == 1 {
+		key = source.Properties.PrimaryKeys[0]
+	}
+	keyIndex := slices.IndexFunc(columns, func(c column) bool { return c.source == key })
+	where := o.where
+	if (o.checkpoint != nil || o.checkpointTable != "") && keyIndex < 0 {
+		return nil, ErrNoKey
+	}
+	store, err := o.checkpointStore(ctx, dst, dstAdapter, dstTable)
+	if err != nil {
+		return nil, err
+	}
+	if store != nil {
+		last, resume, err := store.load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error loading checkpoint: %w", err)
+		}
+		if resume {
+			report.Resumed = last
+			where = append(slices.Clone(where), sql.Gt(key, keyValue(source.Field(key), last)))
+		}
+	}
+
+	if o.truncate && report.Resumed == "" {
+		if err := truncate(ctx, dst, dstDialect, dstAdapter, dstTable); err != nil {
+			return report, err
+		}
+	}
+
+	sourceNames := make([]string, len(columns))
+	destinationNames := make([]string, len(columns))
+	for i, c := range columns {
+		sourceNames[i], destinationNames[i] = c.source, c.destination
+	}
+	query := sql.Select(sourceNames...).From(srcTable).Where(where...)
+	if key != "" {
+		query = query.OrderBy(key, false)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	responses, err := src.Request(ctx, request.Stream(query.ToRequest(), o.batchSize))
+	if err != nil {
+		return report, err
+	}
+	for response := range responses {
+		if response.Error != nil {
+			return report, fmt.Errorf("error reading %s: %w", srcTable, response.Error)
+		}
+		chunk, ok := response.Body.(*result.Chunk)
+		if !ok {
+			return report, fmt.Errorf("unexpected response %T", response.Body)
+		}
+		if len(chunk.Rows) == 0 {
+			continue
+		}
+
+		rows := make([][]any, len(chunk.Rows))
+		for i, row := range chunk.Rows {
+			rows[i] = make([]any, len(row.Values))
+			for j, value := range row.Values {
+				rows[i][j] = normalize(chunk.Columns[j], value)
+			}
+		}
+		insert := request.InsertRows(dstTable, destinationNames, rows)
+		var copied int64
+		if store != nil {
+			last := chunk.Rows[len(chunk.Rows)-1].Values[keyIndex]
+			copied, err = store.insert(ctx, insert, keyString(chunk.Columns[keyIndex], last))
+		} else {
+			copied, err = insertRows(ctx, dst, insert)
+		}
+		report.Copied += copied
+		if err != nil {
+			return report, fmt.Errorf("error writing %s: %w", dstTable, err)
+		}
+	}
+
+	if store != nil {
+		if err := store.clear(ctx); err != nil {
+			return report, fmt.Errorf("error clearing checkpoint: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// checkpointStore returns the store of the checkpoint of the copy into dstTable, nil without one.
+func (o *options) checkpointStore(ctx context.Context, dst *target.Target, adapter datasource.SQLAdapter, dstTable string) (checkpointStore, error) {
+	switch {
+	case o.checkpointTable != "":
+		store := &tableCheckpoint{dst: dst, table: o.checkpointTable, name: dstTable}
+		if err := store.create(ctx, adapter); err != nil {
+			return nil, err
+		}
+		return store, nil
+	case o.checkpoint != nil:
+		return &externalCheckpoint{checkpoint: o.checkpoint, dst: dst}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (o *options) copiedColumns(source *schema.Table, report *Report) ([]column, error) {
+	var columns []column
+	for _, field := range source.Fields {
+		destination, mapped := o.columns[field.Name]
+		if o.columns != nil && !mapped {
+			continue
+		}
+		if field.Properties.Generated != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("generated column %s is not copied", field.Name))
+			continue
+		}
+		if destination == "" {
+			destination = field.Name
+		}
+		columns = append(columns, column{source: field.Name, destination: destination})
+	}
+	for name := range o.columns {
+		if source.Field(name) == nil {
+			return nil, fmt.Errorf("column %s not found in table %s", name, source.Name)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("no columns to copy")
+	}
+	return columns, nil
+}
+
+func readTable(ctx context.Context, t *target.Target, name string) (*schema.Table, error) {
+	databaseName, tableName := "", name
+	if index := strings.LastIndex(name, "."); index >= 0 {
+		databaseName, tableName = name[:index], name[index+1:]
+	}
+
+	var table *schema.Table
+	err := t.PerformWithHandler(ctx, func(response *request.Response) {
+		table, _ = response.Body.(*schema.Table)
+	}, request.NewReadTableSchema(databaseName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return nil, fmt.Errorf("error reading schema of %s", name)
+	}
+	return table, nil
+}
+
+func createTable(ctx context.Context, dst *target.Target, dialect datasource.SQLDialectType, source *schema.Table, name string, columns []column, report *Report) error {
+	table := &schema.Table{Name: name}
+	renamed := map[string]string{}
+	for _, c := range columns {
+		field := *source.Field(c.source)
+		field.Name = c.destination
+		table.Fields = append(table.Fields, &field)
+		renamed[c.source] = c.destination
+	}
+	for _, key := range source.Properties.PrimaryKeys {
+		destination, copied := renamed[key]
+		if !copied {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("primary key column %s is not copied, %s has no primary key", key, name))
+			table.Properties.PrimaryKeys = nil
+			break
+		}
+		table.Properties.PrimaryKeys = append(table.Properties.PrimaryKeys, destination)
+	}
+
+	ddl, err := sql.GenerateDDL(dialect, table)
+	if err != nil {
+		return err
+	}
+	report.Warnings = append(report.Warnings, ddl.Warnings...)
+	for _, statement := range ddl.Statements {
+		if err := dst.PerformWithHandler(ctx, func(*request.Response) {}, request.Exec(statement)); err != nil {
+			return fmt.Errorf("error creating table %s: %w", name, err)
+		}
+	}
+	report.Created = true
+	return nil
+}
+
+func truncate(ctx context.Context, dst *target.Target, dialect datasource.SQLDialectType, adapter datasource.SQLAdapter, name string) error {
+	table := sql.NewRenderer(adapter).Ident(name)
+	statement := "TRUNCATE TABLE " + table
+	if dialect == datasource.SQLite3 {
+		statement = "DELETE FROM " + table
+	}
+	if err := dst.PerformWithHandler(ctx, func(*request.Response) {}, request.Exec(statement)); err != nil {
+		return fmt.Errorf("error truncating %s: %w", name, err)
+	}
+	return nil
+}
+
+func insertRows(ctx context.Context, dst performer, req request.InsertRowsRequest) (int64, error) {
+	var inserted int64
+	err := dst.PerformWithHandler(ctx, func(response *request.Response) {
+		if write, ok := response.Body.(*result.Write); ok {
+			inserted = write.RowsAffected
+		}
+	}, req)
+	return inserted, err
+}
+
+// normalize turns the bytes of text columns into strings, for a destination that stores
+// bytes as binary data.
+func normalize(column *result.Column, value any) any {
+	if b, ok := value.([]byte); ok && !column.IsBinary(b) {
+		return string(b)
+	}
+	return value
+}
+
+// keyValue converts key, as keyString saved it, back to the type of the key column, for it to be
+// compared as the column is rather than as text. A key it cannot convert is left as text.
+func keyValue(field *schema.Field, key string) any {
+	typeName := strings.ToLower(field.Properties.Type)
+	switch {
+	case strings.Contains(typeName, "int"):
+		if value, err := strconv.ParseInt(key, 10, 64); err == nil {
+			return value
+		}
+	case strings.Contains(typeName, "date"), strings.Contains(typeName, "time"):
+		if value, err := time.Parse(time.RFC3339Nano, key); err == nil {
+			return value
+		}
+	case strings.Contains(typeName, "real"), strings.Contains(typeName, "float"), strings.Contains(typeName, "double"):
+		if value, err := strconv.ParseFloat(key, 64); err == nil {
+			return value
+		}
+	}
+	return key
+}
+
+func keyString(column *result.Column, value any) string {
+	switch value := normalize(column, value).(type) {
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(value)
+	}
+}
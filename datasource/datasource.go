@@ -98,6 +98,13 @@ type (
 		Path() []string
 	}
 
+	// Streamer is implemented by data sources that can deliver a read in
+	// chunks instead of a single materialized result. emit is called once per
+	// chunk, and the stream stops with emit's error if it returns one.
+	Streamer interface {
+		Stream(ctx context.Context, req Request, chunkSize int, emit func(chunk any) error) error
+	}
+
 	Executor interface {
 		Name() string
 		ExecutorType() ExecutorType
@@ -0,0 +1,101 @@
+package datasource
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+//go:generate stringer -type=ErrorCategory -trimprefix=Error
+
+// ErrorCategory is the kind of failure behind an error of a data source, see Classify.
+type ErrorCategory int
+
+const (
+	ErrorUnknown ErrorCategory = iota
+	// ErrorTransient is a data source that is busy or out of a resource, the same request may succeed later.
+	ErrorTransient
+	// ErrorConflict is a serialization failure or deadlock with a concurrent transaction,
+	// running the whole transaction again may succeed.
+	ErrorConflict
+	// ErrorConstraint is a unique, foreign key, not null or check constraint that was violated.
+	ErrorConstraint
+	// ErrorSyntax is a malformed statement or one naming a table or column that does not exist.
+	ErrorSyntax
+	// ErrorPermission is a request the user is not allowed to make.
+	ErrorPermission
+	// ErrorConnection is a connection that could not be made or was lost.
+	ErrorConnection
+)
+
+// ErrorClassifier is implemented by data sources that know the errors of their driver.
+type ErrorClassifier interface {
+	// ClassifyError returns the category of err, ErrorUnknown when it is not an error of the driver it knows.
+	ClassifyError(err error) ErrorCategory
+}
+
+// Error is an error of a data source together with its category, as targets respond with.
+type Error struct {
+	Category ErrorCategory
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Classify returns the category of err. It is that of an *Error in the chain of err, otherwise
+// broken connections are recognized without knowing the driver and the rest is ErrorUnknown.
+func Classify(err error) ErrorCategory {
+	var classified *Error
+	if errors.As(err, &classified) {
+		return classified.Category
+	}
+	switch {
+	case err == nil,
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return ErrorUnknown
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE):
+		return ErrorConnection
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrorConnection
+	}
+	return ErrorUnknown
+}
+
+// ClassifyWith wraps err in an *Error with the category classifier gives it, falling back on
+// Classify. Errors that are already classified, or that remain ErrorUnknown, are returned as they are.
+func ClassifyWith(classifier ErrorClassifier, err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+	category := ErrorUnknown
+	if classifier != nil {
+		category = classifier.ClassifyError(err)
+	}
+	if category == ErrorUnknown {
+		category = Classify(err)
+	}
+	if category == ErrorUnknown {
+		return err
+	}
+	return &Error{Category: category, Err: err}
+}
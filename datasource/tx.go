@@ -0,0 +1,35 @@
+package datasource
+
+import "context"
+
+// IsolationLevel is the isolation level of a transaction, IsolationDefault
+// leaves the choice to the data source.
+type IsolationLevel int
+
+const (
+	IsolationDefault IsolationLevel = iota
+	IsolationReadUncommitted
+	IsolationReadCommitted
+	IsolationRepeatableRead
+	IsolationSerializable
+)
+
+type (
+	TxOptions struct {
+		Isolation IsolationLevel
+		ReadOnly  bool
+	}
+
+	// Transactor is implemented by data sources that can run requests in a transaction.
+	Transactor interface {
+		Begin(context.Context, TxOptions) (Tx, error)
+	}
+
+	// Tx is an open transaction, requests sent through it are committed or rolled back together.
+	Tx interface {
+		Request(ctx context.Context, req Request) (any, error)
+
+		Commit() error
+		Rollback() error
+	}
+)
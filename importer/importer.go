@@ -0,0 +1,364 @@
+// Package importer loads CSV and NDJSON into a table of a target, inferring the types of its
+// columns from a sample of the rows. Only decimal literals are numbers, values like 007, NaN or
+// 0x10 are text. NDJSON values are typed by their JSON type alone, a string being text whatever
+// it holds. Timestamps are a timestamptz when any of the sample has an offset.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/ctrl-alt-boop/dribble/result"
+	"github.com/ctrl-alt-boop/dribble/schema"
+	dribblesql "github.com/ctrl-alt-boop/dribble/sql"
+	"github.com/ctrl-alt-boop/dribble/target"
+)
+
+const (
+	DefaultSampleSize = 1000
+	DefaultBatchSize  = 500
+)
+
+var (
+	ErrErrorBudget        = errors.New("too many rows failed to import")
+	ErrUnsupportedDialect = errors.New("imports are unsupported for this data source")
+)
+
+type (
+	Format int
+
+	// RowError is a row that failed to import. Line is where the row starts in the input.
+	RowError struct {
+		Line int
+		Err  error
+	}
+
+	// Progress is reported after every batch.
+	Progress struct {
+		Read     int
+		Inserted int64
+		Failed   int
+	}
+
+	// Report is what an import did. Fields are the inferred columns of the table.
+	Report struct {
+		Fields   schema.Fields
+		Read     int
+		Inserted int64
+		Failed   []RowError
+		Warnings []string // Of the generated table, see sql.DDL
+	}
+
+	// Importer loads rows into one table of a target.
+	Importer struct {
+		target  *target.Target
+		dialect datasource.SQLDialectType
+		table   string
+
+		sampleSize  int
+		batchSize   int
+		errorBudget int
+		createTable bool
+		delimiter   rune
+		progress    func(Progress)
+	}
+
+	Option func(*Importer)
+
+	// row is a record with its values in the order of the columns.
+	row struct {
+		line   int
+		values []any
+	}
+)
+
+const (
+	CSV Format = iota
+	NDJSON
+)
+
+// WithSampleSize sets the number of rows the column types are inferred from, DefaultSampleSize by default.
+func WithSampleSize(rows int) Option {
+	return func(im *Importer) {
+		im.sampleSize = max(rows, 1)
+	}
+}
+
+// WithBatchSize sets the number of rows inserted per request, DefaultBatchSize by default.
+func WithBatchSize(rows int) Option {
+	return func(im *Importer) {
+		im.batchSize = max(rows, 1)
+	}
+}
+
+// WithErrorBudget sets how many rows may fail before the import stops with ErrErrorBudget.
+// By default the first failed row stops it, rows inserted before are kept.
+func WithErrorBudget(rows int) Option {
+	return func(im *Importer) {
+		im.errorBudget = rows
+	}
+}
+
+// WithCreateTable creates the table from the inferred columns before loading it.
+func WithCreateTable() Option {
+	return func(im *Importer) {
+		im.createTable = true
+	}
+}
+
+// WithDelimiter sets the CSV field delimiter, a comma by default.
+func WithDelimiter(delimiter rune) Option {
+	return func(im *Importer) {
+		im.delimiter = delimiter
+	}
+}
+
+// WithProgress sets a function called after every batch.
+func WithProgress(progress func(Progress)) Option {
+	return func(im *Importer) {
+		im.progress = progress
+	}
+}
+
+// New returns an importer into table, which may be qualified by a schema.
+func New(t *target.Target, table string, opts ...Option) (*Importer, error) {
+	dialect, ok := t.DBType.(datasource.SQLDialectType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedDialect, t.DBType)
+	}
+	im := &Importer{
+		target:     t,
+		dialect:    dialect,
+		table:      table,
+		sampleSize: DefaultSampleSize,
+		batchSize:  DefaultBatchSize,
+		delimiter:  ',',
+	}
+	for _, opt := range opts {
+		opt(im)
+	}
+	return im, nil
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// Import reads r in format and loads its rows. CSV takes its columns from a header line
+// and reads empty fields as NULL, NDJSON from the keys of the objects in the sample,
+// a key first seen after it failing its row. The report is returned even on error.
+func (im *Importer) Import(ctx context.Context, r io.Reader, format Format) (*Report, error) {
+	var src source
+	switch format {
+	case CSV:
+		csvSource, err := newCSVSource(r, im.delimiter)
+		if err != nil {
+			return nil, err
+		}
+		src = csvSource
+	case NDJSON:
+		src = newNDJSONSource(r)
+	default:
+		return nil, fmt.Errorf("unknown format %d", format)
+	}
+
+	run := &importRun{Importer: im, ctx: ctx, report: &Report{}}
+	err := run.load(src)
+	return run.report, err
+}
+
+// importRun is the state of one Import.
+type importRun struct {
+	*Importer
+	ctx    context.Context
+	report *Report
+
+	columns []string
+	types   []columnType
+}
+
+func (run *importRun) load(src source) error {
+	run.columns = slices.Clone(src.header())
+	var records []*record
+	for len(records) < run.sampleSize {
+		rec, err := src.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		records = append(records, rec)
+		if src.header() == nil && rec.err == nil {
+			for _, key := range rec.keys {
+				if !slices.Contains(run.columns, key) {
+					run.columns = append(run.columns, key)
+				}
+			}
+		}
+	}
+	if len(run.columns) == 0 {
+		return errors.New("no columns to import")
+	}
+
+	var sample []*row
+	for _, rec := range records {
+		if row, err := run.align(rec); err == nil {
+			sample = append(sample, row)
+		}
+	}
+	run.types = inferTypes(len(run.columns), sample)
+	run.report.Fields = fields(run.columns, run.types)
+	if run.createTable {
+		if err := run.create(); err != nil {
+			return err
+		}
+	}
+
+	batch := make([]*row, 0, run.batchSize)
+	add := func(rec *record) error {
+		run.report.Read++
+		row, err := run.align(rec)
+		if err == nil {
+			err = run.convert(row)
+		}
+		if err != nil {
+			return run.fail(rec.line, err)
+		}
+		batch = append(batch, row)
+		if len(batch) < run.batchSize {
+			return nil
+		}
+		err = run.insert(batch)
+		batch = batch[:0]
+		return err
+	}
+
+	for _, rec := range records {
+		if err := add(rec); err != nil {
+			return err
+		}
+	}
+	for {
+		rec, err := src.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := add(rec); err != nil {
+			return err
+		}
+	}
+	return run.insert(batch)
+}
+
+// align orders the values of rec by column.
+func (run *importRun) align(rec *record) (*row, error) {
+	if rec.err != nil {
+		return nil, rec.err
+	}
+	if rec.keys == nil {
+		if len(rec.values) != len(run.columns) {
+			return nil, fmt.Errorf("row has %d fields for %d columns", len(rec.values), len(run.columns))
+		}
+		return &row{line: rec.line, values: rec.values}, nil
+	}
+
+	values := make([]any, len(run.columns))
+	for i, key := range rec.keys {
+		index := slices.Index(run.columns, key)
+		if index < 0 {
+			return nil, fmt.Errorf("unknown column %q", key)
+		}
+		values[index] = rec.values[i]
+	}
+	return &row{line: rec.line, values: values}, nil
+}
+
+func (run *importRun) convert(row *row) error {
+	for i, value := range row.values {
+		converted, err := convert(value, run.types[i])
+		if err != nil {
+			return fmt.Errorf("column %s: %w", run.columns[i], err)
+		}
+		row.values[i] = converted
+	}
+	return nil
+}
+
+func (run *importRun) create() error {
+	ddl, err := dribblesql.GenerateDDL(run.dialect, &schema.Table{Name: run.table, Fields: run.report.Fields})
+	if err != nil {
+		return err
+	}
+	run.report.Warnings = ddl.Warnings
+	for _, statement := range ddl.Statements {
+		if err := run.target.PerformWithHandler(run.ctx, func(*request.Response) {}, request.Exec(statement)); err != nil {
+			return fmt.Errorf("error creating table %s: %w", run.table, err)
+		}
+	}
+	return nil
+}
+
+// insert loads batch, and when that fails loads its rows one by one to find those failing.
+func (run *importRun) insert(batch []*row) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	values := make([][]any, len(batch))
+	for i, row := range batch {
+		values[i] = row.values
+	}
+
+	inserted, err := run.insertRows(values)
+	if err != nil {
+		if run.ctx.Err() != nil {
+			return run.ctx.Err()
+		}
+		for _, row := range batch {
+			rowInserted, err := run.insertRows([][]any{row.values})
+			if err != nil {
+				if err := run.fail(row.line, err); err != nil {
+					return err
+				}
+				continue
+			}
+			inserted += rowInserted
+		}
+	}
+	run.report.Inserted += inserted
+
+	if run.progress != nil {
+		run.progress(Progress{Read: run.report.Read, Inserted: run.report.Inserted, Failed: len(run.report.Failed)})
+	}
+	return nil
+}
+
+func (run *importRun) insertRows(values [][]any) (int64, error) {
+	var inserted int64
+	err := run.target.PerformWithHandler(run.ctx, func(response *request.Response) {
+		if write, ok := response.Body.(*result.Write); ok {
+			inserted = write.RowsAffected
+		}
+	}, request.InsertRows(run.table, run.columns, values))
+	return inserted, err
+}
+
+func (run *importRun) fail(line int, err error) error {
+	run.report.Failed = append(run.report.Failed, RowError{Line: line, Err: err})
+	if len(run.report.Failed) > run.errorBudget {
+		return fmt.Errorf("%w: %d failed, the budget is %d", ErrErrorBudget, len(run.report.Failed), run.errorBudget)
+	}
+	return nil
+}
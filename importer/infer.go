@@ -0,0 +1,233 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/schema"
+)
+
+type (
+	// jsonText is a nested object or array of an NDJSON record.
+	jsonText string
+	// jsonString is a string of an NDJSON record, which is text whatever it holds.
+	jsonString string
+)
+
+// columnType is an inferred type, each type but text widening the ones before it that
+// it can hold.
+type columnType int
+
+const (
+	typeUnknown columnType = iota // Only NULLs seen
+	typeBoolean
+	typeInteger
+	typeFloat
+	typeDate
+	typeTimestamp
+	typeTimestampTZ // A timestamp with an offset, as 2024-01-02T10:00:00+02:00 or Z
+	typeJSON
+	typeText
+)
+
+// Type names that GenerateDDL maps to every dialect.
+var typeNames = map[columnType]string{
+	typeUnknown:     "text",
+	typeBoolean:     "boolean",
+	typeInteger:     "bigint",
+	typeFloat:       "double precision",
+	typeDate:        "date",
+	typeTimestamp:   "timestamp",
+	typeTimestampTZ: "timestamptz",
+	typeJSON:        "json",
+	typeText:        "text",
+}
+
+const dateLayout = "2006-01-02"
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// zoned reports whether a timestamp of layout holds an offset.
+func zoned(layout string) bool {
+	return strings.Contains(layout, "Z07")
+}
+
+// Decimal literals, without the leading zeros that a number would lose, as in 007. Unlike
+// strconv, they leave out hexadecimal, underscores, NaN and Inf, which are left as text.
+var (
+	integerPattern = regexp.MustCompile(`^[+-]?(0|[1-9][0-9]*)$`)
+	floatPattern   = regexp.MustCompile(`^[+-]?((0|[1-9][0-9]*)(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+)
+
+var errNotDecimal = errors.New("not a decimal number")
+
+// typeOf returns the narrowest type holding value.
+func typeOf(value any) columnType {
+	switch value := value.(type) {
+	case nil:
+		return typeUnknown
+	case bool:
+		return typeBoolean
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return typeInteger
+		}
+		return typeFloat
+	case jsonText:
+		return typeJSON
+	case jsonString:
+		return typeText
+	case string:
+		return typeOfText(value)
+	default:
+		return typeText
+	}
+}
+
+func typeOfText(value string) columnType {
+	if integerPattern.MatchString(value) {
+		if _, err := parseInteger(value); err == nil {
+			return typeInteger
+		}
+		// Too large for a bigint, and a double would round it
+		return typeText
+	}
+	if _, err := parseFloat(value); err == nil {
+		return typeFloat
+	}
+	if strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+		return typeBoolean
+	}
+	if _, err := time.Parse(dateLayout, value); err == nil {
+		return typeDate
+	}
+	if _, layout, err := parseTimestamp(value); err == nil {
+		if zoned(layout) {
+			return typeTimestampTZ
+		}
+		return typeTimestamp
+	}
+	return typeText
+}
+
+// widen returns the narrowest type holding both a and b.
+func widen(a, b columnType) columnType {
+	if a == typeUnknown || a == b {
+		return b
+	}
+	if b == typeUnknown {
+		return a
+	}
+	switch {
+	case a == typeInteger && b == typeFloat, a == typeFloat && b == typeInteger:
+		return typeFloat
+	case a == typeDate && b == typeTimestamp, a == typeTimestamp && b == typeDate:
+		return typeTimestamp
+	case isTimestamp(a) && isTimestamp(b):
+		// Any offset seen is kept
+		return typeTimestampTZ
+	default:
+		return typeText
+	}
+}
+
+func isTimestamp(t columnType) bool {
+	return t == typeDate || t == typeTimestamp || t == typeTimestampTZ
+}
+
+// inferTypes returns the types of the columns holding the values of sample.
+func inferTypes(numColumns int, sample []*row) []columnType {
+	types := make([]columnType, numColumns)
+	for _, row := range sample {
+		for i, value := range row.values {
+			types[i] = widen(types[i], typeOf(value))
+		}
+	}
+	return types
+}
+
+// fields returns the fields of columns of types. Every field is nullable, as rows after
+// the sample may hold NULLs.
+func fields(columns []string, types []columnType) schema.Fields {
+	fields := make(schema.Fields, len(columns))
+	for i, name := range columns {
+		fields[i] = &schema.Field{
+			Name: name,
+			Properties: schema.FieldProperties{
+				Type:     typeNames[types[i]],
+				Nullable: true,
+			},
+		}
+	}
+	return fields
+}
+
+// convert returns value as the Go type inserted into a column of columnType.
+func convert(value any, columnType columnType) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if _, ok := value.(jsonString); ok && columnType != typeText && columnType != typeUnknown {
+		return nil, fmt.Errorf("JSON string %q in a column of type %s", value, typeNames[columnType])
+	}
+	text := fmt.Sprint(value)
+	switch columnType {
+	case typeBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		return strconv.ParseBool(text)
+	case typeInteger:
+		return parseInteger(text)
+	case typeFloat:
+		return parseFloat(text)
+	case typeDate:
+		return time.Parse(dateLayout, text)
+	case typeTimestamp, typeTimestampTZ:
+		if t, err := time.Parse(dateLayout, text); err == nil {
+			return t, nil
+		}
+		t, _, err := parseTimestamp(text)
+		return t, err
+	default:
+		return text, nil
+	}
+}
+
+// parseInteger parses a decimal integer literal.
+func parseInteger(text string) (int64, error) {
+	if !integerPattern.MatchString(text) {
+		return 0, fmt.Errorf("%w: %q", errNotDecimal, text)
+	}
+	return strconv.ParseInt(text, 10, 64)
+}
+
+// parseFloat parses a decimal floating point literal.
+func parseFloat(text string) (float64, error) {
+	if !floatPattern.MatchString(text) {
+		return 0, fmt.Errorf("%w: %q", errNotDecimal, text)
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+// parseTimestamp parses value with the first of timestampLayouts that fits, returning it.
+func parseTimestamp(value string) (time.Time, string, error) {
+	var err error
+	for _, layout := range timestampLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", err
+}
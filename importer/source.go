@@ -0,0 +1,155 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// record is one row of the input, its values keyed by column. CSV records have values in
+// header order and no keys.
+type record struct {
+	line   int
+	keys   []string
+	values []any
+	err    error
+}
+
+type source interface {
+	// header returns the columns named by the input itself, nil for NDJSON.
+	header() []string
+	// next returns io.EOF after the last record, a record that fails to parse has err set.
+	next() (*record, error)
+}
+
+type csvSource struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func newCSVSource(r io.Reader, delimiter rune) (*csvSource, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	columns, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %w", err)
+	}
+	return &csvSource{reader: reader, columns: columns}, nil
+}
+
+func (s *csvSource) header() []string {
+	return s.columns
+}
+
+func (s *csvSource) next() (*record, error) {
+	fields, err := s.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if err != nil && !errors.As(err, &parseErr) {
+		return nil, err
+	}
+
+	line, _ := s.reader.FieldPos(0)
+	if parseErr != nil {
+		return &record{line: parseErr.StartLine, err: err}, nil
+	}
+	values := make([]any, len(fields))
+	for i, field := range fields {
+		if field == "" {
+			values[i] = nil
+		} else {
+			values[i] = field
+		}
+	}
+	return &record{line: line, values: values}, nil
+}
+
+type ndjsonSource struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONSource(r io.Reader) *ndjsonSource {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	return &ndjsonSource{scanner: scanner}
+}
+
+func (s *ndjsonSource) header() []string {
+	return nil
+}
+
+func (s *ndjsonSource) next() (*record, error) {
+	for s.scanner.Scan() {
+		s.line++
+		line := bytes.TrimSpace(s.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		keys, values, err := decodeObject(line)
+		return &record{line: s.line, keys: keys, values: values, err: err}, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// decodeObject decodes a JSON object, keeping the order of its keys. Nested objects and
+// arrays are kept as their JSON text.
+func decodeObject(data []byte) ([]string, []any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, nil, errors.New("line is not a JSON object")
+	}
+
+	var keys []string
+	var values []any
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := token.(string)
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+		value, err := decodeValue(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+	if decoder.More() {
+		return nil, nil, errors.New("line holds more than one JSON value")
+	}
+	return keys, values, nil
+}
+
+func decodeValue(raw json.RawMessage) (any, error) {
+	switch raw[0] {
+	case '{', '[':
+		return jsonText(raw), nil
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var value any
+		err := decoder.Decode(&value)
+		if text, ok := value.(string); ok {
+			return jsonString(text), err
+		}
+		return value, err
+	}
+}
@@ -5,8 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
-	"text/template"
+	"time"
 
 	"github.com/ctrl-alt-boop/dribble/datasource"
 	"github.com/ctrl-alt-boop/dribble/internal/adapters"
@@ -14,10 +13,14 @@ import (
 	"github.com/ctrl-alt-boop/dribble/result"
 )
 
+var (
+	_ datasource.Streamer   = (*Base)(nil)
+	_ datasource.Transactor = (*Base)(nil)
+)
+
 type SQLModel interface {
 	datasource.Model
-	GetTemplate(datasource.RequestType) string
-	GetPrefab(datasource.Request) (string, []any, error)
+	datasource.SQLAdapter
 	DriverName() string
 }
 
@@ -27,11 +30,14 @@ type Base struct {
 	Self  SQLModel
 	DB    *sql.DB
 	Namer datasource.Namer
+
+	backendIDs *backendIDs
 }
 
 func NewBase(dsn datasource.Namer) Base {
 	return Base{
-		Namer: dsn,
+		Namer:      dsn,
+		backendIDs: &backendIDs{},
 	}
 }
 
@@ -72,66 +78,111 @@ func (b *Base) Close(_ context.Context) error {
 	return b.DB.Close()
 }
 
+// Stats returns the statistics of the connection pool, the zero value before Open.
+func (b *Base) Stats() sql.DBStats {
+	if b.DB == nil {
+		return sql.DBStats{}
+	}
+	return b.DB.Stats()
+}
+
 func (b *Base) IsClosed() bool {
 	return b.DB == nil
 }
 
-// execute runs a single request against the database.
-func (b *Base) execute(ctx context.Context, req datasource.Request) (any, error) {
-	if err := b.Ping(ctx); err != nil {
-		return nil, err
-	}
-
-	intent, isIntent := req.(request.Intent)
-	if !isIntent {
-		if req.IsPrefab() {
-			fmt.Printf("got prefab request: %T\n", req)
-			queryString, queryArgs, err := b.Self.GetPrefab(req)
-			if err != nil {
-				return nil, fmt.Errorf("failed to render prefab request: %w", err)
-			}
+// Querier is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
 
-			return b.executeRead(ctx, queryString, queryArgs)
-		}
+// execute runs a single request against the database, or the transaction, q.
+func (b *Base) execute(ctx context.Context, q Querier, req datasource.Request) (any, error) {
+	if body, handled, err := b.readSchema(ctx, q, req); handled {
+		return body, err
 	}
-	queryString, queryArgs, err := b.renderRequest(intent)
+	if insert, ok := asInsertRows(req); ok {
+		return b.insertRows(ctx, q, insert)
+	}
+
+	requestType, queryString, queryArgs, err := b.Render(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to render intent request: %w", err)
+		return nil, err
 	}
 
-	switch intent.Type {
+	switch requestType {
 	case datasource.Create, datasource.Update, datasource.Delete:
-		return b.DB.ExecContext(ctx, queryString, queryArgs...)
+		if returnsRows(req) {
+			return b.executeReturning(ctx, q, queryString, queryArgs)
+		}
+		return b.executeWrite(ctx, q, requestType, queryString, queryArgs)
 	case datasource.Read:
-		return b.executeRead(ctx, queryString, queryArgs)
+		return b.executeRead(ctx, q, queryString, queryArgs)
 	default:
-		// Fallback to Exec for unknown types, could also be an error.
-		return b.DB.ExecContext(ctx, queryString, queryArgs...)
+		// Fallback to Exec for unknown types and request.ExecRequest, could also be an error.
+		return b.executeWrite(ctx, q, requestType, queryString, queryArgs)
 	}
 }
 
-// renderRequest converts a database.Request into a query string and arguments.
-func (b *Base) renderRequest(intent request.Intent) (string, []any, error) {
-	queryStringTemplate := b.Self.GetTemplate(intent.Type)
-	if queryStringTemplate == "" {
-		return "", nil, fmt.Errorf("no template found for request type %v in dialect %s", intent.Type, b.Self.Name())
+// executeWrite runs a statement that returns no rows. The last insert ID is only asked for
+// after inserts, as drivers like sqlite3's report that of an earlier insert otherwise.
+func (b *Base) executeWrite(ctx context.Context, q Querier, requestType datasource.RequestType, query string, args []any) (*result.Write, error) {
+	start := time.Now()
+	sqlResult, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	write := &result.Write{
+		RowsAffected: -1,
+		Statement:    query,
+		Args:         args,
+	}
+	if affected, err := sqlResult.RowsAffected(); err == nil {
+		write.RowsAffected = affected
 	}
+	if requestType == datasource.Create {
+		if id, err := sqlResult.LastInsertId(); err == nil {
+			write.LastInsertID = sql.Null[int64]{V: id, Valid: true}
+		}
+	}
+	write.Elapsed = time.Since(start)
+	return write, nil
+}
 
-	tmpl, err := template.New("query").Parse(queryStringTemplate)
-	if err != nil {
-		return "", nil, fmt.Errorf("error parsing query template: %w", err)
+// Render resolves a prefab or intent request into its request type, query string and arguments.
+func (b *Base) Render(req datasource.Request) (datasource.RequestType, string, []any, error) {
+	var intent request.Intent
+	switch r := req.(type) {
+	case request.Intent:
+		intent = r
+	case *request.Intent:
+		intent = *r
+	case request.ExecRequest:
+		return datasource.NoOp, r.Statement, r.Args, nil
+	case *request.ExecRequest:
+		return datasource.NoOp, r.Statement, r.Args, nil
+	default:
+		if !req.IsPrefab() {
+			return datasource.NoOp, "", nil, fmt.Errorf("unsupported request: %T", req)
+		}
+		queryString, queryArgs, err := b.Self.GetPrefab(req)
+		if err != nil {
+			return datasource.NoOp, "", nil, fmt.Errorf("failed to render prefab request: %w", err)
+		}
+		return datasource.Read, queryString, queryArgs, nil
 	}
-	operation := intent.Operation
-	var sb strings.Builder
-	if err := tmpl.Execute(&sb, operation); err != nil {
-		return "", nil, fmt.Errorf("error executing query template: %w", err)
+
+	queryString, queryArgs, err := b.renderRequest(intent)
+	if err != nil {
+		return datasource.NoOp, "", nil, fmt.Errorf("failed to render intent request: %w", err)
 	}
-	return strings.TrimSpace(sb.String()), intent.Args, nil
+	return intent.Type, queryString, queryArgs, nil
 }
 
-func (b *Base) executeRead(ctx context.Context, query string, args []any) (any, error) {
+func (b *Base) executeRead(ctx context.Context, q Querier, query string, args []any) (any, error) {
 	fmt.Printf("queryString: %+v\n", query)
-	rows, err := b.DB.QueryContext(ctx, query, args...)
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error executing query: %w", err)
 	}
@@ -139,17 +190,178 @@ func (b *Base) executeRead(ctx context.Context, query string, args []any) (any,
 
 	// If the result has only one column, treat it as a list.
 	if cols, _ := rows.Columns(); len(cols) == 1 {
-		return result.RowsToList(rows), nil
+		list := result.RowsToList(rows)
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error executing query: %w", err)
+		}
+		return list, nil
+	}
+	columns, dataRows := result.ParseRows(rows)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error executing query: %w", err)
+	}
+	table := result.NewTable(columns, dataRows)
+	table.Resolver = b.Self
+	return table, nil
+}
+
+// returner is implemented by write operations that can return the rows they affected.
+type returner interface {
+	ReturnsRows() bool
+}
+
+func returnsRows(req datasource.Request) bool {
+	var operation any
+	switch r := req.(type) {
+	case request.Intent:
+		operation = r.Operation
+	case *request.Intent:
+		operation = r.Operation
+	}
+	returning, ok := operation.(returner)
+	return ok && returning.ReturnsRows()
+}
+
+// executeReturning runs a write with a RETURNING clause through the read path,
+// the returned rows are always a table, even with a single column.
+func (b *Base) executeReturning(ctx context.Context, q Querier, query string, args []any) (*result.Write, error) {
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing query: %w", err)
 	}
+	defer rows.Close()
+
 	columns, dataRows := result.ParseRows(rows)
-	return result.NewTable(columns, dataRows), nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error executing query: %w", err)
+	}
+	table := result.NewTable(columns, dataRows)
+	table.Resolver = b.Self
+	return &result.Write{
+		RowsAffected: int64(len(dataRows)),
+		Returning:    table,
+		Statement:    query,
+		Args:         args,
+		Elapsed:      time.Since(start),
+	}, nil
+}
+
+// Stream implements datasource.Streamer.
+// The rows of a read are scanned and handed to emit chunkSize at a time, the
+// final chunk is marked and sent even when it holds no rows.
+func (b *Base) Stream(ctx context.Context, req datasource.Request, chunkSize int, emit func(any) error) error {
+	if err := b.Ping(ctx); err != nil {
+		return err
+	}
+
+	requestType, queryString, queryArgs, err := b.Render(req)
+	if err != nil {
+		return err
+	}
+	if requestType != datasource.Read {
+		return fmt.Errorf("only read requests can be streamed, got %v", requestType)
+	}
+
+	var q Querier = b.DB
+	if canceller, ok := b.Self.(BackendCanceller); ok {
+		conn, id, err := b.backendConn(ctx, canceller)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		defer b.cancelOnDone(ctx, canceller, id)()
+		q = conn
+	}
+
+	rows, err := q.QueryContext(ctx, queryString, queryArgs...)
+	if err != nil {
+		return fmt.Errorf("error executing query: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := result.ParseColumns(rows)
+	if err != nil {
+		return fmt.Errorf("error reading columns: %w", err)
+	}
+
+	chunk := result.NewChunk(0, columns, chunkSize)
+	chunk.Resolver = b.Self
+	for rows.Next() {
+		row, err := result.ScanRow(rows, len(columns))
+		if err != nil {
+			return fmt.Errorf("error scanning row: %w", err)
+		}
+		chunk.Rows = append(chunk.Rows, row)
+		if len(chunk.Rows) < chunkSize {
+			continue
+		}
+		if err := emit(chunk); err != nil {
+			return err
+		}
+		chunk = result.NewChunk(chunk.Index+1, columns, chunkSize)
+		chunk.Resolver = b.Self
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	chunk.Final = true
+	return emit(chunk)
 }
 
 func (b *Base) Request(ctx context.Context, request datasource.Request) (any, error) {
 	// if len(requests) != 1 {
 	// 	return nil, errors.New("SQL BaseSQL received multiple requests, but only supports one at a time")
 	// }
-	return b.execute(ctx, request)
+	if err := b.Ping(ctx); err != nil {
+		return nil, err
+	}
+	canceller, ok := b.Self.(BackendCanceller)
+	if !ok {
+		return b.execute(ctx, b.DB, request)
+	}
+	conn, id, err := b.backendConn(ctx, canceller)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	defer b.cancelOnDone(ctx, canceller, id)()
+	return b.execute(ctx, conn, request)
+}
+
+// Begin implements datasource.Transactor.
+func (b *Base) Begin(ctx context.Context, opts datasource.TxOptions) (datasource.Tx, error) {
+	if err := b.Ping(ctx); err != nil {
+		return nil, err
+	}
+	canceller, ok := b.Self.(BackendCanceller)
+	if !ok {
+		sqlTx, err := b.DB.BeginTx(ctx, txOptions(opts))
+		if err != nil {
+			return nil, fmt.Errorf("error beginning transaction: %w", err)
+		}
+		return &Tx{
+			base: b,
+			tx:   sqlTx,
+		}, nil
+	}
+
+	conn, id, err := b.backendConn(ctx, canceller)
+	if err != nil {
+		return nil, err
+	}
+	sqlTx, err := conn.BeginTx(ctx, txOptions(opts))
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("error beginning transaction: %w", err), conn.Close())
+	}
+	return &Tx{
+		base:      b,
+		tx:        sqlTx,
+		conn:      conn,
+		canceller: canceller,
+		backendID: id,
+	}, nil
 }
 
 // Type implements database.SQL.
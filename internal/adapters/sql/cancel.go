@@ -0,0 +1,90 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// cancelTimeout bounds the statement that stops a cancelled request on the server.
+const cancelTimeout = 5 * time.Second
+
+// BackendCanceller is implemented by dialects whose driver leaves a statement running on the server
+// when the context of its request is done, as go-sql-driver/mysql only closes the connection.
+// lib/pq sends a cancel request of its own and sqlite3 interrupts the statement, so neither needs it.
+// Requests then run on a connection of their own, whose session is stopped once their context is done.
+type BackendCanceller interface {
+	// BackendID returns the ID of the server session of conn.
+	BackendID(ctx context.Context, conn *sql.Conn) (int64, error)
+	// CancelBackend stops the running statement of the session id, if there is one.
+	CancelBackend(ctx context.Context, db *sql.DB, id int64) error
+}
+
+// backendIDs are the session IDs of the physical connections of a Base, so they are only asked
+// for once per connection. Closed connections are dropped once it outgrows the pool.
+type backendIDs struct {
+	mu  sync.Mutex
+	ids map[any]int64
+}
+
+func (c *backendIDs) get(driverConn any) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, ok := c.ids[driverConn]
+	return id, ok
+}
+
+func (c *backendIDs) put(driverConn any, id int64, openConnections int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ids == nil || len(c.ids) >= 2*max(openConnections, 1) {
+		c.ids = make(map[any]int64)
+	}
+	c.ids[driverConn] = id
+}
+
+// backendConn returns a connection of its own for a request and the ID of its session.
+func (b *Base) backendConn(ctx context.Context, canceller BackendCanceller) (*sql.Conn, int64, error) {
+	conn, err := b.DB.Conn(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	// The driver connection is only kept as a key, it identifies the session across requests.
+	var driverConn any
+	if err := conn.Raw(func(raw any) error {
+		driverConn = raw
+		return nil
+	}); err != nil {
+		return nil, 0, errors.Join(err, conn.Close())
+	}
+	if id, ok := b.backendIDs.get(driverConn); ok {
+		return conn, id, nil
+	}
+	id, err := canceller.BackendID(ctx, conn)
+	if err != nil {
+		return nil, 0, errors.Join(fmt.Errorf("error reading session id: %w", err), conn.Close())
+	}
+	b.backendIDs.put(driverConn, id, b.DB.Stats().OpenConnections)
+	return conn, id, nil
+}
+
+// cancelOnDone stops the statement of session id on the server once ctx is done. The returned
+// stop must be called before the connection is released, it waits for a cancellation that has
+// begun so it cannot hit a later statement of the session.
+func (b *Base) cancelOnDone(ctx context.Context, canceller BackendCanceller, id int64) (stop func()) {
+	cancelled := make(chan struct{})
+	stopAfter := context.AfterFunc(ctx, func() {
+		defer close(cancelled)
+		cancelCtx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+		defer cancel()
+		_ = canceller.CancelBackend(cancelCtx, b.DB, id) // The request reports the error of the driver
+	})
+	return func() {
+		if !stopAfter() {
+			<-cancelled
+		}
+	}
+}
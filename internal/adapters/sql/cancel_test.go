@@ -0,0 +1,49 @@
+package sql_test
+
+import (
+	"context"
+	gosql "database/sql"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ctrl-alt-boop/dribble/dsn"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/sqlite3"
+	"github.com/ctrl-alt-boop/dribble/request"
+)
+
+// countingCanceller is SQLite3 as a BackendCanceller, counting how often session IDs are read.
+type countingCanceller struct {
+	*sqlite3.SQLite3
+	reads atomic.Int32
+}
+
+func (c *countingCanceller) BackendID(ctx context.Context, conn *gosql.Conn) (int64, error) {
+	c.reads.Add(1)
+	return 1, nil
+}
+
+func (c *countingCanceller) CancelBackend(ctx context.Context, db *gosql.DB, id int64) error {
+	return nil
+}
+
+func TestBackendIDPerConnection(t *testing.T) {
+	ctx := context.Background()
+	source := sqlite3.New(dsn.SQLite3DSN(filepath.Join(t.TempDir(), "cancel.db"))).(*sqlite3.SQLite3)
+	canceller := &countingCanceller{SQLite3: source}
+	source.Self = canceller
+	if err := source.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close(ctx)
+	source.DB.SetMaxOpenConns(1)
+
+	for range 3 {
+		if _, err := source.Request(ctx, request.Exec("SELECT 1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if reads := canceller.reads.Load(); reads != 1 {
+		t.Errorf("session id read %d times for one connection, want once", reads)
+	}
+}
@@ -0,0 +1,125 @@
+package sql_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/mysql"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/postgres"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/sqlite3"
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	gosqlite3 "github.com/mattn/go-sqlite3"
+)
+
+type classifyCase struct {
+	name string
+	err  error
+	want datasource.ErrorCategory
+}
+
+func runClassifyCases(t *testing.T, classifier datasource.ErrorClassifier, cases []classifyCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := classifier.ClassifyError(tc.err); got != tc.want {
+				t.Errorf("ClassifyError(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+			// Wrapped errors classify the same
+			if got := classifier.ClassifyError(fmt.Errorf("wrapped: %w", tc.err)); got != tc.want {
+				t.Errorf("ClassifyError(wrapped %v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClassifyPostgres(t *testing.T) {
+	runClassifyCases(t, postgres.New(nil).(*postgres.Postgres), []classifyCase{
+		{"serialization_failure", &pq.Error{Code: "40001"}, datasource.ErrorConflict},
+		{"deadlock_detected", &pq.Error{Code: "40P01"}, datasource.ErrorConflict},
+		{"lock_not_available", &pq.Error{Code: "55P03"}, datasource.ErrorTransient},
+		{"too_many_connections", &pq.Error{Code: "53300"}, datasource.ErrorTransient},
+		{"connection_failure", &pq.Error{Code: "08006"}, datasource.ErrorConnection},
+		{"admin_shutdown", &pq.Error{Code: "57P01"}, datasource.ErrorConnection},
+		{"unique_violation", &pq.Error{Code: "23505"}, datasource.ErrorConstraint},
+		{"not_null_violation", &pq.Error{Code: "23502"}, datasource.ErrorConstraint},
+		{"syntax_error", &pq.Error{Code: "42601"}, datasource.ErrorSyntax},
+		{"undefined_table", &pq.Error{Code: "42P01"}, datasource.ErrorSyntax},
+		{"insufficient_privilege", &pq.Error{Code: "42501"}, datasource.ErrorPermission},
+		{"invalid_password", &pq.Error{Code: "28P01"}, datasource.ErrorPermission},
+		{"query_canceled", &pq.Error{Code: "57014"}, datasource.ErrorUnknown},
+		{"other driver", &gomysql.MySQLError{Number: 1213}, datasource.ErrorUnknown},
+	})
+}
+
+func TestClassifyMySQL(t *testing.T) {
+	runClassifyCases(t, mysql.New(nil).(*mysql.MySQL), []classifyCase{
+		{"deadlock", &gomysql.MySQLError{Number: 1213}, datasource.ErrorConflict},
+		{"lock wait timeout", &gomysql.MySQLError{Number: 1205}, datasource.ErrorTransient},
+		{"too many connections", &gomysql.MySQLError{Number: 1040}, datasource.ErrorTransient},
+		{"duplicate entry", &gomysql.MySQLError{Number: 1062}, datasource.ErrorConstraint},
+		{"foreign key", &gomysql.MySQLError{Number: 1452}, datasource.ErrorConstraint},
+		{"check constraint", &gomysql.MySQLError{Number: 3819}, datasource.ErrorConstraint},
+		{"parse error", &gomysql.MySQLError{Number: 1064}, datasource.ErrorSyntax},
+		{"no such table", &gomysql.MySQLError{Number: 1146}, datasource.ErrorSyntax},
+		{"access denied", &gomysql.MySQLError{Number: 1045}, datasource.ErrorPermission},
+		{"table access denied", &gomysql.MySQLError{Number: 1142}, datasource.ErrorPermission},
+		{"server shutdown", &gomysql.MySQLError{Number: 1053}, datasource.ErrorConnection},
+		{"invalid connection", gomysql.ErrInvalidConn, datasource.ErrorConnection},
+		{"unlisted", &gomysql.MySQLError{Number: 1366}, datasource.ErrorUnknown},
+		{"other driver", &pq.Error{Code: "40001"}, datasource.ErrorUnknown},
+	})
+}
+
+func TestClassifySQLite3(t *testing.T) {
+	runClassifyCases(t, sqlite3.New(nil).(*sqlite3.SQLite3), []classifyCase{
+		{"busy", gosqlite3.Error{Code: gosqlite3.ErrBusy}, datasource.ErrorTransient},
+		{"locked", gosqlite3.Error{Code: gosqlite3.ErrLocked}, datasource.ErrorTransient},
+		{"busy snapshot", gosqlite3.Error{Code: gosqlite3.ErrBusy, ExtendedCode: gosqlite3.ErrBusySnapshot}, datasource.ErrorConflict},
+		{"constraint", gosqlite3.Error{Code: gosqlite3.ErrConstraint, ExtendedCode: gosqlite3.ErrConstraintUnique}, datasource.ErrorConstraint},
+		{"readonly", gosqlite3.Error{Code: gosqlite3.ErrReadonly}, datasource.ErrorPermission},
+		{"auth", gosqlite3.Error{Code: gosqlite3.ErrAuth}, datasource.ErrorPermission},
+		{"cannot open", gosqlite3.Error{Code: gosqlite3.ErrCantOpen}, datasource.ErrorConnection},
+		{"full", gosqlite3.Error{Code: gosqlite3.ErrFull}, datasource.ErrorUnknown},
+	})
+}
+
+func TestClassify(t *testing.T) {
+	busy := &datasource.Error{Category: datasource.ErrorTransient, Err: errors.New("busy")}
+	for _, tc := range []classifyCase{
+		{"nil", nil, datasource.ErrorUnknown},
+		{"classified", fmt.Errorf("request: %w", busy), datasource.ErrorTransient},
+		{"bad connection", driver.ErrBadConn, datasource.ErrorConnection},
+		{"joined", errors.Join(errors.New("rollback"), driver.ErrBadConn), datasource.ErrorConnection},
+		{"deadline", fmt.Errorf("read: %w", context.DeadlineExceeded), datasource.ErrorUnknown},
+		{"other", errors.New("other"), datasource.ErrorUnknown},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := datasource.Classify(tc.err); got != tc.want {
+				t.Errorf("Classify(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+
+	sqlite := sqlite3.New(nil).(*sqlite3.SQLite3)
+	wrapped := datasource.ClassifyWith(sqlite, gosqlite3.Error{Code: gosqlite3.ErrBusy})
+	var classified *datasource.Error
+	if !errors.As(wrapped, &classified) || classified.Category != datasource.ErrorTransient {
+		t.Errorf("ClassifyWith(busy) = %#v, want a Transient *datasource.Error", wrapped)
+	}
+	var sqliteErr gosqlite3.Error
+	if !errors.As(wrapped, &sqliteErr) || sqliteErr.Code != gosqlite3.ErrBusy {
+		t.Errorf("ClassifyWith(busy) = %#v, want it to still wrap the error of the driver", wrapped)
+	}
+	if again := datasource.ClassifyWith(sqlite, wrapped); again != wrapped {
+		t.Errorf("ClassifyWith(classified) = %#v, want it returned as is", again)
+	}
+	unknown := errors.New("unknown")
+	if got := datasource.ClassifyWith(sqlite, unknown); got != unknown {
+		t.Errorf("ClassifyWith(unknown) = %#v, want it returned as is", got)
+	}
+}
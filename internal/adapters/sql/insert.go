@@ -0,0 +1,139 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/ctrl-alt-boop/dribble/result"
+)
+
+// maxInsertParameters keeps a multi-row INSERT within the bound parameters every dialect
+// accepts, SQLite3 allowing the fewest.
+const maxInsertParameters = 32766
+
+// RowInserter is implemented by dialects with a faster way to insert many rows than
+// multi-row INSERTs. InsertRows returns the number of rows inserted.
+type RowInserter interface {
+	InsertRows(ctx context.Context, q Querier, req request.InsertRowsRequest) (int64, error)
+}
+
+func asInsertRows(req datasource.Request) (request.InsertRowsRequest, bool) {
+	switch r := req.(type) {
+	case request.InsertRowsRequest:
+		return r, true
+	case *request.InsertRowsRequest:
+		return *r, true
+	default:
+		return request.InsertRowsRequest{}, false
+	}
+}
+
+// insertRows responds with the number of rows inserted as a result.Write.
+func (b *Base) insertRows(ctx context.Context, q Querier, req request.InsertRowsRequest) (any, error) {
+	if len(req.Columns) == 0 {
+		return nil, errors.New("no columns to insert into")
+	}
+	for i, row := range req.Rows {
+		if len(row) != len(req.Columns) {
+			return nil, fmt.Errorf("row %d has %d values for %d columns", i, len(row), len(req.Columns))
+		}
+	}
+	if len(req.Rows) == 0 {
+		return &result.Write{}, nil
+	}
+
+	start := time.Now()
+	var inserted int64
+	var err error
+	if inserter, ok := b.Self.(RowInserter); ok {
+		inserted, err = inserter.InsertRows(ctx, q, req)
+	} else {
+		inserted, err = b.insertMultiRow(ctx, q, req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error inserting rows: %w", err)
+	}
+	return &result.Write{RowsAffected: inserted, Elapsed: time.Since(start)}, nil
+}
+
+func (b *Base) insertMultiRow(ctx context.Context, q Querier, req request.InsertRowsRequest) (int64, error) {
+	rowsPerStatement := max(maxInsertParameters/len(req.Columns), 1)
+	var inserted int64
+	err := WithinTx(ctx, q, func(tx *sql.Tx) error {
+		for start := 0; start < len(req.Rows); start += rowsPerStatement {
+			rows := req.Rows[start:min(start+rowsPerStatement, len(req.Rows))]
+			values := make([]string, len(rows))
+			args := make([]any, 0, len(rows)*len(req.Columns))
+			for i, row := range rows {
+				placeholders := make([]string, len(row))
+				for j := range row {
+					placeholders[j] = b.Self.RenderPlaceholder(len(args) + j + 1)
+				}
+				values[i] = "(" + strings.Join(placeholders, ", ") + ")"
+				args = append(args, row...)
+			}
+
+			statement := InsertStatement(b.Self, req.Table, req.Columns) + strings.Join(values, ", ")
+			sqlResult, err := tx.ExecContext(ctx, statement, args...)
+			if err != nil {
+				return err
+			}
+			affected, err := sqlResult.RowsAffected()
+			if err != nil {
+				return err
+			}
+			inserted += affected
+		}
+		return nil
+	})
+	return inserted, err
+}
+
+// InsertStatement returns "INSERT INTO table (columns) VALUES ", quoted by dialect.
+// The table may be qualified by a schema.
+func InsertStatement(dialect datasource.SQLAdapter, table string, columns []string) string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = dialect.Quote(column)
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES ", QuoteQualified(dialect, table), strings.Join(names, ", "))
+}
+
+// QuoteQualified quotes each part of a dotted name.
+func QuoteQualified(dialect datasource.SQLAdapter, name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = dialect.Quote(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// txBeginner is implemented by *sql.DB and *sql.Conn.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// WithinTx runs fn in the transaction q, or in one begun on q and committed when fn succeeds.
+func WithinTx(ctx context.Context, q Querier, fn func(*sql.Tx) error) error {
+	switch q := q.(type) {
+	case *sql.Tx:
+		return fn(q)
+	case txBeginner:
+		tx, err := q.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("error beginning transaction: %w", err)
+		}
+		if err := fn(tx); err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+		return tx.Commit()
+	default:
+		return fmt.Errorf("unsupported querier %T", q)
+	}
+}
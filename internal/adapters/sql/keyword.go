@@ -70,3 +70,17 @@ const (
 	Execute // EXECUTE
 	Pragma  // PRAGMA
 )
+
+var keywordsByName = func() map[string]Keyword {
+	keywords := make(map[string]Keyword, Pragma+1)
+	for keyword := Select; keyword <= Pragma; keyword++ {
+		keywords[keyword.String()] = keyword
+	}
+	return keywords
+}()
+
+// ParseKeyword looks up a keyword by its SQL, e.g. "IS DISTINCT FROM".
+func ParseKeyword(name string) (Keyword, bool) {
+	keyword, ok := keywordsByName[name]
+	return keyword, ok
+}
@@ -0,0 +1,78 @@
+package sql
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Literals are the forms a dialect writes its literals in, used by RenderLiteral.
+type Literals struct {
+	True, False string
+
+	// Bytes renders a binary value from its hex digits.
+	Bytes func(hexDigits string) string
+	// NonFinite renders NaN and the infinities, which have no standard literal.
+	NonFinite func(value float64) string
+
+	TimeLayout      string
+	EscapeBackslash bool // Backslashes escape in string literals, as in MySQL by default
+}
+
+// RenderLiteral renders value as a literal to be inlined in a statement. Values of
+// unknown types are rendered as the string literal of their default format.
+func RenderLiteral(value any, literals Literals) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		resolved, err := valuer.Value()
+		if err != nil {
+			return literals.quote(fmt.Sprint(value))
+		}
+		value = resolved
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return literals.quote(value)
+	case []byte:
+		return literals.Bytes(hex.EncodeToString(value))
+	case bool:
+		if value {
+			return literals.True
+		}
+		return literals.False
+	case int:
+		return strconv.Itoa(value)
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(value)
+	case float32:
+		return literals.float(float64(value), 32)
+	case float64:
+		return literals.float(value, 64)
+	case time.Time:
+		return literals.quote(value.Format(literals.TimeLayout))
+	case fmt.Stringer:
+		return literals.quote(value.String())
+	default:
+		return literals.quote(fmt.Sprint(value))
+	}
+}
+
+func (l Literals) quote(value string) string {
+	if l.EscapeBackslash {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (l Literals) float(value float64, bitSize int) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return l.NonFinite(value)
+	}
+	return strconv.FormatFloat(value, 'g', -1, bitSize)
+}
@@ -0,0 +1,24 @@
+package mysql
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql"
+)
+
+var _ sql.BackendCanceller = (*MySQL)(nil)
+
+// BackendID implements sql.BackendCanceller.
+func (m *MySQL) BackendID(ctx context.Context, conn *gosql.Conn) (int64, error) {
+	var id int64
+	err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id)
+	return id, err
+}
+
+// CancelBackend implements sql.BackendCanceller with KILL QUERY, which leaves the session open.
+func (m *MySQL) CancelBackend(ctx context.Context, db *gosql.DB, id int64) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", id))
+	return err
+}
@@ -0,0 +1,36 @@
+package mysql
+
+import (
+	"errors"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/go-sql-driver/mysql"
+)
+
+var _ datasource.ErrorClassifier = (*MySQL)(nil)
+
+// ClassifyError implements datasource.ErrorClassifier by the error number of the server.
+func (m *MySQL) ClassifyError(err error) datasource.ErrorCategory {
+	if errors.Is(err, mysql.ErrInvalidConn) {
+		return datasource.ErrorConnection
+	}
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return datasource.ErrorUnknown
+	}
+	switch mysqlErr.Number {
+	case 1213: // ER_LOCK_DEADLOCK
+		return datasource.ErrorConflict
+	case 1205, 1040: // ER_LOCK_WAIT_TIMEOUT, ER_CON_COUNT_ERROR
+		return datasource.ErrorTransient
+	case 1062, 1048, 1216, 1217, 1451, 1452, 3819: // ER_DUP_ENTRY, ER_BAD_NULL_ERROR, foreign keys, ER_CHECK_CONSTRAINT_VIOLATED
+		return datasource.ErrorConstraint
+	case 1064, 1054, 1146: // ER_PARSE_ERROR, ER_BAD_FIELD_ERROR, ER_NO_SUCH_TABLE
+		return datasource.ErrorSyntax
+	case 1044, 1045, 1142, 1143, 1227: // access denied to a database, user, table, column or operation
+		return datasource.ErrorPermission
+	case 1053: // ER_SERVER_SHUTDOWN
+		return datasource.ErrorConnection
+	}
+	return datasource.ErrorUnknown
+}
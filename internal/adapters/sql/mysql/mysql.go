@@ -3,6 +3,7 @@ package mysql
 import (
 	_ "embed"
 	"fmt"
+	"strings"
 	"text/template"
 
 	"github.com/ctrl-alt-boop/dribble/datasource"
@@ -99,30 +100,58 @@ func (m *MySQL) GetPrefab(r datasource.Request) (string, []any, error) {
 		return PrefabColumns, []any{r.TableName}, nil
 	case *request.ReadColumnNames:
 		return PrefabColumns, []any{r.TableName}, nil
+	case request.ReadIndexes:
+		return PrefabIndexes, []any{r.DatabaseName, r.TableName}, nil
+	case *request.ReadIndexes:
+		return PrefabIndexes, []any{r.DatabaseName, r.TableName}, nil
+	case request.ReadForeignKeys:
+		return PrefabForeignKeys, []any{r.DatabaseName, r.TableName}, nil
+	case *request.ReadForeignKeys:
+		return PrefabForeignKeys, []any{r.DatabaseName, r.TableName}, nil
+	case request.ReadConstraints:
+		return PrefabConstraints, []any{r.DatabaseName, r.TableName}, nil
+	case *request.ReadConstraints:
+		return PrefabConstraints, []any{r.DatabaseName, r.TableName}, nil
+	case request.ReadViews:
+		return PrefabViews, []any{r.DatabaseName}, nil
+	case *request.ReadViews:
+		return PrefabViews, []any{r.DatabaseName}, nil
+	case request.ReadRoutines:
+		return PrefabRoutines, []any{r.DatabaseName}, nil
+	case *request.ReadRoutines:
+		return PrefabRoutines, []any{r.DatabaseName}, nil
+	case request.ReadTriggers:
+		return PrefabTriggers, []any{r.DatabaseName}, nil
+	case *request.ReadTriggers:
+		return PrefabTriggers, []any{r.DatabaseName}, nil
 	default:
 		return "", nil, fmt.Errorf("unknown prefab request: %T", r)
 	}
 }
 
-// ResolveType implements database.SQLDialect.
-func (m *MySQL) ResolveType(dbType string, value []byte) (any, error) {
-	panic("unimplemented")
-}
-
 //go:embed templates/select.tmpl
 var selectQueryTemplate string
 
+//go:embed templates/insert.tmpl
+var insertQueryTemplate string
+
+//go:embed templates/update.tmpl
+var updateQueryTemplate string
+
+//go:embed templates/delete.tmpl
+var deleteQueryTemplate string
+
 // GetTemplate implements database.Dialect.
 func (m *MySQL) GetTemplate(queryType datasource.RequestType) string {
 	switch queryType {
 	case datasource.Read:
 		return selectQueryTemplate
 	case datasource.Create:
-		return "" // DefaultSQLInsertTemplate
+		return insertQueryTemplate
 	case datasource.Update:
-		return "" // DefaultSQLUpdateTemplate
+		return updateQueryTemplate
 	case datasource.Delete:
-		return "" // DefaultSQLDeleteTemplate
+		return deleteQueryTemplate
 	default:
 		return ""
 	}
@@ -135,12 +164,12 @@ func (m *MySQL) IncreamentPlaceholder() string {
 
 // Quote implements database.Dialect.
 func (m *MySQL) Quote(value string) string {
-	return fmt.Sprintf(`"%s"`, value)
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(value, "`", "``"))
 }
 
 // QuoteRune implements database.Dialect.
 func (m *MySQL) QuoteRune() rune {
-	return '"'
+	return '`'
 }
 
 // RenderCurrentTimestamp implements database.Dialect.
@@ -148,6 +177,25 @@ func (m *MySQL) RenderCurrentTimestamp() string {
 	return "NOW()"
 }
 
+// RenderOperator implements the OperatorDialect of the dribble sql package.
+func (m *MySQL) RenderOperator(name string) (string, error) {
+	keyword, _ := sql.ParseKeyword(name)
+	switch keyword {
+	case sql.Ilike:
+		return "LOWER(%[1]s) LIKE LOWER(%[2]s)", nil
+	case sql.NotIlike:
+		return "LOWER(%[1]s) NOT LIKE LOWER(%[2]s)", nil
+	case sql.IsDistinctFrom:
+		return "NOT (%[1]s <=> %[2]s)", nil
+	case sql.IsNotDistinctFrom:
+		return "%[1]s <=> %[2]s", nil
+	case sql.IntersectAll, sql.ExceptAll:
+		return "", sql.Unsupported(name, m.Name())
+	default:
+		return "", nil
+	}
+}
+
 // RenderPlaceholder implements database.Dialect.
 func (m *MySQL) RenderPlaceholder(index int) string {
 	return "?"
@@ -158,7 +206,17 @@ func (m *MySQL) RenderTypeCast() string { // FIXME
 	return "::"
 }
 
+var literals = sql.Literals{
+	True:  "TRUE",
+	False: "FALSE",
+	Bytes: func(hexDigits string) string { return "X'" + hexDigits + "'" },
+	// MySQL has no NaN or infinity.
+	NonFinite:       func(float64) string { return "NULL" },
+	TimeLayout:      "2006-01-02 15:04:05.999999",
+	EscapeBackslash: true,
+}
+
 // RenderValue implements database.Dialect.
 func (m *MySQL) RenderValue(value any) string {
-	return fmt.Sprintf("%v", value)
+	return sql.RenderLiteral(value, literals)
 }
@@ -0,0 +1,103 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/result"
+)
+
+// ResolveType decodes the text protocol values of go-sql-driver/mysql, which returns
+// every column as bytes unless parseTime is set in the DSN.
+func (m *MySQL) ResolveType(dbType string, value []byte) (any, error) {
+	switch dbType {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR":
+		return strconv.ParseInt(string(value), 10, 64)
+	case "UNSIGNED TINYINT", "UNSIGNED SMALLINT", "UNSIGNED MEDIUMINT", "UNSIGNED INT", "UNSIGNED BIGINT":
+		return strconv.ParseUint(string(value), 10, 64)
+	case "FLOAT", "DOUBLE":
+		return strconv.ParseFloat(string(value), 64)
+	case "DECIMAL":
+		return result.Decimal(value), nil
+	case "JSON":
+		return json.RawMessage(bytes.Clone(value)), nil
+	case "BIT":
+		return parseBit(value)
+	case "DATETIME", "TIMESTAMP", "DATE":
+		return parseDateTime(string(value))
+	case "TIME":
+		return parseTime(string(value))
+	case "SET":
+		if len(value) == 0 {
+			return []string{}, nil
+		}
+		return strings.Split(string(value), ","), nil
+	case "ENUM", "CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT":
+		return string(value), nil
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "GEOMETRY", "VECTOR":
+		return bytes.Clone(value), nil
+	default:
+		return string(value), nil
+	}
+}
+
+// parseBit returns the big-endian value of a BIT(1) to BIT(64) column.
+func parseBit(value []byte) (uint64, error) {
+	if len(value) > 8 {
+		return 0, fmt.Errorf("BIT value of %d bytes does not fit in 64 bits", len(value))
+	}
+	var padded [8]byte
+	copy(padded[8-len(value):], value)
+	return binary.BigEndian.Uint64(padded[:]), nil
+}
+
+var dateTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999",
+	"2006-01-02",
+}
+
+// parseDateTime returns the zero time for the zero dates MySQL allows, e.g. 0000-00-00 00:00:00.
+func parseDateTime(value string) (time.Time, error) {
+	if strings.HasPrefix(value, "0000-00-00") {
+		return time.Time{}, nil
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported datetime %q", value)
+}
+
+// parseTime decodes a TIME column, [-]HHH:MM:SS[.ffffff], as a duration since TIME
+// ranges from -838:59:59 to 838:59:59.
+func parseTime(value string) (time.Duration, error) {
+	clock, negative := strings.CutPrefix(value, "-")
+	parts := strings.Split(clock, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("malformed time %q", value)
+	}
+	hours, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed time %q: %w", value, err)
+	}
+	minutes, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed time %q: %w", value, err)
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed time %q: %w", value, err)
+	}
+	d := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)).Round(time.Microsecond)
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,216 @@
+package mysql
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql"
+	"github.com/ctrl-alt-boop/dribble/schema"
+)
+
+var _ sql.SchemaReader = (*MySQL)(nil)
+
+const (
+	schemaTablesQuery = `SELECT TABLE_NAME FROM information_schema.TABLES
+WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_TYPE = 'BASE TABLE'
+ORDER BY TABLE_NAME`
+
+	// AUTO_INCREMENT is the counter of the table, the value the next row gets.
+	schemaTableQuery = `SELECT TABLE_COMMENT, AUTO_INCREMENT FROM information_schema.TABLES
+WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`
+
+	schemaGeneratedQuery = `SELECT COLUMN_NAME, GENERATION_EXPRESSION FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND GENERATION_EXPRESSION <> ''`
+
+	schemaIndexedColumnsQuery = `SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+ORDER BY INDEX_NAME, SEQ_IN_INDEX`
+)
+
+const (
+	// Functional indexes have no column and are left out.
+	PrefabIndexes = `SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE = 0, INDEX_TYPE, INDEX_NAME = 'PRIMARY'
+FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ? AND COLUMN_NAME IS NOT NULL
+ORDER BY INDEX_NAME, SEQ_IN_INDEX`
+
+	PrefabForeignKeys = `SELECT k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME, r.UPDATE_RULE, r.DELETE_RULE
+FROM information_schema.KEY_COLUMN_USAGE k
+JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+	ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME AND r.TABLE_NAME = k.TABLE_NAME
+WHERE k.TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND k.TABLE_NAME = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION`
+
+	// CHECK_CONSTRAINTS needs MySQL 8.0.16 or later.
+	PrefabConstraints = `SELECT tc.CONSTRAINT_NAME, tc.CONSTRAINT_TYPE, k.COLUMN_NAME, cc.CHECK_CLAUSE
+FROM information_schema.TABLE_CONSTRAINTS tc
+LEFT JOIN information_schema.KEY_COLUMN_USAGE k
+	ON k.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND k.CONSTRAINT_NAME = tc.CONSTRAINT_NAME AND k.TABLE_NAME = tc.TABLE_NAME
+LEFT JOIN information_schema.CHECK_CONSTRAINTS cc
+	ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+WHERE tc.TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND tc.TABLE_NAME = ?
+ORDER BY tc.CONSTRAINT_NAME, k.ORDINAL_POSITION`
+
+	// MySQL has no materialized views.
+	PrefabViews = `SELECT TABLE_NAME, false, VIEW_DEFINITION FROM information_schema.VIEWS
+WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+ORDER BY TABLE_NAME`
+
+	// The parameter at position 0 is the result of a function, and the definition is the body of the routine.
+	PrefabRoutines = `SELECT r.SPECIFIC_NAME, r.ROUTINE_NAME, r.ROUTINE_TYPE,
+	CASE WHEN r.ROUTINE_TYPE = 'FUNCTION' THEN r.DTD_IDENTIFIER END,
+	r.ROUTINE_DEFINITION,
+	p.PARAMETER_NAME, p.DTD_IDENTIFIER, p.PARAMETER_MODE
+FROM information_schema.ROUTINES r
+LEFT JOIN information_schema.PARAMETERS p
+	ON p.SPECIFIC_SCHEMA = r.ROUTINE_SCHEMA AND p.SPECIFIC_NAME = r.SPECIFIC_NAME AND p.ORDINAL_POSITION > 0
+WHERE r.ROUTINE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+ORDER BY r.ROUTINE_NAME, r.SPECIFIC_NAME, p.ORDINAL_POSITION`
+
+	PrefabTriggers = `SELECT TRIGGER_NAME, EVENT_OBJECT_TABLE, ACTION_TIMING, EVENT_MANIPULATION, ACTION_STATEMENT
+FROM information_schema.TRIGGERS
+WHERE TRIGGER_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+ORDER BY TRIGGER_NAME`
+)
+
+// ReadTableNames implements sql.SchemaReader.
+func (m *MySQL) ReadTableNames(ctx context.Context, q sql.Querier, databaseName string) ([]string, error) {
+	return sql.QueryStrings(ctx, q, schemaTablesQuery, databaseName)
+}
+
+// ReadTableSchema implements sql.SchemaReader.
+func (m *MySQL) ReadTableSchema(ctx context.Context, q sql.Querier, databaseName, tableName string) (*schema.Table, error) {
+	if databaseName == "" {
+		if err := q.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&databaseName); err != nil {
+			return nil, fmt.Errorf("error reading current database: %w", err)
+		}
+	}
+
+	table := &schema.Table{Name: tableName}
+	var autoIncrement gosql.NullString
+	if err := q.QueryRowContext(ctx, schemaTableQuery, databaseName, tableName).Scan(&table.Description, &autoIncrement); err != nil {
+		if err == gosql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s in database %s", schema.ErrTableNotFound, tableName, databaseName)
+		}
+		return nil, fmt.Errorf("error reading table %s: %w", tableName, err)
+	}
+
+	if err := m.readFields(ctx, q, databaseName, table, autoIncrement); err != nil {
+		return nil, err
+	}
+	if err := m.readGenerated(ctx, q, databaseName, table); err != nil {
+		return nil, err
+	}
+	if err := m.readIndexedFields(ctx, q, databaseName, table); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// readFields reads the columns with SHOW FULL COLUMNS, whose Type is the full column type, like "enum('a','b')".
+func (m *MySQL) readFields(ctx context.Context, q sql.Querier, databaseName string, table *schema.Table, autoIncrement gosql.NullString) error {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SHOW FULL COLUMNS FROM %s FROM %s", m.Quote(table.Name), m.Quote(databaseName)))
+	if err != nil {
+		return fmt.Errorf("error reading columns of %s: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			name, columnType, null, key, extra, privileges, comment string
+			collation, columnDefault                                gosql.NullString
+		)
+		if err := rows.Scan(&name, &columnType, &collation, &null, &key, &columnDefault, &extra, &privileges, &comment); err != nil {
+			return fmt.Errorf("error scanning column of %s: %w", table.Name, err)
+		}
+
+		field := &schema.Field{
+			Name:        name,
+			Description: comment,
+			Properties: schema.FieldProperties{
+				Nullable:  null == "YES",
+				Default:   sql.NullStringPointer(columnDefault),
+				Overrides: schema.Overrides{Collation: collation.String},
+			},
+		}
+		sql.ParseColumnType(columnType, &field.Properties)
+
+		extra = strings.ToUpper(extra)
+		switch {
+		case strings.Contains(extra, "AUTO_INCREMENT"):
+			seed := "1"
+			if autoIncrement.Valid {
+				seed = autoIncrement.String
+			}
+			field.Properties.AutoInc = &seed
+		case strings.Contains(extra, "VIRTUAL GENERATED"):
+			field.Properties.Virtual = true
+			fallthrough
+		case strings.Contains(extra, "STORED GENERATED"):
+			expression := ""
+			field.Properties.Generated = &expression
+		}
+		table.Fields = append(table.Fields, field)
+	}
+	return rows.Err()
+}
+
+func (m *MySQL) readGenerated(ctx context.Context, q sql.Querier, databaseName string, table *schema.Table) error {
+	rows, err := q.QueryContext(ctx, schemaGeneratedQuery, databaseName, table.Name)
+	if err != nil {
+		return fmt.Errorf("error reading generated columns of %s: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, expression string
+		if err := rows.Scan(&name, &expression); err != nil {
+			return fmt.Errorf("error scanning generated column of %s: %w", table.Name, err)
+		}
+		if field := table.Field(name); field != nil {
+			field.Properties.Generated = &expression
+		}
+	}
+	return rows.Err()
+}
+
+// readIndexedFields reads the primary key, and marks the fields covered by an index and those unique on their own.
+func (m *MySQL) readIndexedFields(ctx context.Context, q sql.Querier, databaseName string, table *schema.Table) error {
+	rows, err := q.QueryContext(ctx, schemaIndexedColumnsQuery, databaseName, table.Name)
+	if err != nil {
+		return fmt.Errorf("error reading indexes of %s: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	var indexNames []string
+	columns := map[string][]string{}
+	unique := map[string]bool{}
+	for rows.Next() {
+		var indexName, column string
+		var nonUnique int
+		if err := rows.Scan(&indexName, &column, &nonUnique); err != nil {
+			return fmt.Errorf("error scanning index of %s: %w", table.Name, err)
+		}
+		if _, seen := columns[indexName]; !seen {
+			indexNames = append(indexNames, indexName)
+		}
+		columns[indexName] = append(columns[indexName], column)
+		unique[indexName] = nonUnique == 0
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, indexName := range indexNames {
+		for _, column := range columns[indexName] {
+			if field := table.Field(column); field != nil {
+				field.Properties.Index = true
+				field.Properties.Unique = field.Properties.Unique || (unique[indexName] && len(columns[indexName]) == 1)
+			}
+		}
+	}
+	sql.SetPrimaryKeys(table, columns["PRIMARY"])
+	return nil
+}
@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/lib/pq"
+)
+
+var _ datasource.ErrorClassifier = (*Postgres)(nil)
+
+// ClassifyError implements datasource.ErrorClassifier by the SQLSTATE of the error.
+func (p *Postgres) ClassifyError(err error) datasource.ErrorCategory {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return datasource.ErrorUnknown
+	}
+	switch pqErr.Code {
+	case "40001", "40P01": // serialization_failure, deadlock_detected
+		return datasource.ErrorConflict
+	case "55P03": // lock_not_available
+		return datasource.ErrorTransient
+	case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+		return datasource.ErrorConnection
+	case "42501": // insufficient_privilege
+		return datasource.ErrorPermission
+	}
+	switch pqErr.Code.Class() {
+	case "53": // insufficient_resources
+		return datasource.ErrorTransient
+	case "08": // connection_exception
+		return datasource.ErrorConnection
+	case "23": // integrity_constraint_violation
+		return datasource.ErrorConstraint
+	case "28": // invalid_authorization_specification
+		return datasource.ErrorPermission
+	case "42": // syntax_error_or_access_rule_violation
+		return datasource.ErrorSyntax
+	}
+	return datasource.ErrorUnknown
+}
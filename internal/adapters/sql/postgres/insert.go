@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	gosql "database/sql"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql"
+	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/lib/pq"
+)
+
+var _ sql.RowInserter = (*Postgres)(nil)
+
+// InsertRows implements sql.RowInserter with COPY FROM STDIN, which takes a transaction.
+func (p *Postgres) InsertRows(ctx context.Context, q sql.Querier, req request.InsertRowsRequest) (int64, error) {
+	copyIn := pq.CopyIn(req.Table, req.Columns...)
+	if schemaName, table, qualified := strings.Cut(req.Table, "."); qualified {
+		copyIn = pq.CopyInSchema(schemaName, table, req.Columns...)
+	}
+
+	err := sql.WithinTx(ctx, q, func(tx *gosql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, copyIn)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, row := range req.Rows {
+			if _, err := stmt.ExecContext(ctx, row...); err != nil {
+				return err
+			}
+		}
+		// The call without values flushes the buffered rows.
+		_, err = stmt.ExecContext(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(req.Rows)), nil
+}
@@ -1,14 +1,16 @@
 package postgres
 
 import (
+	_ "embed"
 	"fmt"
+	"math"
+	"strings"
 	"text/template"
 
 	"github.com/ctrl-alt-boop/dribble/datasource"
 
 	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql"
 	"github.com/ctrl-alt-boop/dribble/request"
-	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 )
 
@@ -86,15 +88,6 @@ func (p *Postgres) ConnectionStringTemplate() *template.Template {
 	return tmpl
 }
 
-func (p *Postgres) ResolveType(dbType string, value []byte) (any, error) {
-	switch dbType {
-	case "UUID":
-		return uuid.ParseBytes(value)
-	default:
-		return string(value), nil
-	}
-}
-
 func (p *Postgres) GetPrefab(r datasource.Request) (string, []any, error) {
 	switch r := r.(type) {
 	case request.ReadDatabaseNames:
@@ -108,22 +101,58 @@ func (p *Postgres) GetPrefab(r datasource.Request) (string, []any, error) {
 			return fmt.Sprintf(PrefabCountDBFormat, r.DatabaseName, r.TableName), nil, nil
 		}
 		return fmt.Sprintf(PrefabCountFormat, r.TableName), nil, nil
+	case request.ReadIndexes:
+		return PrefabIndexes, []any{r.DatabaseName, r.TableName}, nil
+	case *request.ReadIndexes:
+		return PrefabIndexes, []any{r.DatabaseName, r.TableName}, nil
+	case request.ReadForeignKeys:
+		return PrefabForeignKeys, []any{r.DatabaseName, r.TableName}, nil
+	case *request.ReadForeignKeys:
+		return PrefabForeignKeys, []any{r.DatabaseName, r.TableName}, nil
+	case request.ReadConstraints:
+		return PrefabConstraints, []any{r.DatabaseName, r.TableName}, nil
+	case *request.ReadConstraints:
+		return PrefabConstraints, []any{r.DatabaseName, r.TableName}, nil
+	case request.ReadViews:
+		return PrefabViews, []any{r.DatabaseName}, nil
+	case *request.ReadViews:
+		return PrefabViews, []any{r.DatabaseName}, nil
+	case request.ReadRoutines:
+		return PrefabRoutines, []any{r.DatabaseName}, nil
+	case *request.ReadRoutines:
+		return PrefabRoutines, []any{r.DatabaseName}, nil
+	case request.ReadTriggers:
+		return PrefabTriggers, []any{r.DatabaseName}, nil
+	case *request.ReadTriggers:
+		return PrefabTriggers, []any{r.DatabaseName}, nil
 	default:
 		return "", nil, fmt.Errorf("unknown prefab request: %T", r)
 	}
 }
 
+//go:embed templates/select.tmpl
+var selectQueryTemplate string
+
+//go:embed templates/insert.tmpl
+var insertQueryTemplate string
+
+//go:embed templates/update.tmpl
+var updateQueryTemplate string
+
+//go:embed templates/delete.tmpl
+var deleteQueryTemplate string
+
 // GetTemplate implements database.Dialect.
 func (p *Postgres) GetTemplate(queryType datasource.RequestType) string {
 	switch queryType {
 	case datasource.Read:
-		return ""
+		return selectQueryTemplate
 	case datasource.Create:
-		return "" // DefaultSQLInsertTemplate
+		return insertQueryTemplate
 	case datasource.Update:
-		return "" // DefaultSQLUpdateTemplate
+		return updateQueryTemplate
 	case datasource.Delete:
-		return "" // DefaultSQLDeleteTemplate
+		return deleteQueryTemplate
 	default:
 		return ""
 	}
@@ -136,7 +165,7 @@ func (p *Postgres) IncreamentPlaceholder() string {
 
 // Quote implements database.Dialect.
 func (p *Postgres) Quote(value string) string {
-	return fmt.Sprintf(`"%s"`, value)
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(value, `"`, `""`))
 }
 
 // QuoteRune implements database.Dialect.
@@ -149,6 +178,12 @@ func (p *Postgres) RenderCurrentTimestamp() string {
 	return "NOW()"
 }
 
+// RenderOperator implements the OperatorDialect of the dribble sql package.
+func (p *Postgres) RenderOperator(name string) (string, error) {
+	// PostgreSQL has every keyword natively.
+	return "", nil
+}
+
 // RenderPlaceholder implements database.Dialect.
 func (p *Postgres) RenderPlaceholder(index int) string {
 	return fmt.Sprintf("$%d", index)
@@ -159,9 +194,26 @@ func (p *Postgres) RenderTypeCast() string { // FIXME
 	return "::"
 }
 
+var literals = sql.Literals{
+	True:  "TRUE",
+	False: "FALSE",
+	Bytes: func(hexDigits string) string { return `'\x` + hexDigits + "'::bytea" },
+	NonFinite: func(value float64) string {
+		switch {
+		case math.IsNaN(value):
+			return "'NaN'::float8"
+		case value > 0:
+			return "'Infinity'::float8"
+		default:
+			return "'-Infinity'::float8"
+		}
+	},
+	TimeLayout: "2006-01-02 15:04:05.999999999-07:00",
+}
+
 // RenderValue implements database.Dialect.
 func (p *Postgres) RenderValue(value any) string {
-	return fmt.Sprintf("%v", value)
+	return sql.RenderLiteral(value, literals)
 }
 
 const (
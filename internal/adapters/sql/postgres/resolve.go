@@ -0,0 +1,346 @@
+package postgres
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/result"
+	"github.com/google/uuid"
+)
+
+// ResolveType decodes the text format of the types lib/pq leaves undecoded.
+// Array types are named after their element type with a leading underscore, e.g. _INT4.
+// Types from extensions, such as hstore, have no name.
+func (p *Postgres) ResolveType(dbType string, value []byte) (any, error) {
+	if elementType, ok := strings.CutPrefix(dbType, "_"); ok {
+		return parseArray(elementType, value)
+	}
+	return resolveScalar(dbType, value)
+}
+
+func resolveScalar(dbType string, value []byte) (any, error) {
+	switch dbType {
+	case "UUID":
+		return uuid.ParseBytes(value)
+	case "NUMERIC", "DECIMAL":
+		return result.Decimal(value), nil
+	case "JSON", "JSONB":
+		return json.RawMessage(bytes.Clone(value)), nil
+	case "INTERVAL":
+		return parseInterval(string(value))
+	case "INET":
+		if bytes.IndexByte(value, '/') >= 0 {
+			return netip.ParsePrefix(string(value))
+		}
+		return netip.ParseAddr(string(value))
+	case "CIDR":
+		return netip.ParsePrefix(string(value))
+	case "BYTEA":
+		// lib/pq has decoded it, bytes starting with \x are data
+		return bytes.Clone(value), nil
+	case "HSTORE":
+		return parseHstore(value)
+	case "":
+		if looksLikeHstore(value) {
+			if hstore, err := parseHstore(value); err == nil {
+				return hstore, nil
+			}
+		}
+		return string(value), nil
+	// lib/pq decodes the following itself, they are only seen as array elements.
+	case "INT2", "INT4", "INT8":
+		return strconv.ParseInt(string(value), 10, 64)
+	case "FLOAT4", "FLOAT8":
+		return strconv.ParseFloat(string(value), 64)
+	case "BOOL":
+		return string(value) == "t", nil
+	case "TIMESTAMP", "TIMESTAMPTZ", "DATE":
+		return parseTimestamp(string(value))
+	default:
+		return string(value), nil
+	}
+}
+
+// resolveElement decodes an element of an array literal, which unlike a column is left in its
+// text form by lib/pq.
+func resolveElement(elementType string, value []byte) (any, error) {
+	if elementType == "BYTEA" {
+		return parseBytea(value)
+	}
+	return resolveScalar(elementType, value)
+}
+
+// parseBytea decodes the text form of bytea, copying values in the escape format as they are.
+func parseBytea(value []byte) ([]byte, error) {
+	if encoded, ok := bytes.CutPrefix(value, []byte(`\x`)); ok {
+		decoded := make([]byte, hex.DecodedLen(len(encoded)))
+		_, err := hex.Decode(decoded, encoded)
+		return decoded, err
+	}
+	return bytes.Clone(value), nil
+}
+
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported timestamp %q", value)
+}
+
+// parseArray decodes an array literal such as {1,NULL,"a \"b\""} into []any,
+// nested arrays become nested slices. Elements are decoded as elementType.
+func parseArray(elementType string, value []byte) (any, error) {
+	text := string(value)
+	// Arrays with bounds other than the default are prefixed with them, e.g. [0:1]={1,2}.
+	if strings.HasPrefix(text, "[") {
+		_, after, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed array %q", text)
+		}
+		text = after
+	}
+	delimiter := byte(',')
+	if elementType == "BOX" {
+		delimiter = ';'
+	}
+	parser := arrayParser{text: text, delimiter: delimiter, elementType: elementType}
+	array, err := parser.array()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos != len(parser.text) {
+		return nil, fmt.Errorf("malformed array %q", text)
+	}
+	return array, nil
+}
+
+type arrayParser struct {
+	text        string
+	pos         int
+	delimiter   byte
+	elementType string
+}
+
+func (p *arrayParser) array() ([]any, error) {
+	if p.pos >= len(p.text) || p.text[p.pos] != '{' {
+		return nil, fmt.Errorf("malformed array %q", p.text)
+	}
+	p.pos++
+	elements := []any{}
+	if p.pos < len(p.text) && p.text[p.pos] == '}' {
+		p.pos++
+		return elements, nil
+	}
+	for {
+		element, err := p.element()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+
+		if p.pos >= len(p.text) {
+			return nil, fmt.Errorf("malformed array %q", p.text)
+		}
+		switch p.text[p.pos] {
+		case p.delimiter:
+			p.pos++
+		case '}':
+			p.pos++
+			return elements, nil
+		default:
+			return nil, fmt.Errorf("malformed array %q", p.text)
+		}
+	}
+}
+
+func (p *arrayParser) element() (any, error) {
+	if p.pos >= len(p.text) {
+		return nil, fmt.Errorf("malformed array %q", p.text)
+	}
+	switch p.text[p.pos] {
+	case '{':
+		return p.array()
+	case '"':
+		p.pos++
+		var element strings.Builder
+		for p.pos < len(p.text) {
+			c := p.text[p.pos]
+			p.pos++
+			switch c {
+			case '\\':
+				if p.pos < len(p.text) {
+					element.WriteByte(p.text[p.pos])
+					p.pos++
+				}
+			case '"':
+				return resolveElement(p.elementType, []byte(element.String()))
+			default:
+				element.WriteByte(c)
+			}
+		}
+		return nil, fmt.Errorf("malformed array %q", p.text)
+	default:
+		start := p.pos
+		for p.pos < len(p.text) && p.text[p.pos] != p.delimiter && p.text[p.pos] != '}' {
+			p.pos++
+		}
+		element := p.text[start:p.pos]
+		if element == "NULL" {
+			return nil, nil
+		}
+		return resolveElement(p.elementType, []byte(element))
+	}
+}
+
+// parseInterval decodes the postgres IntervalStyle, e.g. "1 year -2 mons 3 days -04:05:06.5".
+func parseInterval(value string) (result.Interval, error) {
+	var interval result.Interval
+	fields := strings.Fields(value)
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if strings.Contains(field, ":") {
+			micros, err := parseClock(field)
+			if err != nil {
+				return result.Interval{}, fmt.Errorf("malformed interval %q: %w", value, err)
+			}
+			interval.Microseconds += micros
+			continue
+		}
+		if i+1 >= len(fields) {
+			return result.Interval{}, fmt.Errorf("malformed interval %q", value)
+		}
+		n, err := strconv.ParseInt(field, 10, 32)
+		if err != nil {
+			return result.Interval{}, fmt.Errorf("malformed interval %q: %w", value, err)
+		}
+		i++
+		switch strings.TrimSuffix(fields[i], "s") {
+		case "year":
+			interval.Months += int32(n) * 12
+		case "mon":
+			interval.Months += int32(n)
+		case "day":
+			interval.Days += int32(n)
+		default:
+			return result.Interval{}, fmt.Errorf("malformed interval %q: unknown unit %q", value, fields[i])
+		}
+	}
+	return interval, nil
+}
+
+// parseClock returns the microseconds of a [-+]HH:MM:SS[.ffffff] time of day, the hours may exceed 24.
+func parseClock(clock string) (int64, error) {
+	sign := int64(1)
+	switch clock[0] {
+	case '-':
+		sign, clock = -1, clock[1:]
+	case '+':
+		clock = clock[1:]
+	}
+	parts := strings.Split(clock, ":")
+	if len(parts) != 3 {
+		return 0, errors.New("expected HH:MM:SS")
+	}
+	hours, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	minutes, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	seconds, fraction, _ := strings.Cut(parts[2], ".")
+	secs, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	var micros int64
+	if fraction != "" {
+		if len(fraction) > 6 {
+			return 0, errors.New("more than microsecond precision")
+		}
+		micros, err = strconv.ParseInt(fraction+strings.Repeat("0", 6-len(fraction)), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return sign * (((hours*60+minutes)*60+secs)*1e6 + micros), nil
+}
+
+func looksLikeHstore(value []byte) bool {
+	return bytes.HasPrefix(value, []byte(`"`)) && bytes.Contains(value, []byte(`"=>`))
+}
+
+// parseHstore decodes "key"=>"value", "other"=>NULL pairs, NULL values are nil.
+func parseHstore(value []byte) (map[string]*string, error) {
+	hstore := map[string]*string{}
+	text := string(value)
+	pos := 0
+	quoted := func() (string, error) {
+		if pos >= len(text) || text[pos] != '"' {
+			return "", fmt.Errorf("malformed hstore %q", text)
+		}
+		pos++
+		var s strings.Builder
+		for pos < len(text) {
+			c := text[pos]
+			pos++
+			switch c {
+			case '\\':
+				if pos < len(text) {
+					s.WriteByte(text[pos])
+					pos++
+				}
+			case '"':
+				return s.String(), nil
+			default:
+				s.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("malformed hstore %q", text)
+	}
+	for pos < len(text) {
+		key, err := quoted()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(text[pos:], "=>") {
+			return nil, fmt.Errorf("malformed hstore %q", text)
+		}
+		pos += len("=>")
+		if strings.HasPrefix(text[pos:], "NULL") {
+			hstore[key] = nil
+			pos += len("NULL")
+		} else {
+			v, err := quoted()
+			if err != nil {
+				return nil, err
+			}
+			hstore[key] = &v
+		}
+		if pos < len(text) {
+			if !strings.HasPrefix(text[pos:], ", ") {
+				return nil, fmt.Errorf("malformed hstore %q", text)
+			}
+			pos += len(", ")
+		}
+	}
+	return hstore, nil
+}
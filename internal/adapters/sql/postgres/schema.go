@@ -0,0 +1,268 @@
+package postgres
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql"
+	"github.com/ctrl-alt-boop/dribble/schema"
+)
+
+var _ sql.SchemaReader = (*Postgres)(nil)
+
+// In PostgreSQL the database name of a request is the schema, the database is fixed by the connection.
+const (
+	schemaTablesQuery = `SELECT table_name FROM information_schema.tables
+WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_type = 'BASE TABLE'
+ORDER BY table_name`
+
+	schemaTableQuery = `SELECT obj_description(format('%I.%I', $1::text, $2::text)::regclass, 'pg_class')`
+
+	// The sequence of serial and identity columns holds their auto increment seed.
+	schemaColumnsQuery = `SELECT c.column_name, c.data_type, c.udt_name, c.is_nullable = 'YES', c.column_default,
+	c.character_maximum_length, c.numeric_precision, c.numeric_scale,
+	a.attgenerated::text, c.generation_expression, c.collation_name,
+	col_description(a.attrelid, a.attnum),
+	(SELECT s.seqstart::text FROM pg_sequence s
+		WHERE s.seqrelid = pg_get_serial_sequence(format('%I.%I', c.table_schema, c.table_name), c.column_name)::regclass)
+FROM information_schema.columns c
+JOIN pg_attribute a ON a.attrelid = format('%I.%I', c.table_schema, c.table_name)::regclass AND a.attname = c.column_name
+WHERE c.table_schema = $1 AND c.table_name = $2
+ORDER BY c.ordinal_position`
+
+	schemaIndexedColumnsQuery = `SELECT a.attname, i.indisprimary, i.indisunique, i.indnatts
+FROM pg_index i
+JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+WHERE i.indrelid = format('%I.%I', $1::text, $2::text)::regclass
+ORDER BY i.indisprimary DESC, array_position(i.indkey::int2[], a.attnum)`
+
+	schemaEnumQuery = `SELECT e.enumlabel FROM pg_type t
+JOIN pg_enum e ON e.enumtypid = t.oid
+WHERE t.typname = $1
+ORDER BY e.enumsortorder`
+)
+
+const (
+	// Only the key columns are listed, not those of INCLUDE. The column of an expression, whose
+	// attnum is 0, is its expression in parentheses.
+	PrefabIndexes = `SELECT ic.relname,
+	CASE WHEN k.attnum = 0 THEN '(' || pg_get_indexdef(i.indexrelid, k.position::int, true) || ')' ELSE a.attname END,
+	i.indisunique, am.amname, i.indisprimary
+FROM pg_index i
+JOIN pg_class t ON t.oid = i.indrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_class ic ON ic.oid = i.indexrelid
+JOIN pg_am am ON am.oid = ic.relam
+JOIN LATERAL unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, position) ON true
+LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND t.relname = $2 AND k.position <= i.indnkeyatts
+ORDER BY ic.relname, k.position`
+
+	PrefabForeignKeys = `SELECT c.conname, a.attname, rt.relname, ra.attname,
+	` + prefabActionUpdate + `, ` + prefabActionDelete + `
+FROM pg_constraint c
+JOIN pg_class t ON t.oid = c.conrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_class rt ON rt.oid = c.confrelid
+JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, refnum, position) ON true
+JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = k.refnum
+WHERE c.contype = 'f' AND n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND t.relname = $2
+ORDER BY c.conname, k.position`
+
+	PrefabConstraints = `SELECT c.conname,
+	CASE c.contype WHEN 'p' THEN 'PRIMARY KEY' WHEN 'u' THEN 'UNIQUE' WHEN 'f' THEN 'FOREIGN KEY' WHEN 'c' THEN 'CHECK' ELSE 'EXCLUDE' END,
+	a.attname,
+	CASE WHEN c.contype = 'c' THEN pg_get_constraintdef(c.oid) END
+FROM pg_constraint c
+JOIN pg_class t ON t.oid = c.conrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+LEFT JOIN LATERAL unnest(c.conkey) WITH ORDINALITY AS k(attnum, position) ON true
+LEFT JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+WHERE c.contype IN ('p', 'u', 'f', 'c', 'x') AND n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND t.relname = $2
+ORDER BY c.conname, k.position`
+
+	PrefabViews = `SELECT viewname, false, definition FROM pg_views
+WHERE schemaname = COALESCE(NULLIF($1, ''), current_schema())
+UNION ALL
+SELECT matviewname, true, definition FROM pg_matviews
+WHERE schemaname = COALESCE(NULLIF($1, ''), current_schema())
+ORDER BY 1`
+
+	// The parameters come from information_schema, which names routines by their name and oid.
+	// Aggregates and window functions have no definition to read and are left out.
+	PrefabRoutines = `SELECT p.oid::text, p.proname,
+	CASE p.prokind WHEN 'p' THEN 'PROCEDURE' ELSE 'FUNCTION' END,
+	CASE WHEN p.prokind <> 'p' THEN pg_get_function_result(p.oid) END,
+	pg_get_functiondef(p.oid),
+	pa.parameter_name, pa.data_type, pa.parameter_mode
+FROM pg_proc p
+JOIN pg_namespace n ON n.oid = p.pronamespace
+LEFT JOIN information_schema.parameters pa
+	ON pa.specific_schema = n.nspname AND pa.specific_name = p.proname || '_' || p.oid
+WHERE n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND p.prokind IN ('f', 'p')
+ORDER BY p.proname, p.oid, pa.ordinal_position`
+
+	// The bits of tgtype are those of TRIGGER_TYPE_* in catalog/pg_trigger.h.
+	PrefabTriggers = `SELECT tg.tgname, t.relname,
+	CASE WHEN tg.tgtype & 2 <> 0 THEN 'BEFORE' WHEN tg.tgtype & 64 <> 0 THEN 'INSTEAD OF' ELSE 'AFTER' END,
+	concat_ws(' OR ',
+		CASE WHEN tg.tgtype & 4 <> 0 THEN 'INSERT' END,
+		CASE WHEN tg.tgtype & 16 <> 0 THEN 'UPDATE' END,
+		CASE WHEN tg.tgtype & 8 <> 0 THEN 'DELETE' END,
+		CASE WHEN tg.tgtype & 32 <> 0 THEN 'TRUNCATE' END),
+	pg_get_triggerdef(tg.oid, true)
+FROM pg_trigger tg
+JOIN pg_class t ON t.oid = tg.tgrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+WHERE NOT tg.tgisinternal AND n.nspname = COALESCE(NULLIF($1, ''), current_schema())
+ORDER BY tg.tgname, t.relname`
+
+	prefabActionUpdate = `CASE c.confupdtype WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END`
+	prefabActionDelete = `CASE c.confdeltype WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END`
+)
+
+// schemaOrCurrent resolves an empty schema name to the current schema of the connection.
+func schemaOrCurrent(ctx context.Context, q sql.Querier, schemaName string) (string, error) {
+	if schemaName != "" {
+		return schemaName, nil
+	}
+	err := q.QueryRowContext(ctx, "SELECT current_schema()").Scan(&schemaName)
+	return schemaName, err
+}
+
+// ReadTableNames implements sql.SchemaReader.
+func (p *Postgres) ReadTableNames(ctx context.Context, q sql.Querier, databaseName string) ([]string, error) {
+	return sql.QueryStrings(ctx, q, schemaTablesQuery, databaseName)
+}
+
+// ReadTableSchema implements sql.SchemaReader.
+func (p *Postgres) ReadTableSchema(ctx context.Context, q sql.Querier, databaseName, tableName string) (*schema.Table, error) {
+	schemaName, err := schemaOrCurrent(ctx, q, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("error reading current schema: %w", err)
+	}
+
+	table := &schema.Table{Name: tableName}
+	var description gosql.NullString
+	if err := q.QueryRowContext(ctx, schemaTableQuery, schemaName, tableName).Scan(&description); err != nil {
+		return nil, fmt.Errorf("error reading table %s: %w", tableName, err)
+	}
+	table.Description = description.String
+
+	if err := p.readFields(ctx, q, schemaName, table); err != nil {
+		return nil, err
+	}
+	if err := p.readIndexedFields(ctx, q, schemaName, table); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func (p *Postgres) readFields(ctx context.Context, q sql.Querier, schemaName string, table *schema.Table) error {
+	rows, err := q.QueryContext(ctx, schemaColumnsQuery, schemaName, table.Name)
+	if err != nil {
+		return fmt.Errorf("error reading columns of %s: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	var enums []*schema.Field
+	for rows.Next() {
+		var (
+			name, dataType, udtName              string
+			nullable                             bool
+			columnDefault, generated, expression gosql.NullString
+			collation, description, seed         gosql.NullString
+			length, precision, scale             gosql.NullInt64
+		)
+		if err := rows.Scan(&name, &dataType, &udtName, &nullable, &columnDefault,
+			&length, &precision, &scale,
+			&generated, &expression, &collation,
+			&description, &seed); err != nil {
+			return fmt.Errorf("error scanning column of %s: %w", table.Name, err)
+		}
+
+		field := &schema.Field{
+			Name:        name,
+			Description: description.String,
+			Properties: schema.FieldProperties{
+				Type:      dataType,
+				Length:    sql.NullIntPointer(length),
+				Precision: sql.NullIntPointer(precision),
+				Scale:     sql.NullIntPointer(scale),
+				Nullable:  nullable,
+				AutoInc:   sql.NullStringPointer(seed),
+				Overrides: schema.Overrides{Collation: collation.String},
+			},
+		}
+		switch dataType {
+		case "USER-DEFINED":
+			field.Properties.Type = udtName
+			enums = append(enums, field)
+		case "ARRAY":
+			field.Properties.Type = udtName
+		}
+		switch generated.String {
+		case "s", "v":
+			field.Properties.Generated = sql.NullStringPointer(expression)
+			field.Properties.Virtual = generated.String == "v"
+		default:
+			// Generated columns have no default, serial ones default to nextval of their sequence.
+			if !field.Properties.IsAutoIncrement() {
+				field.Properties.Default = sql.NullStringPointer(columnDefault)
+			}
+		}
+		table.Fields = append(table.Fields, field)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(table.Fields) == 0 {
+		return fmt.Errorf("%w: %s in schema %s", schema.ErrTableNotFound, table.Name, schemaName)
+	}
+
+	for _, field := range enums {
+		values, err := sql.QueryStrings(ctx, q, schemaEnumQuery, field.Properties.Type)
+		if err != nil {
+			return fmt.Errorf("error reading values of %s: %w", field.Properties.Type, err)
+		}
+		field.Properties.AllowedValues = values
+	}
+	return nil
+}
+
+// readIndexedFields reads the primary key, and marks the fields covered by an index and those unique on their own.
+func (p *Postgres) readIndexedFields(ctx context.Context, q sql.Querier, schemaName string, table *schema.Table) error {
+	rows, err := q.QueryContext(ctx, schemaIndexedColumnsQuery, schemaName, table.Name)
+	if err != nil {
+		return fmt.Errorf("error reading indexes of %s: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var (
+			name            string
+			primary, unique bool
+			columns         int
+		)
+		if err := rows.Scan(&name, &primary, &unique, &columns); err != nil {
+			return fmt.Errorf("error scanning index of %s: %w", table.Name, err)
+		}
+		field := table.Field(name)
+		if field == nil {
+			continue
+		}
+		if primary {
+			keys = append(keys, name)
+		}
+		field.Properties.Index = true
+		field.Properties.Unique = field.Properties.Unique || (unique && columns == 1)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	sql.SetPrimaryKeys(table, keys)
+	return nil
+}
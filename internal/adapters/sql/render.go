@@ -0,0 +1,85 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/ctrl-alt-boop/dribble/sql"
+)
+
+// validator is implemented by operations that can be built in an invalid state.
+type validator interface {
+	Validate() error
+}
+
+// renderRequest converts a database.Request into a query string and arguments.
+// The arguments are bound by the templates, in the placeholder style of the dialect.
+func (b *Base) renderRequest(intent request.Intent) (string, []any, error) {
+	if query, isRaw := intent.Operation.(string); isRaw {
+		return query, intent.Args, nil
+	}
+	if operation, ok := intent.Operation.(validator); ok {
+		if err := operation.Validate(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	queryStringTemplate := b.Self.GetTemplate(intent.Type)
+	if queryStringTemplate == "" {
+		return "", nil, fmt.Errorf("no template found for request type %v in dialect %s", intent.Type, b.Self.Name())
+	}
+
+	renderer := sql.NewRenderer(b.Self)
+	tmpl, err := template.New("query").Funcs(templateFuncs(renderer, b.Self.Name())).Parse(queryStringTemplate)
+	if err != nil {
+		return "", nil, fmt.Errorf("error parsing query template: %w", err)
+	}
+	// Statements embedding a select, like INSERT ... SELECT, render it with {{template "select"}}.
+	if _, err := tmpl.New("select").Parse(strings.TrimSpace(b.Self.GetTemplate(datasource.Read))); err != nil {
+		return "", nil, fmt.Errorf("error parsing select template: %w", err)
+	}
+
+	renderer.SetSubquery(func(query *sql.SelectQuery) (string, error) {
+		var sb strings.Builder
+		err := tmpl.ExecuteTemplate(&sb, "select", query)
+		return strings.TrimSpace(sb.String()), err
+	})
+
+	operation := intent.Operation
+	var sb strings.Builder
+	if err := tmpl.ExecuteTemplate(&sb, "query", operation); err != nil {
+		return "", nil, fmt.Errorf("error executing query template: %w", err)
+	}
+	if err := renderer.Err(); err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(sb.String()), renderer.Args(), nil
+}
+
+// templateFuncs exposes renderer to the query templates,
+// {{unsupported "FEATURE"}} fails the rendering for features the dialect lacks.
+func templateFuncs(renderer *sql.Renderer, dialect string) template.FuncMap {
+	return template.FuncMap{
+		"quote":    renderer.Ident,
+		"bind":     renderer.Bind,
+		"raw":      renderer.Raw,
+		"expr":     renderer.Expr,
+		"value":    renderer.Value,
+		"subquery": renderer.Query,
+		// operator renders a set operation with its keyword in the dialect.
+		"operator": func(keyword sql.SetOperator) string {
+			return renderer.Operator(string(keyword), string(keyword))
+		},
+		"unsupported": func(feature string) (string, error) {
+			return "", Unsupported(feature, dialect)
+		},
+	}
+}
+
+// Unsupported returns an error wrapping the ErrUnsupported of the dribble sql package.
+func Unsupported(feature, dialect string) error {
+	return fmt.Errorf("%w: %s in %s", sql.ErrUnsupported, feature, dialect)
+}
@@ -0,0 +1,472 @@
+package sql_test
+
+import (
+	"errors"
+	"flag"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/mysql"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/postgres"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/sqlite3"
+	"github.com/ctrl-alt-boop/dribble/sql"
+)
+
+type renderer interface {
+	Render(datasource.Request) (datasource.RequestType, string, []any, error)
+}
+
+var dialects = map[string]renderer{
+	"postgres": postgres.New(nil).(*postgres.Postgres),
+	"mysql":    mysql.New(nil).(*mysql.MySQL),
+	"sqlite3":  sqlite3.New(nil).(*sqlite3.SQLite3),
+}
+
+type renderCase struct {
+	name    string
+	request datasource.Request
+	want    map[string]string
+	args    []any
+}
+
+func runRenderCases(t *testing.T, cases []renderCase) {
+	t.Helper()
+	for _, tc := range cases {
+		for dialect, want := range tc.want {
+			t.Run(tc.name+"/"+dialect, func(t *testing.T) {
+				_, query, args, err := dialects[dialect].Render(tc.request)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if query != want {
+					t.Errorf("query mismatch\n got: %s\nwant: %s", query, want)
+				}
+				if !reflect.DeepEqual(args, tc.args) {
+					t.Errorf("args mismatch\n got: %#v\nwant: %#v", args, tc.args)
+				}
+			})
+		}
+	}
+}
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+type goldenCase struct {
+	name    string
+	request datasource.Request
+	args    []any
+}
+
+// runGoldenCases renders every case in every dialect and compares the query
+// with testdata/<name>.<dialect>.golden, rewriting the files when run with -update.
+func runGoldenCases(t *testing.T, cases []goldenCase) {
+	t.Helper()
+	names := slices.Sorted(maps.Keys(dialects))
+	for _, tc := range cases {
+		for _, dialect := range names {
+			t.Run(tc.name+"/"+dialect, func(t *testing.T) {
+				_, query, args, err := dialects[dialect].Render(tc.request)
+				if err != nil {
+					t.Fatal(err)
+				}
+				golden := filepath.Join("testdata", strings.ReplaceAll(tc.name, " ", "_")+"."+dialect+".golden")
+				if *update {
+					if err := os.WriteFile(golden, []byte(query+"\n"), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if query != strings.TrimSuffix(string(want), "\n") {
+					t.Errorf("query mismatch\n got: %s\nwant: %s", query, want)
+				}
+				if !reflect.DeepEqual(args, tc.args) {
+					t.Errorf("args mismatch\n got: %#v\nwant: %#v", args, tc.args)
+				}
+			})
+		}
+	}
+}
+
+func TestRenderSelect(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{
+			name:    "select all",
+			request: sql.SelectAll().From("users").ToRequest(),
+			args:    []any{},
+		},
+		{
+			name:    "select distinct",
+			request: sql.DistinctSelect("id", "name AS username").From("public.users").ToRequest(),
+			args:    []any{},
+		},
+		{
+			name: "select joins",
+			request: sql.Select("users.id", "orders.*").From("users",
+				sql.InnerJoin("orders", "orders.user_id = users.id"),
+				sql.LeftJoin("profiles", "profiles.user_id = users.id"),
+			).ToRequest(),
+			args: []any{},
+		},
+		{
+			name:    "select where",
+			request: sql.Select("id").From("users").Where(sql.Eq("name", "a"), sql.Gt("age", 30)).ToRequest(),
+			args:    []any{"a", 30},
+		},
+		{
+			name: "select expressions",
+			request: sql.SelectAll().From("users").Where(
+				sql.Or(sql.Eq("users.a", 1), sql.Not(sql.Eq("b", 2))),
+				sql.Not(sql.Null("c")),
+				sql.Not(sql.Like("d", "x%")),
+				sql.Not(sql.And(sql.Gt("e", 1), sql.Lt("e", 5))),
+			).ToRequest(),
+			args: []any{1, 2, "x%", 1, 5},
+		},
+		{
+			name: "select operators",
+			request: sql.SelectAll().From("users").Where(
+				sql.In("id", []int{1, 2, 3}),
+				sql.NotIn("role", "admin", "owner"),
+				sql.Between("age", 18, 65),
+				sql.Ge("score", 10),
+				sql.Le("score", 20),
+				sql.IsNotNull("email"),
+				sql.Ilike("name", "a%"),
+				sql.IsDistinctFrom("team", nil),
+				sql.Not(sql.IsNotDistinctFrom("team", "x")),
+			).ToRequest(),
+			args: []any{1, 2, 3, "admin", "owner", 18, 65, 10, 20, "a%", nil, "x"},
+		},
+		{
+			name: "select subqueries",
+			request: sql.Select("id").From("users").Where(
+				sql.In("id", sql.Select("user_id").From("orders").Where(sql.Gt("total", 100))),
+				sql.NotExists(sql.Select("1").From("bans").Where(sql.Eq("bans.user_id", sql.Col("users.id")))),
+				sql.Eq("name", "a"),
+			).ToRequest(),
+			args: []any{100, "a"},
+		},
+		{
+			name: "select with",
+			request: sql.Select("region", "total").From("totals").
+				With("totals", sql.Select("region", "SUM(amount) AS total").From("orders").Where(sql.Gt("amount", 0)).GroupBy("region")).
+				Where(sql.Gt("total", 1000)).
+				ToRequest(),
+			args: []any{0, 1000},
+		},
+		{
+			name: "select with recursive",
+			request: sql.SelectAll().From("subordinates").
+				WithRecursive("subordinates", sql.Select("id", "manager_id").From("employees").Where(sql.Eq("id", 1)).
+					UnionAll(sql.Select("e.id", "e.manager_id").From("employees AS e",
+						sql.InnerJoin("subordinates AS s", "s.id = e.manager_id")))).
+				ToRequest(),
+			args: []any{1},
+		},
+		{
+			name: "select window",
+			request: sql.Select("name", sql.Window("ROW_NUMBER()").PartitionBy("dept").OrderBy("salary", true).As("rank")).
+				From("employees").
+				ToRequest(),
+			args: []any{},
+		},
+		{
+			name: "select union",
+			request: sql.Select("id").From("customers").Where(sql.Eq("active", true)).
+				UnionAll(sql.Select("id").From("suppliers")).
+				Union(sql.Select("id").From("partners").Where(sql.Eq("active", true))).
+				OrderBy("id", false).
+				Limit(10).
+				ToRequest(),
+			args: []any{true, true},
+		},
+		{
+			name: "select intersect except",
+			request: sql.Select("id").From("customers").
+				Intersect(sql.Select("id").From("suppliers")).
+				Except(sql.Select("id").From("banned")).
+				ToRequest(),
+			args: []any{},
+		},
+		{
+			name:    "select injection",
+			request: sql.Select("id").From("users").Where(sql.Eq("name", "'; DROP TABLE users; --")).ToRequest(),
+			args:    []any{"'; DROP TABLE users; --"},
+		},
+		{
+			name: "select grouped",
+			request: sql.Select("age", "COUNT(*) AS n").From("users").
+				GroupBy("age").
+				Having("COUNT(*) > 1").
+				OrderBy("age", true).
+				OrderBy("n", false).
+				ToRequest(),
+			args: []any{},
+		},
+		{
+			name:    "select limit offset",
+			request: sql.SelectAll().From("users").Limit(10).Offset(20).ToRequest(),
+			args:    []any{},
+		},
+		{
+			name:    "select offset",
+			request: sql.SelectAll().From("users").Offset(5).ToRequest(),
+			args:    []any{},
+		},
+		{
+			name:    "select count",
+			request: sql.Count("*", "users").ToRequest(),
+			args:    []any{},
+		},
+		{
+			name:    "select without table",
+			request: sql.Select("current_database()").From("").ToRequest(),
+			args:    []any{},
+		},
+	})
+}
+
+func TestRenderQuantified(t *testing.T) {
+	req := sql.SelectAll().From("products").Where(sql.Any("price", ">", sql.Select("price").From("offers"))).ToRequest()
+	runRenderCases(t, []renderCase{
+		{
+			name:    "any",
+			request: req,
+			want: map[string]string{
+				"postgres": `SELECT *` + "\n" + `FROM "products"` + "\n" + `WHERE "price" > ANY (SELECT "price" FROM "offers")`,
+				"mysql":    "SELECT *\nFROM `products`\nWHERE `price` > ANY (SELECT `price` FROM `offers`)",
+			},
+			args: []any{},
+		},
+	})
+	if _, _, _, err := dialects["sqlite3"].Render(req); !errors.Is(err, sql.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported for ANY on sqlite3, got %v", err)
+	}
+	for _, op := range []string{"> 0 OR 1=1 OR price >", "LIKE", ""} {
+		injected := sql.SelectAll().From("products").Where(sql.All("price", op, sql.Select("price").From("offers"))).ToRequest()
+		if _, _, _, err := dialects["postgres"].Render(injected); !errors.Is(err, sql.ErrInvalidOperator) {
+			t.Errorf("expected ErrInvalidOperator for %q, got %v", op, err)
+		}
+	}
+}
+
+func TestRenderSetOperationUnsupported(t *testing.T) {
+	req := sql.Select("id").From("customers").IntersectAll(sql.Select("id").From("suppliers")).ToRequest()
+	for _, dialect := range []string{"mysql", "sqlite3"} {
+		if _, _, _, err := dialects[dialect].Render(req); !errors.Is(err, sql.ErrUnsupported) {
+			t.Errorf("expected ErrUnsupported for INTERSECT ALL on %s, got %v", dialect, err)
+		}
+	}
+	if _, _, _, err := dialects["postgres"].Render(req); err != nil {
+		t.Errorf("postgres: %v", err)
+	}
+}
+
+func TestRenderSelectUnsupported(t *testing.T) {
+	req := sql.SelectAll().From("users", sql.FullJoin("orders", "orders.user_id = users.id")).ToRequest()
+	if _, _, _, err := dialects["mysql"].Render(req); !errors.Is(err, sql.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported for FULL JOIN on mysql, got %v", err)
+	}
+	if _, _, _, err := dialects["postgres"].Render(req); err != nil {
+		t.Errorf("unexpected error for FULL JOIN on postgres: %v", err)
+	}
+}
+
+type user struct {
+	ID      int    `db:"id"`
+	Name    string `db:"name"`
+	Ignored string `db:"-"`
+}
+
+func TestRenderInsert(t *testing.T) {
+	runRenderCases(t, []renderCase{
+		{
+			name:    "multi row",
+			request: sql.Insert("users").Columns("id", "name").Values(1, "a").Values(2, "b").ToRequest(),
+			want: map[string]string{
+				"postgres": `INSERT INTO "users" ("id", "name")` + "\n" + `VALUES ($1, $2), ($3, $4)`,
+				"mysql":    "INSERT INTO `users` (`id`, `name`)\nVALUES (?, ?), (?, ?)",
+				"sqlite3":  `INSERT INTO "users" ("id", "name")` + "\n" + `VALUES (?, ?), (?, ?)`,
+			},
+			args: []any{1, "a", 2, "b"},
+		},
+		{
+			name:    "from select",
+			request: sql.Insert("archive").Columns("id", "name").FromSelect(sql.Select("id", "name").From("users")).ToRequest(),
+			want: map[string]string{
+				"postgres": `INSERT INTO "archive" ("id", "name")` + "\n" + `SELECT "id", "name"` + "\n" + `FROM "users"`,
+			},
+			args: []any{},
+		},
+		{
+			name:    "structs",
+			request: sql.Insert("users").Structs(user{ID: 1, Name: "a"}, &user{ID: 2, Name: "b"}).ToRequest(),
+			want: map[string]string{
+				"postgres": `INSERT INTO "users" ("id", "name")` + "\n" + `VALUES ($1, $2), ($3, $4)`,
+			},
+			args: []any{1, "a", 2, "b"},
+		},
+		{
+			name:    "maps",
+			request: sql.Insert("users").Maps(map[string]any{"name": "a", "id": 1}).ToRequest(),
+			want: map[string]string{
+				"sqlite3": `INSERT INTO "users" ("id", "name")` + "\n" + `VALUES (?, ?)`,
+			},
+			args: []any{1, "a"},
+		},
+	})
+}
+
+func TestRenderUpsert(t *testing.T) {
+	runGoldenCases(t, []goldenCase{
+		{
+			name: "insert on conflict do update",
+			request: sql.Insert("users").Columns("id", "name", "age").Values(1, "a", 30).
+				OnConflict("id").DoUpdate().
+				ToRequest(),
+			args: []any{1, "a", 30},
+		},
+		{
+			name: "insert on conflict do nothing",
+			request: sql.Insert("users").Columns("id", "name").Values(1, "a").
+				OnConflict("id").DoNothing().
+				ToRequest(),
+			args: []any{1, "a"},
+		},
+		{
+			name: "insert select on conflict",
+			request: sql.Insert("users").Columns("id", "name").
+				FromSelect(sql.Select("id", "name").From("staging")).
+				OnConflict("id").DoUpdate("name").
+				ToRequest(),
+			args: []any{},
+		},
+	})
+}
+
+func TestRenderReturning(t *testing.T) {
+	cases := []struct {
+		name    string
+		request datasource.Request
+		want    string
+		args    []any
+	}{
+		{
+			name:    "insert",
+			request: sql.Insert("users").Columns("name").Values("a").Returning("id").ToRequest(),
+			want:    `INSERT INTO "users" ("name")` + "\n" + `VALUES ($1)` + "\n" + `RETURNING "id"`,
+			args:    []any{"a"},
+		},
+		{
+			name:    "update",
+			request: sql.Update("users").Set("age", 31).Where(sql.Eq("id", 1)).Returning("id", "age").ToRequest(),
+			want:    `UPDATE "users"` + "\n" + `SET "age" = $1` + "\n" + `WHERE "id" = $2` + "\n" + `RETURNING "id", "age"`,
+			args:    []any{31, 1},
+		},
+		{
+			name:    "delete",
+			request: sql.DeleteFrom("users").Where(sql.Eq("id", 1)).Returning("*").ToRequest(),
+			want:    `DELETE FROM "users"` + "\n" + `WHERE "id" = $1` + "\n" + `RETURNING *`,
+			args:    []any{1},
+		},
+	}
+	for _, tc := range cases {
+		runRenderCases(t, []renderCase{{
+			name:    tc.name,
+			request: tc.request,
+			want:    map[string]string{"postgres": tc.want, "sqlite3": strings.ReplaceAll(strings.ReplaceAll(tc.want, "$1", "?"), "$2", "?")},
+			args:    tc.args,
+		}})
+		if _, _, _, err := dialects["mysql"].Render(tc.request); !errors.Is(err, sql.ErrUnsupported) {
+			t.Errorf("%s: expected ErrUnsupported for RETURNING on mysql, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestRenderInsertErrors(t *testing.T) {
+	requests := map[string]datasource.Request{
+		"no values":          sql.Insert("users").Columns("id").ToRequest(),
+		"column mismatch":    sql.Insert("users").Columns("id", "name").Values(1).ToRequest(),
+		"row mismatch":       sql.Insert("users").Values(1, "a").Values(2).ToRequest(),
+		"columns after rows": sql.Insert("users").Values(1, "a").Columns("id").ToRequest(),
+		"unknown map key":    sql.Insert("users").Columns("id").Maps(map[string]any{"id": 1, "name": "a"}).ToRequest(),
+		"extra map key":      sql.Insert("users").Maps(map[string]any{"id": 1}, map[string]any{"id": 2, "name": "b"}).ToRequest(),
+		"missing map key":    sql.Insert("users").Maps(map[string]any{"id": 1, "name": "a"}, map[string]any{"id": 2}).ToRequest(),
+		"not a struct":       sql.Insert("users").Structs(1).ToRequest(),
+		"no conflict target": sql.Insert("users").Columns("id").Values(1).OnConflict().DoUpdate().ToRequest(),
+		"nothing to update":  sql.Insert("users").Columns("id").Values(1).OnConflict("id").DoUpdate().ToRequest(),
+	}
+	for name, req := range requests {
+		if _, _, _, err := dialects["postgres"].Render(req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRenderUpdateDelete(t *testing.T) {
+	runRenderCases(t, []renderCase{
+		{
+			name:    "update",
+			request: sql.Update("users").Set("name", "b").Set("age", 3).Where(sql.Eq("id", 1)).ToRequest(),
+			want: map[string]string{
+				"postgres": `UPDATE "users"` + "\n" + `SET "name" = $1, "age" = $2` + "\n" + `WHERE "id" = $3`,
+				"mysql":    "UPDATE `users`\nSET `name` = ?, `age` = ?\nWHERE `id` = ?",
+			},
+			args: []any{"b", 3, 1},
+		},
+		{
+			name: "update expressions",
+			request: sql.Update("counters").Set("n", sql.Raw("n + ?", 1)).Set("updated", sql.Raw("NOW()")).
+				Set("copy", sql.Col("n")).Where(sql.Eq("id", 1)).ToRequest(),
+			want: map[string]string{
+				"postgres": `UPDATE "counters"` + "\n" + `SET "n" = n + $1, "updated" = NOW(), "copy" = "n"` + "\n" + `WHERE "id" = $2`,
+				"mysql":    "UPDATE `counters`\nSET `n` = n + ?, `updated` = NOW(), `copy` = `n`\nWHERE `id` = ?",
+			},
+			args: []any{1, 1},
+		},
+		{
+			name:    "update all",
+			request: sql.Update("users").Set("age", 3).All().ToRequest(),
+			want: map[string]string{
+				"sqlite3": `UPDATE "users"` + "\n" + `SET "age" = ?`,
+			},
+			args: []any{3},
+		},
+		{
+			name:    "delete",
+			request: sql.DeleteFrom("users").Where(sql.Eq("id", 1)).ToRequest(),
+			want: map[string]string{
+				"postgres": `DELETE FROM "users"` + "\n" + `WHERE "id" = $1`,
+			},
+			args: []any{1},
+		},
+		{
+			name:    "delete all",
+			request: sql.DeleteFrom("users").All().ToRequest(),
+			want: map[string]string{
+				"mysql": "DELETE FROM `users`",
+			},
+			args: []any{},
+		},
+	})
+
+	unsafe := map[string]datasource.Request{
+		"update without where": sql.Update("users").Set("age", 3).ToRequest(),
+		"update without set":   sql.Update("users").All().ToRequest(),
+		"delete without where": sql.DeleteFrom("users").ToRequest(),
+	}
+	for name, req := range unsafe {
+		if _, _, _, err := dialects["sqlite3"].Render(req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package sql_test
+
+import (
+	"encoding/json"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/mysql"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/postgres"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/sqlite3"
+	"github.com/ctrl-alt-boop/dribble/result"
+	"github.com/google/uuid"
+)
+
+type resolveCase struct {
+	name    string
+	dbType  string
+	value   string
+	want    any
+	wantErr bool
+}
+
+func runResolveCases(t *testing.T, resolver datasource.SQLAdapter, cases []resolveCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolver.ResolveType(tc.dbType, []byte(tc.value))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("resolved mismatch\n got: %#v\nwant: %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestResolvePostgres(t *testing.T) {
+	runResolveCases(t, postgres.New(nil).(*postgres.Postgres), []resolveCase{
+		{name: "uuid", dbType: "UUID", value: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11",
+			want: uuid.MustParse("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11")},
+		{name: "numeric", dbType: "NUMERIC", value: "12345678901234567890.000000000001",
+			want: result.Decimal("12345678901234567890.000000000001")},
+		{name: "numeric nan", dbType: "NUMERIC", value: "NaN", want: result.Decimal("NaN")},
+		{name: "json", dbType: "JSON", value: `{"a": [1, 2]}`, want: json.RawMessage(`{"a": [1, 2]}`)},
+		{name: "jsonb", dbType: "JSONB", value: `{"a": [1, 2]}`, want: json.RawMessage(`{"a": [1, 2]}`)},
+		{name: "interval", dbType: "INTERVAL", value: "1 year 2 mons 3 days 04:05:06.5",
+			want: result.Interval{Months: 14, Days: 3, Microseconds: 14706500000}},
+		{name: "interval negative", dbType: "INTERVAL", value: "-1 days +02:00:00",
+			want: result.Interval{Days: -1, Microseconds: 7200000000}},
+		{name: "interval time only", dbType: "INTERVAL", value: "-00:00:00.000001",
+			want: result.Interval{Microseconds: -1}},
+		{name: "interval zero", dbType: "INTERVAL", value: "00:00:00", want: result.Interval{}},
+		{name: "interval verbose", dbType: "INTERVAL", value: "@ 1 hour", wantErr: true},
+		{name: "inet address", dbType: "INET", value: "192.168.0.1", want: netip.MustParseAddr("192.168.0.1")},
+		{name: "inet with mask", dbType: "INET", value: "192.168.0.1/24", want: netip.MustParsePrefix("192.168.0.1/24")},
+		{name: "inet ipv6", dbType: "INET", value: "::1", want: netip.MustParseAddr("::1")},
+		{name: "cidr", dbType: "CIDR", value: "10.0.0.0/8", want: netip.MustParsePrefix("10.0.0.0/8")},
+		{name: "bytea decoded by the driver", dbType: "BYTEA", value: "\x00\xff", want: []byte{0x00, 0xff}},
+		{name: "bytea starting with a backslash and x", dbType: "BYTEA", value: `\x00ff`, want: []byte(`\x00ff`)},
+		{name: "bytea not hex", dbType: "BYTEA", value: `\xzz`, want: []byte(`\xzz`)},
+		{name: "hstore", dbType: "HSTORE", value: `"a"=>"1", "b"=>NULL, "c \"d\""=>"e, f"`,
+			want: map[string]*string{"a": ptr("1"), "b": nil, `c "d"`: ptr("e, f")}},
+		{name: "hstore empty", dbType: "HSTORE", value: "", want: map[string]*string{}},
+		{name: "hstore unnamed", dbType: "", value: `"a"=>"1"`, want: map[string]*string{"a": ptr("1")}},
+		{name: "unnamed", dbType: "", value: "POINT(1 2)", want: "POINT(1 2)"},
+		{name: "int array", dbType: "_INT4", value: "{1,NULL,-3}", want: []any{int64(1), nil, int64(-3)}},
+		{name: "empty array", dbType: "_INT8", value: "{}", want: []any{}},
+		{name: "text array", dbType: "_TEXT", value: `{a,"b,c","d \"e\"","NULL",NULL}`,
+			want: []any{"a", "b,c", `d "e"`, "NULL", nil}},
+		{name: "nested array", dbType: "_FLOAT8", value: "{{1.5,2},{3,4}}",
+			want: []any{[]any{1.5, 2.0}, []any{3.0, 4.0}}},
+		{name: "array with bounds", dbType: "_INT2", value: "[0:1]={7,8}", want: []any{int64(7), int64(8)}},
+		{name: "bool array", dbType: "_BOOL", value: "{t,f}", want: []any{true, false}},
+		{name: "numeric array", dbType: "_NUMERIC", value: "{1.10,NaN}",
+			want: []any{result.Decimal("1.10"), result.Decimal("NaN")}},
+		{name: "bytea array", dbType: "_BYTEA", value: `{"\\x01ff",NULL}`, want: []any{[]byte{0x01, 0xff}, nil}},
+		{name: "bytea array bad hex", dbType: "_BYTEA", value: `{"\\xzz"}`, wantErr: true},
+		{name: "timestamptz array", dbType: "_TIMESTAMPTZ", value: `{"2024-01-02 03:04:05.5+01"}`,
+			want: []any{time.Date(2024, 1, 2, 3, 4, 5, 5e8, time.FixedZone("", 3600))}},
+		{name: "uuid array", dbType: "_UUID", value: "{a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11}",
+			want: []any{uuid.MustParse("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11")}},
+		{name: "unterminated array", dbType: "_INT4", value: "{1,2", wantErr: true},
+		{name: "trailing text", dbType: "_INT4", value: "{1}x", wantErr: true},
+	})
+}
+
+func TestResolveMySQL(t *testing.T) {
+	runResolveCases(t, mysql.New(nil).(*mysql.MySQL), []resolveCase{
+		{name: "int", dbType: "INT", value: "-42", want: int64(-42)},
+		{name: "unsigned bigint", dbType: "UNSIGNED BIGINT", value: "18446744073709551615", want: uint64(18446744073709551615)},
+		{name: "year", dbType: "YEAR", value: "2024", want: int64(2024)},
+		{name: "double", dbType: "DOUBLE", value: "1.5e-3", want: 1.5e-3},
+		{name: "decimal", dbType: "DECIMAL", value: "0.10000000000000000001", want: result.Decimal("0.10000000000000000001")},
+		{name: "json", dbType: "JSON", value: `{"a": 1}`, want: json.RawMessage(`{"a": 1}`)},
+		{name: "bit", dbType: "BIT", value: "\x01\x02", want: uint64(0x0102)},
+		{name: "bit too wide", dbType: "BIT", value: "123456789", wantErr: true},
+		{name: "datetime", dbType: "DATETIME", value: "2024-01-02 03:04:05.123456",
+			want: time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)},
+		{name: "timestamp", dbType: "TIMESTAMP", value: "2024-01-02 03:04:05", want: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{name: "date", dbType: "DATE", value: "2024-01-02", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "zero datetime", dbType: "DATETIME", value: "0000-00-00 00:00:00", want: time.Time{}},
+		{name: "time", dbType: "TIME", value: "-838:59:59.5",
+			want: -(838*time.Hour + 59*time.Minute + 59*time.Second + 500*time.Millisecond)},
+		{name: "set", dbType: "SET", value: "a,b", want: []string{"a", "b"}},
+		{name: "empty set", dbType: "SET", value: "", want: []string{}},
+		{name: "enum", dbType: "ENUM", value: "small", want: "small"},
+		{name: "varchar", dbType: "VARCHAR", value: "text", want: "text"},
+		{name: "blob", dbType: "BLOB", value: "\x00\x01", want: []byte{0x00, 0x01}},
+		{name: "malformed int", dbType: "BIGINT", value: "x", wantErr: true},
+	})
+}
+
+func TestResolveSQLite3(t *testing.T) {
+	runResolveCases(t, sqlite3.New(nil).(*sqlite3.SQLite3), []resolveCase{
+		{name: "integer", dbType: "INTEGER", value: "42", want: int64(42)},
+		{name: "bigint not integer", dbType: "BIGINT", value: "4x", want: []byte("4x")},
+		{name: "varchar", dbType: "varchar(20)", value: "text", want: "text"},
+		{name: "text invalid utf8", dbType: "TEXT", value: "\xff", want: []byte{0xff}},
+		{name: "blob", dbType: "BLOB", value: "42", want: []byte("42")},
+		{name: "no declared type", dbType: "", value: "42", want: []byte("42")},
+		{name: "real", dbType: "DOUBLE PRECISION", value: "1.5", want: 1.5},
+		{name: "numeric integer", dbType: "DECIMAL(10,5)", value: "3", want: int64(3)},
+		{name: "numeric real", dbType: "NUMERIC", value: "3.25", want: 3.25},
+		{name: "numeric text", dbType: "BOOLEAN", value: "yes", want: "yes"},
+		{name: "floating point is integer", dbType: "FLOATING POINT", value: "2", want: int64(2)},
+	})
+}
+
+func TestAffinityOf(t *testing.T) {
+	for declared, want := range map[string]sqlite3.Affinity{
+		"INT":               sqlite3.AffinityInteger,
+		"unsigned big int":  sqlite3.AffinityInteger,
+		"CHARINT":           sqlite3.AffinityInteger,
+		"NATIVE CHARACTER":  sqlite3.AffinityText,
+		"CLOB":              sqlite3.AffinityText,
+		"BLOB":              sqlite3.AffinityBlob,
+		"":                  sqlite3.AffinityBlob,
+		"REAL":              sqlite3.AffinityReal,
+		"FLOAT":             sqlite3.AffinityReal,
+		"DOUBLE":            sqlite3.AffinityReal,
+		"DATETIME":          sqlite3.AffinityNumeric,
+		"STRING":            sqlite3.AffinityNumeric,
+		"DECIMAL(10,5)":     sqlite3.AffinityNumeric,
+		"FLOATING POINT":    sqlite3.AffinityInteger,
+		"VARYING CHARACTER": sqlite3.AffinityText,
+	} {
+		if got := sqlite3.AffinityOf(declared); got != want {
+			t.Errorf("AffinityOf(%q) = %d, want %d", declared, got, want)
+		}
+	}
+}
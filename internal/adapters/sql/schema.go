@@ -0,0 +1,452 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/ctrl-alt-boop/dribble/schema"
+)
+
+// SchemaReader is implemented by adapters that read the structure of a database into the schema model.
+// An empty databaseName is the database, or schema, the connection is using.
+type SchemaReader interface {
+	ReadTableNames(ctx context.Context, q Querier, databaseName string) ([]string, error)
+	ReadTableSchema(ctx context.Context, q Querier, databaseName, tableName string) (*schema.Table, error)
+}
+
+// TriggerReader is implemented by adapters that cannot select the timing and event of their triggers,
+// and read the triggers themselves instead of with the rows of their GetPrefab query.
+type TriggerReader interface {
+	ReadTriggers(ctx context.Context, q Querier, databaseName string) (schema.Triggers, error)
+}
+
+// readSchema answers the schema prefabs with typed results, from the SchemaReader of the adapter
+// or the rows of its GetPrefab query. handled is false for any other request.
+func (b *Base) readSchema(ctx context.Context, q Querier, req datasource.Request) (body any, handled bool, err error) {
+	switch r := req.(type) {
+	case request.ReadIndexes, *request.ReadIndexes:
+		body, err = b.queryPrefab(ctx, q, req, scanIndexes)
+		return body, true, err
+	case request.ReadForeignKeys, *request.ReadForeignKeys:
+		body, err = b.queryPrefab(ctx, q, req, scanForeignKeys)
+		return body, true, err
+	case request.ReadConstraints, *request.ReadConstraints:
+		body, err = b.queryPrefab(ctx, q, req, scanConstraints)
+		return body, true, err
+	case request.ReadViews, *request.ReadViews:
+		body, err = b.queryPrefab(ctx, q, req, scanViews)
+		return body, true, err
+	case request.ReadRoutines, *request.ReadRoutines:
+		body, err = b.queryPrefab(ctx, q, req, scanRoutines)
+		return body, true, err
+	case request.ReadTriggers:
+		body, err = b.readTriggers(ctx, q, req, r.DatabaseName)
+		return body, true, err
+	case *request.ReadTriggers:
+		body, err = b.readTriggers(ctx, q, req, r.DatabaseName)
+		return body, true, err
+	}
+
+	reader, ok := b.Self.(SchemaReader)
+	if !ok {
+		return nil, false, nil
+	}
+	switch r := req.(type) {
+	case request.ReadDatabaseSchema:
+		body, err = b.readDatabaseSchema(ctx, reader, q, r.DatabaseName)
+	case *request.ReadDatabaseSchema:
+		body, err = b.readDatabaseSchema(ctx, reader, q, r.DatabaseName)
+	case request.ReadTableSchema:
+		body, err = b.readTableSchema(ctx, reader, q, r.DatabaseName, r.TableName)
+	case *request.ReadTableSchema:
+		body, err = b.readTableSchema(ctx, reader, q, r.DatabaseName, r.TableName)
+	case request.ReadColumnSchema:
+		body, err = readColumnSchema(ctx, reader, q, r.DatabaseName, r.TableName, r.ColumnName)
+	case *request.ReadColumnSchema:
+		body, err = readColumnSchema(ctx, reader, q, r.DatabaseName, r.TableName, r.ColumnName)
+	default:
+		return nil, false, nil
+	}
+	return body, true, err
+}
+
+func (b *Base) readDatabaseSchema(ctx context.Context, reader SchemaReader, q Querier, databaseName string) (*schema.Database, error) {
+	tableNames, err := reader.ReadTableNames(ctx, q, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tables: %w", err)
+	}
+	database := &schema.Database{
+		Name:   databaseName,
+		Tables: make(schema.Tables, 0, len(tableNames)),
+	}
+	for _, tableName := range tableNames {
+		table, err := b.readTableSchema(ctx, reader, q, databaseName, tableName)
+		if err != nil {
+			return nil, err
+		}
+		database.Tables = append(database.Tables, table)
+	}
+
+	views, err := b.queryPrefab(ctx, q, request.NewReadViews(databaseName), scanViews)
+	if err != nil {
+		return nil, fmt.Errorf("error reading views: %w", err)
+	}
+	routines, err := b.queryPrefab(ctx, q, request.NewReadRoutines(databaseName), scanRoutines)
+	if err != nil {
+		return nil, fmt.Errorf("error reading routines: %w", err)
+	}
+	triggers, err := b.readTriggers(ctx, q, request.NewReadTriggers(databaseName), databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("error reading triggers: %w", err)
+	}
+	database.Views = views.(schema.Views)
+	database.Procedures = routines.(schema.Functions)
+	database.Triggers = triggers
+	return database, nil
+}
+
+// readTableSchema reads the table with reader, along with its indexes, foreign keys and constraints.
+func (b *Base) readTableSchema(ctx context.Context, reader SchemaReader, q Querier, databaseName, tableName string) (*schema.Table, error) {
+	table, err := reader.ReadTableSchema(ctx, q, databaseName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := b.queryPrefab(ctx, q, request.NewReadIndexes(databaseName, tableName), scanIndexes)
+	if err != nil {
+		return nil, fmt.Errorf("error reading indexes of %s: %w", tableName, err)
+	}
+	foreignKeys, err := b.queryPrefab(ctx, q, request.NewReadForeignKeys(databaseName, tableName), scanForeignKeys)
+	if err != nil {
+		return nil, fmt.Errorf("error reading foreign keys of %s: %w", tableName, err)
+	}
+	constraints, err := b.queryPrefab(ctx, q, request.NewReadConstraints(databaseName, tableName), scanConstraints)
+	if err != nil {
+		return nil, fmt.Errorf("error reading constraints of %s: %w", tableName, err)
+	}
+	table.Properties.Indexes = indexes.([]*schema.Index)
+	table.Properties.ForeignKeys = foreignKeys.([]*schema.ForeignKey)
+	table.Properties.Constraints = constraints.([]*schema.Constraint)
+	return table, nil
+}
+
+// readTriggers reads the triggers with the TriggerReader of the adapter, or the rows of the GetPrefab query of req.
+func (b *Base) readTriggers(ctx context.Context, q Querier, req datasource.Request, databaseName string) (schema.Triggers, error) {
+	if reader, ok := b.Self.(TriggerReader); ok {
+		return reader.ReadTriggers(ctx, q, databaseName)
+	}
+	triggers, err := b.queryPrefab(ctx, q, req, scanTriggers)
+	if err != nil {
+		return nil, err
+	}
+	return triggers.(schema.Triggers), nil
+}
+
+func readColumnSchema(ctx context.Context, reader SchemaReader, q Querier, databaseName, tableName, columnName string) (*schema.Field, error) {
+	table, err := reader.ReadTableSchema(ctx, q, databaseName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	field := table.Field(columnName)
+	if field == nil {
+		return nil, fmt.Errorf("column %s not found in table %s", columnName, tableName)
+	}
+	return field, nil
+}
+
+// queryPrefab runs the GetPrefab query of req and converts its rows with scan.
+func (b *Base) queryPrefab(ctx context.Context, q Querier, req datasource.Request, scan func(*sql.Rows) (any, error)) (any, error) {
+	query, args, err := b.Self.GetPrefab(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render prefab request: %w", err)
+	}
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing query: %w", err)
+	}
+	defer rows.Close()
+
+	body, err := scan(rows)
+	if err != nil {
+		return nil, err
+	}
+	return body, rows.Err()
+}
+
+// The GetPrefab queries of ReadIndexes, ReadForeignKeys and ReadConstraints return a row per
+// column of each object, ordered by object and the position of the column in it:
+//
+//	ReadIndexes:     name, column, unique, method, primary
+//	ReadForeignKeys: name, column, referenced table, referenced column, on update, on delete
+//	ReadConstraints: name, type, column, definition
+//
+// The column of a constraint is NULL for checks on the whole table.
+//
+// Those of ReadViews, ReadRoutines and ReadTriggers return a row per object, and for routines
+// a row per parameter, ordered by routine and the position of the parameter:
+//
+//	ReadViews:    name, materialized, definition
+//	ReadRoutines: specific name, name, kind, returns, definition, parameter name, parameter type, parameter mode
+//	ReadTriggers: name, table, timing, event, definition
+//
+// The specific name tells overloaded routines apart. The parameter columns are NULL for a
+// routine without parameters, and returns is NULL for procedures. An adapter that cannot
+// select the timing and event of its triggers implements TriggerReader.
+
+func scanIndexes(rows *sql.Rows) (any, error) {
+	var indexes []*schema.Index
+	for rows.Next() {
+		var (
+			name, column, method string
+			unique, primary      bool
+		)
+		if err := rows.Scan(&name, &column, &unique, &method, &primary); err != nil {
+			return nil, fmt.Errorf("error scanning index: %w", err)
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+			indexes = append(indexes, &schema.Index{
+				Name:    name,
+				Type:    method,
+				Unique:  unique,
+				Primary: primary,
+			})
+		}
+		index := indexes[len(indexes)-1]
+		index.Fields = append(index.Fields, column)
+	}
+	return indexes, nil
+}
+
+func scanForeignKeys(rows *sql.Rows) (any, error) {
+	var foreignKeys []*schema.ForeignKey
+	for rows.Next() {
+		var name, column, toTable, toColumn, onUpdate, onDelete string
+		if err := rows.Scan(&name, &column, &toTable, &toColumn, &onUpdate, &onDelete); err != nil {
+			return nil, fmt.Errorf("error scanning foreign key: %w", err)
+		}
+		if len(foreignKeys) == 0 || foreignKeys[len(foreignKeys)-1].Name != name {
+			foreignKeys = append(foreignKeys, &schema.ForeignKey{
+				Name:     name,
+				ToTable:  toTable,
+				OnUpdate: onUpdate,
+				OnDelete: onDelete,
+			})
+		}
+		foreignKey := foreignKeys[len(foreignKeys)-1]
+		foreignKey.Fields = append(foreignKey.Fields, column)
+		foreignKey.ToFields = append(foreignKey.ToFields, toColumn)
+	}
+	return foreignKeys, nil
+}
+
+func scanConstraints(rows *sql.Rows) (any, error) {
+	var constraints []*schema.Constraint
+	for rows.Next() {
+		var name, constraintType string
+		var column, definition sql.NullString
+		if err := rows.Scan(&name, &constraintType, &column, &definition); err != nil {
+			return nil, fmt.Errorf("error scanning constraint: %w", err)
+		}
+		if len(constraints) == 0 || constraints[len(constraints)-1].Name != name {
+			constraints = append(constraints, &schema.Constraint{
+				Name:       name,
+				Type:       constraintType,
+				Definition: definition.String,
+			})
+		}
+		if column.Valid {
+			constraint := constraints[len(constraints)-1]
+			constraint.Fields = append(constraint.Fields, column.String)
+		}
+	}
+	return constraints, nil
+}
+
+func scanViews(rows *sql.Rows) (any, error) {
+	var views schema.Views
+	for rows.Next() {
+		var name string
+		var materialized bool
+		var definition sql.NullString
+		if err := rows.Scan(&name, &materialized, &definition); err != nil {
+			return nil, fmt.Errorf("error scanning view: %w", err)
+		}
+		views = append(views, &schema.View{
+			Name: name,
+			Properties: schema.ViewProperties{
+				Definition:   strings.TrimSpace(definition.String),
+				Materialized: materialized,
+			},
+		})
+	}
+	return views, nil
+}
+
+func scanRoutines(rows *sql.Rows) (any, error) {
+	var routines schema.Functions
+	var lastSpecificName string
+	for rows.Next() {
+		var specificName, name, kind string
+		var returns, definition, parameterName, parameterType, parameterMode sql.NullString
+		if err := rows.Scan(&specificName, &name, &kind, &returns, &definition,
+			&parameterName, &parameterType, &parameterMode); err != nil {
+			return nil, fmt.Errorf("error scanning routine: %w", err)
+		}
+		if len(routines) == 0 || lastSpecificName != specificName {
+			routines = append(routines, &schema.Function{
+				Name: name,
+				Properties: schema.FunctionProperties{
+					Definition: strings.TrimSpace(definition.String),
+					Type:       kind,
+					Returns:    NullStringPointer(returns),
+				},
+			})
+			lastSpecificName = specificName
+		}
+		if parameterType.Valid {
+			routine := routines[len(routines)-1]
+			routine.Properties.Parameters = append(routine.Properties.Parameters, schema.Parameter{
+				Name:      parameterName.String,
+				Type:      parameterType.String,
+				Direction: parameterMode.String,
+			})
+		}
+	}
+	return routines, nil
+}
+
+func scanTriggers(rows *sql.Rows) (any, error) {
+	var triggers schema.Triggers
+	for rows.Next() {
+		var name, table, timing, event string
+		var definition sql.NullString
+		if err := rows.Scan(&name, &table, &timing, &event, &definition); err != nil {
+			return nil, fmt.Errorf("error scanning trigger: %w", err)
+		}
+		triggers = append(triggers, &schema.Trigger{
+			Name: name,
+			Properties: schema.TriggerProperties{
+				Table:      table,
+				Timing:     timing,
+				Event:      event,
+				Definition: strings.TrimSpace(definition.String),
+			},
+		})
+	}
+	return triggers, nil
+}
+
+// QueryStrings runs query and returns its first column, NULLs excluded.
+func QueryStrings(ctx context.Context, q Querier, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value sql.NullString
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		if value.Valid {
+			values = append(values, value.String)
+		}
+	}
+	return values, rows.Err()
+}
+
+// SetPrimaryKeys marks the fields of table named by keys, in key order, as its primary key.
+func SetPrimaryKeys(table *schema.Table, keys []string) {
+	table.Properties.PrimaryKeys = keys
+	for _, key := range keys {
+		if field := table.Field(key); field != nil {
+			field.Properties.PrimaryKey = true
+		}
+	}
+}
+
+var typeModifiersPattern = regexp.MustCompile(`^([^(]*)\(([^)]*)\)(.*)$`)
+
+// ParseColumnType splits a declared type like "varchar(255)", "decimal(10, 2)" or
+// "enum('a','b')" into the type, with any trailing attributes like unsigned, and its modifiers.
+func ParseColumnType(declared string, properties *schema.FieldProperties) {
+	match := typeModifiersPattern.FindStringSubmatch(strings.TrimSpace(declared))
+	if match == nil {
+		properties.Type = strings.TrimSpace(declared)
+		return
+	}
+	properties.Type = strings.TrimSpace(match[1] + match[3])
+
+	modifiers := match[2]
+	switch strings.ToLower(strings.TrimSpace(match[1])) {
+	case "enum", "set":
+		properties.Type = strings.TrimSpace(match[1])
+		properties.AllowedValues = parseQuotedList(modifiers)
+		return
+	case "decimal", "numeric", "float", "double", "real":
+		precision, scale, hasScale := strings.Cut(modifiers, ",")
+		properties.Precision = parseInt(precision)
+		if hasScale {
+			properties.Scale = parseInt(scale)
+		}
+	default:
+		properties.Length = parseInt(modifiers)
+	}
+}
+
+// parseQuotedList parses a list of single-quoted values, in which a doubled quote stands for one.
+func parseQuotedList(list string) []string {
+	var values []string
+	var sb strings.Builder
+	inQuote := false
+	for i := 0; i < len(list); i++ {
+		char := list[i]
+		switch {
+		case char == '\'' && inQuote && i+1 < len(list) && list[i+1] == '\'':
+			sb.WriteByte('\'')
+			i++
+		case char == '\'' && inQuote:
+			values = append(values, sb.String())
+			sb.Reset()
+			inQuote = false
+		case char == '\'':
+			inQuote = true
+		case inQuote:
+			sb.WriteByte(char)
+		}
+	}
+	return values
+}
+
+func parseInt(value string) *int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+// NullStringPointer returns nil for a NULL, and a pointer to the string otherwise.
+func NullStringPointer(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
+
+// NullIntPointer returns nil for a NULL, and a pointer to the int otherwise.
+func NullIntPointer(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	n := int(value.Int64)
+	return &n
+}
@@ -0,0 +1,30 @@
+package sql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql"
+	"github.com/ctrl-alt-boop/dribble/schema"
+)
+
+func TestParseColumnType(t *testing.T) {
+	n := func(n int) *int { return &n }
+	cases := map[string]schema.FieldProperties{
+		"integer":                  {Type: "integer"},
+		"varchar(255)":             {Type: "varchar", Length: n(255)},
+		"int(10) unsigned":         {Type: "int unsigned", Length: n(10)},
+		"decimal(10, 2)":           {Type: "decimal", Precision: n(10), Scale: n(2)},
+		"NUMERIC(5)":               {Type: "NUMERIC", Precision: n(5)},
+		"enum('a','b''c')":         {Type: "enum", AllowedValues: []string{"a", "b'c"}},
+		"set('read', 'write')":     {Type: "set", AllowedValues: []string{"read", "write"}},
+		"timestamp with time zone": {Type: "timestamp with time zone"},
+	}
+	for declared, want := range cases {
+		var got schema.FieldProperties
+		sql.ParseColumnType(declared, &got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", declared, got, want)
+		}
+	}
+}
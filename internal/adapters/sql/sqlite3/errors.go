@@ -0,0 +1,42 @@
+package sqlite3
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/mattn/go-sqlite3"
+)
+
+var _ datasource.ErrorClassifier = (*SQLite3)(nil)
+
+// ClassifyError implements datasource.ErrorClassifier by the result code of the error.
+// SQLite reports syntax errors and missing tables alike as SQLITE_ERROR, told apart by the message.
+func (s *SQLite3) ClassifyError(err error) datasource.ErrorCategory {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return datasource.ErrorUnknown
+	}
+	if sqliteErr.ExtendedCode == sqlite3.ErrBusySnapshot {
+		// The snapshot of the transaction is stale, only starting it over helps
+		return datasource.ErrorConflict
+	}
+	switch sqliteErr.Code {
+	case sqlite3.ErrBusy, sqlite3.ErrLocked:
+		return datasource.ErrorTransient
+	case sqlite3.ErrConstraint:
+		return datasource.ErrorConstraint
+	case sqlite3.ErrPerm, sqlite3.ErrAuth, sqlite3.ErrReadonly:
+		return datasource.ErrorPermission
+	case sqlite3.ErrCantOpen:
+		return datasource.ErrorConnection
+	case sqlite3.ErrError:
+		message := sqliteErr.Error()
+		if strings.Contains(message, "syntax error") ||
+			strings.Contains(message, "no such table") ||
+			strings.Contains(message, "no such column") {
+			return datasource.ErrorSyntax
+		}
+	}
+	return datasource.ErrorUnknown
+}
@@ -0,0 +1,41 @@
+package sqlite3
+
+import (
+	"context"
+	gosql "database/sql"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql"
+	"github.com/ctrl-alt-boop/dribble/request"
+)
+
+var _ sql.RowInserter = (*SQLite3)(nil)
+
+// InsertRows implements sql.RowInserter with a prepared statement executed per row,
+// in one transaction so the rows are written to disk once.
+func (s *SQLite3) InsertRows(ctx context.Context, q sql.Querier, req request.InsertRowsRequest) (int64, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(req.Columns)), ", ")
+	statement := sql.InsertStatement(s, req.Table, req.Columns) + "(" + placeholders + ")"
+
+	var inserted int64
+	err := sql.WithinTx(ctx, q, func(tx *gosql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, row := range req.Rows {
+			result, err := stmt.ExecContext(ctx, row...)
+			if err != nil {
+				return err
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			inserted += affected
+		}
+		return nil
+	})
+	return inserted, err
+}
@@ -0,0 +1,68 @@
+package sqlite3
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Affinity is the type affinity SQLite gives a column from its declared type.
+type Affinity int
+
+const (
+	AffinityBlob Affinity = iota
+	AffinityInteger
+	AffinityText
+	AffinityReal
+	AffinityNumeric
+)
+
+// AffinityOf applies the rules of https://www.sqlite.org/datatype3.html#determination_of_column_affinity
+// in their order, so "CHARINT" has integer affinity and "FLOATING POINT" has integer affinity too.
+func AffinityOf(declaredType string) Affinity {
+	declared := strings.ToUpper(declaredType)
+	switch {
+	case strings.Contains(declared, "INT"):
+		return AffinityInteger
+	case strings.Contains(declared, "CHAR"), strings.Contains(declared, "CLOB"), strings.Contains(declared, "TEXT"):
+		return AffinityText
+	case strings.Contains(declared, "BLOB"), declared == "":
+		return AffinityBlob
+	case strings.Contains(declared, "REAL"), strings.Contains(declared, "FLOA"), strings.Contains(declared, "DOUB"):
+		return AffinityReal
+	default:
+		return AffinityNumeric
+	}
+}
+
+// ResolveType decodes values go-sqlite3 returns as bytes, which it does for every value with
+// blob storage, by the affinity of the declared type of the column. Bytes that do not decode
+// to the type of the affinity are returned as they are.
+func (s *SQLite3) ResolveType(dbType string, value []byte) (any, error) {
+	switch AffinityOf(dbType) {
+	case AffinityInteger:
+		if i, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+			return i, nil
+		}
+	case AffinityText:
+		if utf8.Valid(value) {
+			return string(value), nil
+		}
+	case AffinityReal:
+		if f, err := strconv.ParseFloat(string(value), 64); err == nil {
+			return f, nil
+		}
+	case AffinityNumeric:
+		if i, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(string(value), 64); err == nil {
+			return f, nil
+		}
+		if utf8.Valid(value) {
+			return string(value), nil
+		}
+	}
+	return bytes.Clone(value), nil
+}
@@ -0,0 +1,449 @@
+package sqlite3
+
+import (
+	"context"
+	gosql "database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql"
+	"github.com/ctrl-alt-boop/dribble/schema"
+)
+
+var (
+	_ sql.SchemaReader  = (*SQLite3)(nil)
+	_ sql.TriggerReader = (*SQLite3)(nil)
+)
+
+// Values of the hidden column of PRAGMA table_xinfo.
+const (
+	hiddenVirtualGenerated = 2
+	hiddenStoredGenerated  = 3
+)
+
+// The prefabs take the table and the database as ?1 and ?2. SQLite does not name foreign keys, they are
+// named fk_<id> after their id in PRAGMA foreign_key_list, and keeps no record of CHECK constraints.
+const (
+	PrefabIndexes = `SELECT il.name, ii.name, il."unique", 'btree', il.origin = 'pk'
+FROM pragma_index_list(?1, ?2) AS il
+JOIN pragma_index_info(il.name, ?2) AS ii
+WHERE ii.name IS NOT NULL
+ORDER BY il.name, ii.seqno`
+
+	// A foreign key to the primary key of the referenced table may leave out its columns.
+	PrefabForeignKeys = `SELECT 'fk_' || fk.id, fk."from", fk."table",
+	COALESCE(fk."to", (SELECT ti.name FROM pragma_table_info(fk."table", ?2) AS ti WHERE ti.pk = fk.seq + 1)),
+	fk.on_update, fk.on_delete
+FROM pragma_foreign_key_list(?1, ?2) AS fk
+ORDER BY fk.id, fk.seq`
+
+	PrefabConstraints = `SELECT name, type, column, definition FROM (
+	SELECT ?1 || '_pkey' AS name, 'PRIMARY KEY' AS type, ti.name AS column, NULL AS definition, ti.pk AS position
+	FROM pragma_table_info(?1, ?2) AS ti WHERE ti.pk > 0
+	UNION ALL
+	SELECT il.name, 'UNIQUE', ii.name, NULL, ii.seqno
+	FROM pragma_index_list(?1, ?2) AS il JOIN pragma_index_info(il.name, ?2) AS ii WHERE il.origin = 'u'
+	UNION ALL
+	SELECT 'fk_' || fk.id, 'FOREIGN KEY', fk."from", NULL, fk.seq
+	FROM pragma_foreign_key_list(?1, ?2) AS fk
+)
+ORDER BY name, position`
+
+	// The format verb of the view and trigger prefabs is the quoted database.
+	PrefabViewsFormat = `SELECT name, false, sql FROM %s.sqlite_master WHERE type = 'view' ORDER BY name`
+
+	// SQLite has no stored routines.
+	PrefabRoutines = `SELECT NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL LIMIT 0`
+
+	// Triggers are read by ReadTriggers, which parses their timing and event from the statement.
+	PrefabTriggersFormat = `SELECT name, tbl_name, sql FROM %s.sqlite_master WHERE type = 'trigger' ORDER BY name`
+)
+
+// databaseOrMain defaults to the main database of the connection.
+func databaseOrMain(databaseName string) string {
+	if databaseName == "" {
+		return "main"
+	}
+	return databaseName
+}
+
+// ReadTableNames implements sql.SchemaReader.
+func (s *SQLite3) ReadTableNames(ctx context.Context, q sql.Querier, databaseName string) ([]string, error) {
+	query := fmt.Sprintf(`SELECT name FROM %s.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite\_%%' ESCAPE '\' ORDER BY name`,
+		s.Quote(databaseOrMain(databaseName)))
+	return sql.QueryStrings(ctx, q, query)
+}
+
+// ReadTableSchema implements sql.SchemaReader.
+// The columns come from PRAGMA table_xinfo, which unlike table_info includes generated ones,
+// and what the pragmas leave out, like generation expressions, from the CREATE TABLE statement.
+func (s *SQLite3) ReadTableSchema(ctx context.Context, q sql.Querier, databaseName, tableName string) (*schema.Table, error) {
+	database := databaseOrMain(databaseName)
+	rows, err := q.QueryContext(ctx, "SELECT cid, name, type, \"notnull\", dflt_value, pk, hidden FROM pragma_table_xinfo(?, ?)", tableName, database)
+	if err != nil {
+		return nil, fmt.Errorf("error reading columns of %s: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	table := &schema.Table{Name: tableName}
+	keyFields := map[int]string{}
+	for rows.Next() {
+		var (
+			cid, notNull, pk, hidden int
+			name, declared           string
+			defaultValue             gosql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &declared, &notNull, &defaultValue, &pk, &hidden); err != nil {
+			return nil, fmt.Errorf("error scanning column of %s: %w", tableName, err)
+		}
+		field := &schema.Field{Name: name}
+		sql.ParseColumnType(declared, &field.Properties)
+		field.Properties.Nullable = notNull == 0 && pk == 0
+		field.Properties.Default = sql.NullStringPointer(defaultValue)
+		field.Properties.Virtual = hidden == hiddenVirtualGenerated
+		if hidden == hiddenVirtualGenerated || hidden == hiddenStoredGenerated {
+			expression := ""
+			field.Properties.Generated = &expression
+		}
+		if pk > 0 {
+			keyFields[pk] = name
+		}
+		table.Fields = append(table.Fields, field)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(table.Fields) == 0 {
+		return nil, fmt.Errorf("%w: %s", schema.ErrTableNotFound, tableName)
+	}
+	keys := make([]string, 0, len(keyFields))
+	for position := 1; position <= len(keyFields); position++ {
+		keys = append(keys, keyFields[position])
+	}
+	sql.SetPrimaryKeys(table, keys)
+
+	// A single INTEGER PRIMARY KEY is an alias of the rowid, and assigned automatically.
+	if len(keys) == 1 {
+		if key := table.Field(keys[0]); strings.EqualFold(key.Properties.Type, "INTEGER") {
+			seed := "1"
+			key.Properties.AutoInc = &seed
+		}
+	}
+
+	if err := s.readDefinitions(ctx, q, database, table); err != nil {
+		return nil, err
+	}
+	if err := s.readIndexedFields(ctx, q, database, table); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// ReadTriggers implements sql.TriggerReader.
+func (s *SQLite3) ReadTriggers(ctx context.Context, q sql.Querier, databaseName string) (schema.Triggers, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(PrefabTriggersFormat, s.Quote(databaseOrMain(databaseName))))
+	if err != nil {
+		return nil, fmt.Errorf("error executing query: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers schema.Triggers
+	for rows.Next() {
+		var name, table, definition string
+		if err := rows.Scan(&name, &table, &definition); err != nil {
+			return nil, fmt.Errorf("error scanning trigger: %w", err)
+		}
+		timing, event, err := triggerHead(definition)
+		if err != nil {
+			return nil, fmt.Errorf("error reading trigger %s: %w", name, err)
+		}
+		triggers = append(triggers, &schema.Trigger{
+			Name: name,
+			Properties: schema.TriggerProperties{
+				Table:      table,
+				Timing:     timing,
+				Event:      event,
+				Definition: strings.TrimSpace(definition),
+			},
+		})
+	}
+	return triggers, rows.Err()
+}
+
+var (
+	generatedPattern = regexp.MustCompile(`(?i)\bAS\s*\(`)
+	collatePattern   = regexp.MustCompile(`(?i)\bCOLLATE\s+("[^"]+"|\w+)`)
+)
+
+// readDefinitions fills in the generation expressions and collations from the CREATE TABLE statement.
+func (s *SQLite3) readDefinitions(ctx context.Context, q sql.Querier, database string, table *schema.Table) error {
+	var createSQL gosql.NullString
+	query := fmt.Sprintf("SELECT sql FROM %s.sqlite_master WHERE type = 'table' AND name = ?", s.Quote(database))
+	if err := q.QueryRowContext(ctx, query, table.Name).Scan(&createSQL); err != nil {
+		return fmt.Errorf("error reading definition of %s: %w", table.Name, err)
+	}
+	for name, definition := range columnDefinitions(createSQL.String) {
+		field := table.Field(name)
+		if field == nil {
+			continue
+		}
+		if field.Properties.Generated != nil {
+			if loc := generatedPattern.FindStringIndex(definition); loc != nil {
+				expression := balanced(definition[loc[1]-1:])
+				field.Properties.Generated = &expression
+			}
+		}
+		if match := collatePattern.FindStringSubmatch(definition); match != nil {
+			field.Properties.Overrides.Collation = strings.Trim(match[1], `"`)
+		}
+	}
+	return nil
+}
+
+// readIndexedFields marks the fields covered by an index, and those unique on their own.
+func (s *SQLite3) readIndexedFields(ctx context.Context, q sql.Querier, database string, table *schema.Table) error {
+	rows, err := q.QueryContext(ctx, "SELECT name, \"unique\" FROM pragma_index_list(?, ?)", table.Name, database)
+	if err != nil {
+		return fmt.Errorf("error reading indexes of %s: %w", table.Name, err)
+	}
+	type index struct {
+		name   string
+		unique bool
+	}
+	var indexes []index
+	for rows.Next() {
+		var name string
+		var unique int
+		if err := rows.Scan(&name, &unique); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning index of %s: %w", table.Name, err)
+		}
+		indexes = append(indexes, index{name: name, unique: unique == 1})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		columns, err := sql.QueryStrings(ctx, q, "SELECT name FROM pragma_index_info(?, ?)", index.name, database)
+		if err != nil {
+			return fmt.Errorf("error reading index %s: %w", index.name, err)
+		}
+		for _, column := range columns {
+			if field := table.Field(column); field != nil {
+				field.Properties.Index = true
+				field.Properties.Unique = field.Properties.Unique || (index.unique && len(columns) == 1)
+			}
+		}
+	}
+	return nil
+}
+
+// columnDefinitions splits the body of a CREATE TABLE statement into the definitions of its columns, by name.
+// Table constraints, like PRIMARY KEY (a, b), are keyed by their first word and never match a column.
+func columnDefinitions(createSQL string) map[string]string {
+	definitions := map[string]string{}
+	start := strings.Index(createSQL, "(")
+	if start < 0 {
+		return definitions
+	}
+	for _, definition := range splitTopLevel(createSQL[start+1:]) {
+		definition = strings.TrimSpace(definition)
+		name, rest := cutIdentifier(definition)
+		if name != "" {
+			definitions[name] = rest
+		}
+	}
+	return definitions
+}
+
+// splitTopLevel splits on the commas outside of parentheses and quotes, up to the closing parenthesis of the body.
+func splitTopLevel(body string) []string {
+	var parts []string
+	depth, last := 0, 0
+	var quote byte
+	for i := 0; i < len(body); i++ {
+		char := body[i]
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"' || char == '`':
+			quote = char
+		case char == '[':
+			quote = ']'
+		case char == '(':
+			depth++
+		case char == ')':
+			if depth == 0 {
+				return append(parts, body[last:i])
+			}
+			depth--
+		case char == ',' && depth == 0:
+			parts = append(parts, body[last:i])
+			last = i + 1
+		}
+	}
+	return append(parts, body[last:])
+}
+
+// cutIdentifier returns the leading, possibly quoted, identifier of definition and what follows it.
+func cutIdentifier(definition string) (string, string) {
+	if definition == "" {
+		return "", ""
+	}
+	closing := map[byte]byte{'"': '"', '`': '`', '[': ']', '\'': '\''}[definition[0]]
+	if closing != 0 {
+		end := strings.IndexByte(definition[1:], closing)
+		if end < 0 {
+			return "", ""
+		}
+		return definition[1 : end+1], definition[end+2:]
+	}
+	end := strings.IndexFunc(definition, unicode.IsSpace)
+	if end < 0 {
+		return definition, ""
+	}
+	return definition[:end], definition[end:]
+}
+
+// balanced returns the contents of the parentheses opening expression.
+func balanced(expression string) string {
+	depth := 0
+	for i := 0; i < len(expression); i++ {
+		switch expression[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return strings.TrimSpace(expression[1:i])
+			}
+		}
+	}
+	return strings.TrimSpace(strings.TrimPrefix(expression, "("))
+}
+
+var errNotCreateTrigger = errors.New("not a CREATE TRIGGER statement")
+
+// triggerHead returns the timing and event of a CREATE TRIGGER statement, from the tokens heading it:
+//
+//	CREATE [TEMP|TEMPORARY] TRIGGER [IF NOT EXISTS] [schema.]name [BEFORE|AFTER|INSTEAD OF] {DELETE|INSERT|UPDATE}
+//
+// A trigger without a timing runs BEFORE.
+func triggerHead(createSQL string) (timing, event string, err error) {
+	tokens := headTokens(createSQL)
+	next := func() string {
+		if len(tokens) == 0 {
+			return ""
+		}
+		token := tokens[0]
+		tokens = tokens[1:]
+		return token
+	}
+	accept := func(keywords ...string) bool {
+		if len(tokens) < len(keywords) {
+			return false
+		}
+		for i, keyword := range keywords {
+			if !strings.EqualFold(tokens[i], keyword) {
+				return false
+			}
+		}
+		tokens = tokens[len(keywords):]
+		return true
+	}
+
+	if !accept("CREATE") {
+		return "", "", errNotCreateTrigger
+	}
+	if !accept("TEMP") {
+		accept("TEMPORARY")
+	}
+	if !accept("TRIGGER") {
+		return "", "", errNotCreateTrigger
+	}
+	accept("IF", "NOT", "EXISTS")
+	if next() == "" {
+		return "", "", errors.New("trigger has no name")
+	}
+	if accept(".") && next() == "" {
+		return "", "", errors.New("trigger has no name")
+	}
+
+	switch {
+	case accept("BEFORE"):
+		timing = "BEFORE"
+	case accept("AFTER"):
+		timing = "AFTER"
+	case accept("INSTEAD", "OF"):
+		timing = "INSTEAD OF"
+	default:
+		timing = "BEFORE"
+	}
+	switch event = strings.ToUpper(next()); event {
+	case "DELETE", "INSERT", "UPDATE":
+		return timing, event, nil
+	}
+	return "", "", fmt.Errorf("unknown trigger event %q", event)
+}
+
+// headTokens splits the head of statement, up to its twelfth token, into keywords, identifiers
+// unquoted, and dots, leaving out whitespace and comments.
+func headTokens(statement string) []string {
+	var tokens []string
+	for i := 0; i < len(statement) && len(tokens) < 12; {
+		char := statement[i]
+		switch {
+		case unicode.IsSpace(rune(char)):
+			i++
+		case strings.HasPrefix(statement[i:], "--"):
+			end := strings.IndexByte(statement[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case strings.HasPrefix(statement[i:], "/*"):
+			end := strings.Index(statement[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case char == '"' || char == '`' || char == '[' || char == '\'':
+			closing := char
+			if char == '[' {
+				closing = ']'
+			}
+			var identifier strings.Builder
+			for i++; i < len(statement); i++ {
+				if statement[i] == closing {
+					// A quote is escaped by doubling it
+					if closing != ']' && i+1 < len(statement) && statement[i+1] == closing {
+						i++
+					} else {
+						break
+					}
+				}
+				identifier.WriteByte(statement[i])
+			}
+			tokens = append(tokens, identifier.String())
+			i++
+		case char == '.':
+			tokens = append(tokens, ".")
+			i++
+		default:
+			end := i + 1
+			for end < len(statement) && !unicode.IsSpace(rune(statement[end])) && !strings.ContainsRune(".\"`['(;", rune(statement[end])) {
+				end++
+			}
+			tokens = append(tokens, statement[i:end])
+			i = end
+		}
+	}
+	return tokens
+}
@@ -2,6 +2,8 @@ package sqlite3
 
 import (
 	"fmt"
+	"math"
+	"strings"
 	"text/template"
 
 	_ "embed"
@@ -103,39 +105,56 @@ func (s *SQLite3) GetPrefab(r datasource.Request) (string, []any, error) {
 		return PrefabColumns, []any{r.TableName}, nil
 	case *request.ReadColumnNames:
 		return PrefabColumns, []any{r.TableName}, nil
+	case request.ReadIndexes:
+		return PrefabIndexes, []any{r.TableName, databaseOrMain(r.DatabaseName)}, nil
+	case *request.ReadIndexes:
+		return PrefabIndexes, []any{r.TableName, databaseOrMain(r.DatabaseName)}, nil
+	case request.ReadForeignKeys:
+		return PrefabForeignKeys, []any{r.TableName, databaseOrMain(r.DatabaseName)}, nil
+	case *request.ReadForeignKeys:
+		return PrefabForeignKeys, []any{r.TableName, databaseOrMain(r.DatabaseName)}, nil
+	case request.ReadConstraints:
+		return PrefabConstraints, []any{r.TableName, databaseOrMain(r.DatabaseName)}, nil
+	case *request.ReadConstraints:
+		return PrefabConstraints, []any{r.TableName, databaseOrMain(r.DatabaseName)}, nil
+	case request.ReadViews:
+		return fmt.Sprintf(PrefabViewsFormat, s.Quote(databaseOrMain(r.DatabaseName))), nil, nil
+	case *request.ReadViews:
+		return fmt.Sprintf(PrefabViewsFormat, s.Quote(databaseOrMain(r.DatabaseName))), nil, nil
+	case request.ReadRoutines, *request.ReadRoutines:
+		return PrefabRoutines, nil, nil
+	case request.ReadTriggers:
+		return fmt.Sprintf(PrefabTriggersFormat, s.Quote(databaseOrMain(r.DatabaseName))), nil, nil
+	case *request.ReadTriggers:
+		return fmt.Sprintf(PrefabTriggersFormat, s.Quote(databaseOrMain(r.DatabaseName))), nil, nil
 	default:
 		return "", nil, fmt.Errorf("unknown prefab request: %T", r)
 	}
 }
 
-func (s *SQLite3) ResolveType(dbType string, value []byte) (any, error) {
-	// |go        | sqlite3           |
-	// |----------|-------------------|
-	// |nil       | null              |
-	// |int       | integer           |
-	// |int64     | integer           |
-	// |float64   | float             |
-	// |bool      | integer           |
-	// |[]byte    | blob              |
-	// |string    | text              |
-	// |time.Time | timestamp/datetime|
-	return string(value), nil
-}
-
 //go:embed templates/select.tmpl
 var selectQueryTemplate string
 
+//go:embed templates/insert.tmpl
+var insertQueryTemplate string
+
+//go:embed templates/update.tmpl
+var updateQueryTemplate string
+
+//go:embed templates/delete.tmpl
+var deleteQueryTemplate string
+
 // GetTemplate implements database.Dialect.
 func (s *SQLite3) GetTemplate(queryType datasource.RequestType) string {
 	switch queryType {
 	case datasource.Read:
 		return selectQueryTemplate
 	case datasource.Create:
-		return "" // DefaultSQLInsertTemplate
+		return insertQueryTemplate
 	case datasource.Update:
-		return "" // DefaultSQLUpdateTemplate
+		return updateQueryTemplate
 	case datasource.Delete:
-		return "" // DefaultSQLDeleteTemplate
+		return deleteQueryTemplate
 	default:
 		return ""
 	}
@@ -148,7 +167,7 @@ func (s *SQLite3) IncreamentPlaceholder() string {
 
 // Quote implements database.Dialect.
 func (s *SQLite3) Quote(value string) string {
-	return fmt.Sprintf(`"%s"`, value)
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(value, `"`, `""`))
 }
 
 // QuoteRune implements database.Dialect.
@@ -161,6 +180,25 @@ func (s *SQLite3) RenderCurrentTimestamp() string {
 	return "NOW()"
 }
 
+// RenderOperator implements the OperatorDialect of the dribble sql package.
+func (s *SQLite3) RenderOperator(name string) (string, error) {
+	keyword, _ := sql.ParseKeyword(name)
+	switch keyword {
+	case sql.Ilike:
+		return "LOWER(%[1]s) LIKE LOWER(%[2]s)", nil
+	case sql.NotIlike:
+		return "LOWER(%[1]s) NOT LIKE LOWER(%[2]s)", nil
+	case sql.IsDistinctFrom:
+		return "%[1]s IS NOT %[2]s", nil
+	case sql.IsNotDistinctFrom:
+		return "%[1]s IS %[2]s", nil
+	case sql.Any, sql.All, sql.IntersectAll, sql.ExceptAll:
+		return "", sql.Unsupported(name, s.Name())
+	default:
+		return "", nil
+	}
+}
+
 // RenderPlaceholder implements database.Dialect.
 func (s *SQLite3) RenderPlaceholder(index int) string {
 	return "?"
@@ -171,7 +209,25 @@ func (s *SQLite3) RenderTypeCast() string { // FIXME
 	return "::"
 }
 
+var literals = sql.Literals{
+	True:  "1",
+	False: "0",
+	Bytes: func(hexDigits string) string { return "X'" + hexDigits + "'" },
+	NonFinite: func(value float64) string {
+		switch {
+		case math.IsNaN(value):
+			return "NULL" // SQLite stores NaN as NULL
+		case value > 0:
+			return "9e999"
+		default:
+			return "-9e999"
+		}
+	},
+	// The layout go-sqlite3 writes times in.
+	TimeLayout: "2006-01-02 15:04:05.999999999-07:00",
+}
+
 // RenderValue implements database.Dialect.
 func (s *SQLite3) RenderValue(value any) string {
-	return fmt.Sprintf("%v", value)
+	return sql.RenderLiteral(value, literals)
 }
@@ -0,0 +1,70 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+)
+
+var _ datasource.Tx = (*Tx)(nil)
+
+// Tx runs requests on an open *sql.Tx, rendering them with the dialect of the Base that began it.
+// For dialects implementing BackendCanceller it holds the connection of the transaction, released
+// when it ends.
+type Tx struct {
+	base *Base
+	tx   *sql.Tx
+
+	conn      *sql.Conn
+	canceller BackendCanceller
+	backendID int64
+}
+
+// Request implements datasource.Tx.
+func (t *Tx) Request(ctx context.Context, req datasource.Request) (any, error) {
+	if t.canceller != nil {
+		defer t.base.cancelOnDone(ctx, t.canceller, t.backendID)()
+	}
+	return t.base.execute(ctx, t.tx, req)
+}
+
+// Commit implements datasource.Tx.
+func (t *Tx) Commit() error {
+	return t.release(t.tx.Commit())
+}
+
+// Rollback implements datasource.Tx.
+func (t *Tx) Rollback() error {
+	return t.release(t.tx.Rollback())
+}
+
+func (t *Tx) release(err error) error {
+	if t.conn == nil {
+		return err
+	}
+	return errors.Join(err, t.conn.Close())
+}
+
+// txOptions maps opts onto database/sql, the postgres and mysql drivers honour
+// both fields while sqlite3 is always serializable and ignores ReadOnly.
+func txOptions(opts datasource.TxOptions) *sql.TxOptions {
+	var isolation sql.IsolationLevel
+	switch opts.Isolation {
+	case datasource.IsolationReadUncommitted:
+		isolation = sql.LevelReadUncommitted
+	case datasource.IsolationReadCommitted:
+		isolation = sql.LevelReadCommitted
+	case datasource.IsolationRepeatableRead:
+		isolation = sql.LevelRepeatableRead
+	case datasource.IsolationSerializable:
+		isolation = sql.LevelSerializable
+	default:
+		isolation = sql.LevelDefault
+	}
+	return &sql.TxOptions{
+		Isolation: isolation,
+		ReadOnly:  opts.ReadOnly,
+	}
+}
@@ -0,0 +1,202 @@
+package migrate
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/ctrl-alt-boop/dribble/result"
+	"github.com/ctrl-alt-boop/dribble/sql"
+	"github.com/ctrl-alt-boop/dribble/target"
+)
+
+const lockPollInterval = 100 * time.Millisecond
+
+// lock takes the migration lock of the table, waiting up to the lock timeout for another runner
+// to release it, and returns the function releasing it.
+//
+// PostgreSQL and MySQL locks belong to a connection, they are taken in a transaction that only
+// holds on to one: a transaction level advisory lock on PostgreSQL, released when it is rolled
+// back, and GET_LOCK on MySQL. The transaction keeps its connection for the whole run, so the
+// migrations need a second one, a pool of a single connection fails with ErrSingleConnection.
+//
+// SQLite3 has no such locks, a runner holds the lock by inserting the single row of a lock table
+// and releases it by deleting the row. A runner that crashed leaves the row behind, see
+// WithStaleLockAfter and ForceUnlock.
+func (m *Migrator) lock(ctx context.Context) (func(context.Context) error, error) {
+	switch m.dialect {
+	case datasource.PostgreSQL:
+		return m.lockInTransaction(ctx,
+			"SELECT pg_try_advisory_xact_lock($1) AS locked, 0 AS unused", "", m.lockKey())
+	case datasource.MySQL:
+		return m.lockInTransaction(ctx,
+			"SELECT GET_LOCK(?, 0) AS locked, 0 AS unused",
+			"SELECT RELEASE_LOCK(?) AS released, 0 AS unused", m.table)
+	case datasource.SQLite3:
+		return m.lockRow(ctx)
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedDialect, m.dialect)
+	}
+}
+
+// lockKey is the advisory lock of the table, its name hashed to a bigint.
+func (m *Migrator) lockKey() int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(m.table))
+	return int64(hash.Sum64())
+}
+
+// lockInTransaction takes the lock with lockQuery, and releases it with unlockQuery, if any, before
+// rolling the transaction back. Both are given args.
+func (m *Migrator) lockInTransaction(ctx context.Context, lockQuery, unlockQuery string, args ...any) (func(context.Context) error, error) {
+	if pool, ok := m.target.SQLAdapter(); ok {
+		if pool, ok := pool.(pooled); ok && pool.Stats().MaxOpenConnections == 1 {
+			return nil, ErrSingleConnection
+		}
+	}
+	tx, err := m.target.Begin(ctx, datasource.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error taking migration lock: %w", err)
+	}
+	release := func(ctx context.Context) error {
+		if unlockQuery != "" {
+			if _, err := queryFirst(ctx, tx, unlockQuery, args...); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		return tx.Rollback()
+	}
+
+	err = m.poll(ctx, func() (bool, error) {
+		locked, err := queryFirst(ctx, tx, lockQuery, args...)
+		return isTrue(locked), err
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return release, nil
+}
+
+func (m *Migrator) lockRow(ctx context.Context) (func(context.Context) error, error) {
+	lockTable, err := m.lockTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	insert := request.Exec(fmt.Sprintf("INSERT OR IGNORE INTO %s (id, locked_at) VALUES (1, CURRENT_TIMESTAMP)", lockTable))
+	// CURRENT_TIMESTAMP is UTC to the second, as is datetime('now')
+	expire := request.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = 1 AND locked_at < datetime('now', '-%d seconds')",
+		lockTable, int64(math.Ceil(m.staleLock.Seconds()))))
+	err = m.poll(ctx, func() (bool, error) {
+		if m.staleLock > 0 {
+			if _, err := perform(ctx, m.target, expire); err != nil {
+				return false, err
+			}
+		}
+		body, err := perform(ctx, m.target, insert)
+		if err != nil {
+			return false, err
+		}
+		inserted, ok := body.(*result.Write)
+		if !ok {
+			return false, fmt.Errorf("unexpected response %T", body)
+		}
+		return inserted.RowsAffected == 1, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		_, err := perform(ctx, m.target, request.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = 1", lockTable)))
+		return err
+	}, nil
+}
+
+// ForceUnlock releases the SQLite3 lock of another runner, to recover from a runner that crashed
+// while holding it. It must only be called when no runner is migrating. PostgreSQL and MySQL
+// locks are released with the connection of their runner, for them it does nothing.
+func (m *Migrator) ForceUnlock(ctx context.Context) error {
+	if m.dialect != datasource.SQLite3 {
+		return nil
+	}
+	lockTable, err := m.lockTable(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := perform(ctx, m.target, request.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = 1", lockTable))); err != nil {
+		return fmt.Errorf("error releasing migration lock: %w", err)
+	}
+	return nil
+}
+
+// lockTable creates the SQLite3 lock table when it is missing and returns its name.
+func (m *Migrator) lockTable(ctx context.Context) (string, error) {
+	lockTable := m.table + "_lock"
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INTEGER PRIMARY KEY CHECK (id = 1), locked_at TIMESTAMP NOT NULL)", lockTable)
+	if _, err := perform(ctx, m.target, request.Exec(create)); err != nil {
+		return "", fmt.Errorf("error creating migration lock table: %w", err)
+	}
+	return lockTable, nil
+}
+
+// poll calls try until it takes the lock, fails, or the lock timeout passes.
+func (m *Migrator) poll(ctx context.Context, try func() (bool, error)) error {
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		locked, err := try()
+		if err != nil {
+			return fmt.Errorf("error taking migration lock: %w", err)
+		}
+		if locked {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLocked
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// pooled is implemented by data sources holding a pool of connections.
+type pooled interface {
+	Stats() gosql.DBStats
+}
+
+// queryFirst returns the first value of the first row of query.
+func queryFirst(ctx context.Context, tx *target.Tx, query string, args ...any) (any, error) {
+	req, err := sql.FromString(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	body, err := perform(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+	table, ok := body.(*result.Table)
+	if !ok || table.NumRows() == 0 {
+		return nil, fmt.Errorf("unexpected response %T", body)
+	}
+	return table.Rows()[0].Values[0], nil
+}
+
+func isTrue(value any) bool {
+	switch value := value.(type) {
+	case bool:
+		return value
+	case int64:
+		return value == 1
+	default:
+		return asString(value) == "1"
+	}
+}
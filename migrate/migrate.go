@@ -0,0 +1,419 @@
+// Package migrate applies versioned SQL migrations to a target and records the applied
+// versions in a table of its own.
+//
+// Migrations are read from pairs of files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, the down file being optional. A file may hold several
+// statements, which for MySQL takes multiStatements=true in the DSN.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/ctrl-alt-boop/dribble/result"
+	"github.com/ctrl-alt-boop/dribble/sql"
+	"github.com/ctrl-alt-boop/dribble/target"
+)
+
+const (
+	DefaultTable       = "dribble_migrations"
+	DefaultLockTimeout = time.Minute
+)
+
+var (
+	ErrLocked             = errors.New("migrations are locked by another runner")
+	ErrUnknownVersion     = errors.New("unknown migration version")
+	ErrNoDownMigration    = errors.New("migration has no down file")
+	ErrUnsupportedDialect = errors.New("migrations are unsupported for this data source")
+	ErrSingleConnection   = errors.New("migrations on PostgreSQL and MySQL need a pool of two connections or more")
+)
+
+type (
+	Migration struct {
+		Version uint64
+		Name    string
+
+		Up   string
+		Down string // Empty when the migration has no down file
+	}
+
+	// MigrationStatus is a migration and whether it is applied. A version that is applied
+	// but has no files is listed with the name it was recorded with, and no statements.
+	MigrationStatus struct {
+		Migration
+		Applied bool
+	}
+
+	// Migrator applies the migrations of a file system to a target.
+	Migrator struct {
+		target      *target.Target
+		dialect     datasource.SQLDialectType
+		migrations  []*Migration
+		table       string
+		lockTimeout time.Duration
+		staleLock   time.Duration
+	}
+
+	Option func(*Migrator)
+)
+
+// WithTable sets the table the applied versions are recorded in, DefaultTable by default.
+func WithTable(name string) Option {
+	return func(m *Migrator) {
+		m.table = name
+	}
+}
+
+// WithLockTimeout sets how long to wait for another runner to release the lock before failing
+// with ErrLocked, DefaultLockTimeout by default.
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(m *Migrator) {
+		m.lockTimeout = timeout
+	}
+}
+
+// WithStaleLockAfter takes over the SQLite3 lock of a runner that has held it for longer than
+// age, as a runner that crashed never releases it. Zero, the default, never takes it over, age
+// must then be longer than migrations can take. PostgreSQL and MySQL locks end with the
+// connection of their runner and are never stale.
+func WithStaleLockAfter(age time.Duration) Option {
+	return func(m *Migrator) {
+		m.staleLock = age
+	}
+}
+
+var (
+	fileNamePattern  = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+	tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// New reads the migrations in the root of fsys, other files are ignored.
+func New(t *target.Target, fsys fs.FS, opts ...Option) (*Migrator, error) {
+	dialect, ok := t.DBType.(datasource.SQLDialectType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedDialect, t.DBType)
+	}
+	m := &Migrator{
+		target:      t,
+		dialect:     dialect,
+		table:       DefaultTable,
+		lockTimeout: DefaultLockTimeout,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if !tableNamePattern.MatchString(m.table) {
+		return nil, fmt.Errorf("invalid migration table name: %q", m.table)
+	}
+
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	m.migrations = migrations
+	return m, nil
+}
+
+func readMigrations(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations: %w", err)
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s: %w", match[1], err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration %s: %w", entry.Name(), err)
+		}
+
+		migration, seen := byVersion[version]
+		if !seen {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, migration)
+	}
+	slices.SortFunc(migrations, func(a, b *Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+// Migrations returns the migrations read by New, ordered by version.
+func (m *Migrator) Migrations() []*Migration {
+	return m.migrations
+}
+
+// Up applies every migration that is not applied yet, in order.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.locked(ctx, func(applied map[uint64]string) error {
+		for _, migration := range m.migrations {
+			if _, isApplied := applied[migration.Version]; isApplied {
+				continue
+			}
+			if err := m.apply(ctx, migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the last n applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	if n < 0 {
+		return fmt.Errorf("cannot revert %d migrations", n)
+	}
+	return m.locked(ctx, func(applied map[uint64]string) error {
+		versions := appliedVersions(applied)
+		slices.Reverse(versions)
+		return m.revertAll(ctx, versions[:min(n, len(versions))])
+	})
+}
+
+// Goto applies or reverts migrations until version is the last one applied.
+// Version 0 reverts every migration.
+func (m *Migrator) Goto(ctx context.Context, version uint64) error {
+	if version != 0 && m.migration(version) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	return m.locked(ctx, func(applied map[uint64]string) error {
+		var revert []uint64
+		for _, appliedVersion := range appliedVersions(applied) {
+			if appliedVersion > version {
+				revert = append(revert, appliedVersion)
+			}
+		}
+		slices.Reverse(revert)
+		if err := m.revertAll(ctx, revert); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, isApplied := applied[migration.Version]; isApplied || migration.Version > version {
+				continue
+			}
+			if err := m.apply(ctx, migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists the migrations and whether they are applied, ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.createTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.readApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		_, isApplied := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{Migration: *migration, Applied: isApplied})
+	}
+	for _, version := range appliedVersions(applied) {
+		if m.migration(version) == nil {
+			statuses = append(statuses, MigrationStatus{Migration: Migration{Version: version, Name: applied[version]}, Applied: true})
+		}
+	}
+	slices.SortFunc(statuses, func(a, b MigrationStatus) int { return cmp.Compare(a.Version, b.Version) })
+	return statuses, nil
+}
+
+// locked runs fn holding the migration lock, with the applied versions and their names.
+func (m *Migrator) locked(ctx context.Context, fn func(applied map[uint64]string) error) (err error) {
+	release, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// The lock is released even if ctx is done.
+		if releaseErr := release(context.WithoutCancel(ctx)); releaseErr != nil && err == nil {
+			err = fmt.Errorf("error releasing migration lock: %w", releaseErr)
+		}
+	}()
+
+	if err := m.createTable(ctx); err != nil {
+		return err
+	}
+	applied, err := m.readApplied(ctx)
+	if err != nil {
+		return err
+	}
+	return fn(applied)
+}
+
+func (m *Migrator) revertAll(ctx context.Context, versions []uint64) error {
+	for _, version := range versions {
+		migration := m.migration(version)
+		if migration == nil {
+			return fmt.Errorf("%w: %d is applied but has no files", ErrUnknownVersion, version)
+		}
+		if err := m.revert(ctx, migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration *Migration) error {
+	record := request.Exec(fmt.Sprintf("INSERT INTO %s (version, name) VALUES (%s, %s)",
+		m.table, m.placeholder(1), m.placeholder(2)), migration.Version, migration.Name)
+	if err := m.run(ctx, migration.Up, record); err != nil {
+		return fmt.Errorf("error applying migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, migration *Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("%w: %d_%s", ErrNoDownMigration, migration.Version, migration.Name)
+	}
+	record := request.Exec(fmt.Sprintf("DELETE FROM %s WHERE version = %s", m.table, m.placeholder(1)), migration.Version)
+	if err := m.run(ctx, migration.Down, record); err != nil {
+		return fmt.Errorf("error reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// run executes the statements of a migration and records it, in one transaction where DDL is
+// transactional. MySQL commits DDL as it goes, a failed migration may be left partly applied.
+func (m *Migrator) run(ctx context.Context, statements string, record request.ExecRequest) error {
+	if m.dialect == datasource.MySQL {
+		if _, err := perform(ctx, m.target, request.Exec(statements)); err != nil {
+			return err
+		}
+		_, err := perform(ctx, m.target, record)
+		return err
+	}
+
+	tx, err := m.target.Begin(ctx, datasource.TxOptions{})
+	if err != nil {
+		return err
+	}
+	if _, err := perform(ctx, tx, request.Exec(statements)); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	if _, err := perform(ctx, tx, record); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) createTable(ctx context.Context) error {
+	statement := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	version BIGINT NOT NULL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`, m.table)
+	if _, err := perform(ctx, m.target, request.Exec(statement)); err != nil {
+		return fmt.Errorf("error creating migration table: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) readApplied(ctx context.Context) (map[uint64]string, error) {
+	// Two columns, a read of a single column is answered with a result.List.
+	query, err := sql.FromString(fmt.Sprintf("SELECT version, name FROM %s ORDER BY version", m.table))
+	if err != nil {
+		return nil, err
+	}
+	body, err := perform(ctx, m.target, query)
+	if err != nil {
+		return nil, fmt.Errorf("error reading applied migrations: %w", err)
+	}
+	table, ok := body.(*result.Table)
+	if !ok {
+		return nil, fmt.Errorf("error reading applied migrations: unexpected response %T", body)
+	}
+
+	applied := make(map[uint64]string, table.NumRows())
+	for _, row := range table.Rows() {
+		version, err := strconv.ParseUint(asString(row.Values[0]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid applied migration version %v: %w", row.Values[0], err)
+		}
+		applied[version] = asString(row.Values[1])
+	}
+	return applied, nil
+}
+
+func (m *Migrator) migration(version uint64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) placeholder(index int) string {
+	if m.dialect == datasource.PostgreSQL {
+		return "$" + strconv.Itoa(index)
+	}
+	return "?"
+}
+
+// performer is implemented by both *target.Target and *target.Tx.
+type performer interface {
+	PerformWithHandler(ctx context.Context, handler func(*request.Response), req datasource.Request) error
+}
+
+// perform sends req and returns the body of its response.
+func perform(ctx context.Context, p performer, req datasource.Request) (any, error) {
+	var body any
+	err := p.PerformWithHandler(ctx, func(response *request.Response) { body = response.Body }, req)
+	return body, err
+}
+
+func appliedVersions(applied map[uint64]string) []uint64 {
+	versions := make([]uint64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+	return versions
+}
+
+// asString formats a scanned value, which the MySQL driver returns as bytes.
+func asString(value any) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
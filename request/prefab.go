@@ -18,6 +18,12 @@ var (
 	_ datasource.Request = (*ReadColumnNames)(nil)
 	_ datasource.Request = (*ReadCount)(nil)
 	_ datasource.Request = (*ReadAllCounts)(nil)
+	_ datasource.Request = (*ReadIndexes)(nil)
+	_ datasource.Request = (*ReadForeignKeys)(nil)
+	_ datasource.Request = (*ReadConstraints)(nil)
+	_ datasource.Request = (*ReadViews)(nil)
+	_ datasource.Request = (*ReadRoutines)(nil)
+	_ datasource.Request = (*ReadTriggers)(nil)
 )
 
 type prefab struct {
@@ -206,6 +212,93 @@ func NewReadAllCounts(databaseName string, tableNames []string) ReadAllCounts {
 	}
 }
 
+// args = [database name, table name]
+type ReadIndexes struct {
+	prefab
+	DatabaseName string
+	TableName    string
+}
+
+func NewReadIndexes(databaseName, tableName string) ReadIndexes {
+	return ReadIndexes{
+		prefab:       prefab{successStatus: SuccessReadIndexes},
+		DatabaseName: databaseName,
+		TableName:    tableName,
+	}
+}
+
+// args = [database name, table name]
+type ReadForeignKeys struct {
+	prefab
+	DatabaseName string
+	TableName    string
+}
+
+func NewReadForeignKeys(databaseName, tableName string) ReadForeignKeys {
+	return ReadForeignKeys{
+		prefab:       prefab{successStatus: SuccessReadForeignKeys},
+		DatabaseName: databaseName,
+		TableName:    tableName,
+	}
+}
+
+// args = [database name, table name]
+type ReadConstraints struct {
+	prefab
+	DatabaseName string
+	TableName    string
+}
+
+func NewReadConstraints(databaseName, tableName string) ReadConstraints {
+	return ReadConstraints{
+		prefab:       prefab{successStatus: SuccessReadConstraints},
+		DatabaseName: databaseName,
+		TableName:    tableName,
+	}
+}
+
+// ReadViews reads the views, materialized ones included, with their definitions.
+// args = [database name]
+type ReadViews struct {
+	prefab
+	DatabaseName string
+}
+
+func NewReadViews(databaseName string) ReadViews {
+	return ReadViews{
+		prefab:       prefab{successStatus: SuccessReadViews},
+		DatabaseName: databaseName,
+	}
+}
+
+// ReadRoutines reads the stored functions and procedures, with their parameters and definitions.
+// args = [database name]
+type ReadRoutines struct {
+	prefab
+	DatabaseName string
+}
+
+func NewReadRoutines(databaseName string) ReadRoutines {
+	return ReadRoutines{
+		prefab:       prefab{successStatus: SuccessReadRoutines},
+		DatabaseName: databaseName,
+	}
+}
+
+// ReadTriggers reads the triggers of every table, with their definitions.
+// args = [database name]
+type ReadTriggers struct {
+	prefab
+	DatabaseName string
+}
+
+func NewReadTriggers(databaseName string) ReadTriggers {
+	return ReadTriggers{
+		prefab:       prefab{successStatus: SuccessReadTriggers},
+		DatabaseName: databaseName,
+	}
+}
+
 func (p prefab) ResponseOnSuccess() datasource.Response {
 	if p.successStatus == StatusUnknown {
 		panic(fmt.Sprintf("prefab request of type %T created without using its constructor", p))
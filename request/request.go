@@ -1,6 +1,8 @@
 package request
 
 import (
+	"time"
+
 	"github.com/ctrl-alt-boop/dribble/datasource"
 )
 
@@ -9,8 +11,17 @@ var _ datasource.Request = (*Intent)(nil)
 var (
 	_ datasource.Request = (*BatchRequest)(nil)
 	_ datasource.Request = (*ChainRequest)(nil)
+	_ datasource.Request = (*StreamRequest)(nil)
+	_ datasource.Request = (*TransactionRequest)(nil)
+	_ datasource.Request = (*ExecRequest)(nil)
+	_ datasource.Request = (*InsertRowsRequest)(nil)
+	_ datasource.Request = (*TimeoutRequest)(nil)
 )
 
+// DefaultChunkSize is the number of rows per chunk used by a StreamRequest
+// created with a chunk size of zero or less.
+const DefaultChunkSize = 500
+
 type (
 	Intent struct { // TODO: finalize this
 		Type datasource.RequestType
@@ -23,6 +34,47 @@ type (
 	BatchRequest []datasource.Request
 
 	ChainRequest []datasource.Request
+
+	// StreamRequest reads the result of Request in chunks of ChunkSize rows,
+	// each chunk is sent as its own Response with a *result.Chunk body.
+	StreamRequest struct {
+		Request datasource.Request
+
+		ChunkSize int
+	}
+
+	// TransactionRequest runs Requests as a chain inside a single transaction,
+	// the transaction is rolled back on the first error and committed otherwise.
+	TransactionRequest struct {
+		Requests ChainRequest
+
+		Options datasource.TxOptions
+	}
+
+	// ExecRequest runs Statement as is, for statements that are not reads or writes of rows,
+	// like DDL. It returns no rows, and a statement without Args may hold several statements
+	// where the driver allows it, which for MySQL takes multiStatements=true in the DSN.
+	ExecRequest struct {
+		Statement string
+		Args      []any
+	}
+
+	// InsertRowsRequest inserts Rows, their values in the order of Columns, into Table by the
+	// fastest path of the dialect: COPY on PostgreSQL, a prepared statement in a transaction
+	// on SQLite3 and multi-row INSERTs otherwise. It responds with the number of rows inserted.
+	InsertRowsRequest struct {
+		Table   string
+		Columns []string
+		Rows    [][]any
+	}
+
+	// TimeoutRequest cancels Request when it has run for longer than Timeout,
+	// it responds as Request does.
+	TimeoutRequest struct {
+		Request datasource.Request
+
+		Timeout time.Duration
+	}
 )
 
 func Batch(requests ...datasource.Request) BatchRequest {
@@ -33,6 +85,50 @@ func Chain(requests ...datasource.Request) ChainRequest {
 	return requests
 }
 
+func Transaction(requests ...datasource.Request) TransactionRequest {
+	return TransactionRequest{
+		Requests: requests,
+	}
+}
+
+// WithOptions returns a copy of the transaction using opts when it begins.
+func (t TransactionRequest) WithOptions(opts datasource.TxOptions) TransactionRequest {
+	t.Options = opts
+	return t
+}
+
+func Exec(statement string, args ...any) ExecRequest {
+	return ExecRequest{
+		Statement: statement,
+		Args:      args,
+	}
+}
+
+func InsertRows(table string, columns []string, rows [][]any) InsertRowsRequest {
+	return InsertRowsRequest{
+		Table:   table,
+		Columns: columns,
+		Rows:    rows,
+	}
+}
+
+func Timeout(req datasource.Request, timeout time.Duration) TimeoutRequest {
+	return TimeoutRequest{
+		Request: req,
+		Timeout: timeout,
+	}
+}
+
+func Stream(req datasource.Request, chunkSize int) StreamRequest {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return StreamRequest{
+		Request:   req,
+		ChunkSize: chunkSize,
+	}
+}
+
 // IsPrefab implements database.Request.
 func (i Intent) IsPrefab() bool {
 	return false
@@ -123,3 +219,94 @@ func (b BatchRequest) ResponseOnSuccess() datasource.Response {
 		Status: SuccessBatchExecute,
 	}
 }
+
+// IsPrefab implements database.Request.
+func (s StreamRequest) IsPrefab() bool {
+	return false
+}
+
+// ResponseOnError implements database.Request.
+func (s StreamRequest) ResponseOnError() datasource.Response {
+	return Response{
+		Status: ErrorStream,
+	}
+}
+
+// ResponseOnSuccess implements database.Request.
+func (s StreamRequest) ResponseOnSuccess() datasource.Response {
+	return Response{
+		Status: SuccessStream,
+	}
+}
+
+// IsPrefab implements database.Request.
+func (t TransactionRequest) IsPrefab() bool {
+	return false
+}
+
+// ResponseOnError implements database.Request.
+func (t TransactionRequest) ResponseOnError() datasource.Response {
+	return Response{
+		Status: ErrorTransaction,
+	}
+}
+
+// ResponseOnSuccess implements database.Request.
+func (t TransactionRequest) ResponseOnSuccess() datasource.Response {
+	return Response{
+		Status: SuccessTransaction,
+	}
+}
+
+// IsPrefab implements database.Request.
+func (e ExecRequest) IsPrefab() bool {
+	return false
+}
+
+// ResponseOnError implements database.Request.
+func (e ExecRequest) ResponseOnError() datasource.Response {
+	return Response{
+		Status: ErrorExecute,
+	}
+}
+
+// ResponseOnSuccess implements database.Request.
+func (e ExecRequest) ResponseOnSuccess() datasource.Response {
+	return Response{
+		Status: SuccessExecute,
+	}
+}
+
+// IsPrefab implements database.Request.
+func (i InsertRowsRequest) IsPrefab() bool {
+	return false
+}
+
+// ResponseOnError implements database.Request.
+func (i InsertRowsRequest) ResponseOnError() datasource.Response {
+	return Response{
+		Status: ErrorInsertRows,
+	}
+}
+
+// ResponseOnSuccess implements database.Request.
+func (i InsertRowsRequest) ResponseOnSuccess() datasource.Response {
+	return Response{
+		Status: SuccessInsertRows,
+	}
+}
+
+// IsPrefab implements database.Request.
+func (t TimeoutRequest) IsPrefab() bool {
+	return t.Request.IsPrefab()
+}
+
+// ResponseOnError implements database.Request.
+func (t TimeoutRequest) ResponseOnError() datasource.Response {
+	return t.Request.ResponseOnError()
+}
+
+// ResponseOnSuccess implements database.Request.
+func (t TimeoutRequest) ResponseOnSuccess() datasource.Response {
+	return t.Request.ResponseOnSuccess()
+}
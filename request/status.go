@@ -67,6 +67,18 @@ const (
 	SuccessExecute
 	SuccessBatchExecute
 	SuccessChainExecute
+	SuccessStream
+	SuccessTransaction
+
+	SuccessReadIndexes
+	SuccessReadForeignKeys
+	SuccessReadConstraints
+
+	SuccessReadViews
+	SuccessReadRoutines
+	SuccessReadTriggers
+
+	SuccessInsertRows
 )
 
 const (
@@ -100,4 +112,16 @@ const (
 	ErrorExecute
 	ErrorBatchExecute
 	ErrorChainExecute
+	ErrorStream
+	ErrorTransaction
+
+	ErrorReadIndexes
+	ErrorReadForeignKeys
+	ErrorReadConstraints
+
+	ErrorReadViews
+	ErrorReadRoutines
+	ErrorReadTriggers
+
+	ErrorInsertRows
 )
@@ -31,6 +31,16 @@ func _() {
 	_ = x[SuccessDelete-20]
 	_ = x[SuccessExecute-21]
 	_ = x[SuccessBatchExecute-22]
+	_ = x[SuccessChainExecute-23]
+	_ = x[SuccessStream-24]
+	_ = x[SuccessTransaction-25]
+	_ = x[SuccessReadIndexes-26]
+	_ = x[SuccessReadForeignKeys-27]
+	_ = x[SuccessReadConstraints-28]
+	_ = x[SuccessReadViews-29]
+	_ = x[SuccessReadRoutines-30]
+	_ = x[SuccessReadTriggers-31]
+	_ = x[SuccessInsertRows-32]
 	_ = x[ErrorConnect - -1]
 	_ = x[ErrorReconnect - -2]
 	_ = x[ErrorDisconnect - -3]
@@ -53,16 +63,26 @@ func _() {
 	_ = x[ErrorDelete - -20]
 	_ = x[ErrorExecute - -21]
 	_ = x[ErrorBatchExecute - -22]
+	_ = x[ErrorChainExecute - -23]
+	_ = x[ErrorStream - -24]
+	_ = x[ErrorTransaction - -25]
+	_ = x[ErrorReadIndexes - -26]
+	_ = x[ErrorReadForeignKeys - -27]
+	_ = x[ErrorReadConstraints - -28]
+	_ = x[ErrorReadViews - -29]
+	_ = x[ErrorReadRoutines - -30]
+	_ = x[ErrorReadTriggers - -31]
+	_ = x[ErrorInsertRows - -32]
 }
 
-const _Status_name = "ErrorBatchExecuteErrorExecuteErrorDeleteErrorUpdateErrorReadErrorCreateErrorReadCountErrorReadDBColumnListErrorReadDBTableListErrorReadDatabaseListErrorReadColumnPropertiesErrorReadTablePropertiesErrorReadDatabasePropertiesErrorReadColumnSchemaErrorReadTableSchemaErrorReadDatabaseSchemaErrorTargetUpdateErrorTargetCloseErrorTargetOpenErrorDisconnectErrorReconnectErrorConnectStatusUnknownSuccessConnectSuccessReconnectSuccessDisconnectSuccessTargetOpenSuccessTargetUpdateSuccessTargetCloseSuccessReadDatabaseSchemaSuccessReadTableSchemaSuccessReadColumnSchemaSuccessReadDatabasePropertiesSuccessReadTablePropertiesSuccessReadColumnPropertiesSuccessReadDatabaseListSuccessReadDBTableListSuccessReadDBColumnListSuccessReadCountSuccessCreateSuccessReadSuccessUpdateSuccessDeleteSuccessExecuteSuccessBatchExecute"
+const _Status_name = "ErrorInsertRowsErrorReadTriggersErrorReadRoutinesErrorReadViewsErrorReadConstraintsErrorReadForeignKeysErrorReadIndexesErrorTransactionErrorStreamErrorChainExecuteErrorBatchExecuteErrorExecuteErrorDeleteErrorUpdateErrorReadErrorCreateErrorReadCountErrorReadDBColumnListErrorReadDBTableListErrorReadDatabaseListErrorReadColumnPropertiesErrorReadTablePropertiesErrorReadDatabasePropertiesErrorReadColumnSchemaErrorReadTableSchemaErrorReadDatabaseSchemaErrorTargetUpdateErrorTargetCloseErrorTargetOpenErrorDisconnectErrorReconnectErrorConnectStatusUnknownSuccessConnectSuccessReconnectSuccessDisconnectSuccessTargetOpenSuccessTargetUpdateSuccessTargetCloseSuccessReadDatabaseSchemaSuccessReadTableSchemaSuccessReadColumnSchemaSuccessReadDatabasePropertiesSuccessReadTablePropertiesSuccessReadColumnPropertiesSuccessReadDatabaseListSuccessReadDBTableListSuccessReadDBColumnListSuccessReadCountSuccessCreateSuccessReadSuccessUpdateSuccessDeleteSuccessExecuteSuccessBatchExecuteSuccessChainExecuteSuccessStreamSuccessTransactionSuccessReadIndexesSuccessReadForeignKeysSuccessReadConstraintsSuccessReadViewsSuccessReadRoutinesSuccessReadTriggersSuccessInsertRows"
 
-var _Status_index = [...]uint16{0, 17, 29, 40, 51, 60, 71, 85, 106, 126, 147, 172, 196, 223, 244, 264, 287, 304, 320, 335, 350, 364, 376, 389, 403, 419, 436, 453, 472, 490, 515, 537, 560, 589, 615, 642, 665, 687, 710, 726, 739, 750, 763, 776, 790, 809}
+var _Status_index = [...]uint16{0, 15, 32, 49, 63, 83, 103, 119, 135, 146, 163, 180, 192, 203, 214, 223, 234, 248, 269, 289, 310, 335, 359, 386, 407, 427, 450, 467, 483, 498, 513, 527, 539, 552, 566, 582, 599, 616, 635, 653, 678, 700, 723, 752, 778, 805, 828, 850, 873, 889, 902, 913, 926, 939, 953, 972, 991, 1004, 1022, 1040, 1062, 1084, 1100, 1119, 1138, 1155}
 
 func (i Status) String() string {
-	i -= -22
+	i -= -32
 	if i < 0 || i >= Status(len(_Status_index)-1) {
-		return "Status(" + strconv.FormatInt(int64(i+-22), 10) + ")"
+		return "Status(" + strconv.FormatInt(int64(i+-32), 10) + ")"
 	}
 	return _Status_name[_Status_index[i]:_Status_index[i+1]]
 }
@@ -0,0 +1,52 @@
+package result
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+)
+
+var _ Body = Chunk{}
+
+// Chunk is one part of a streamed read. Every chunk carries the column
+// metadata so it can be handled on its own, the last chunk of a stream has
+// Final set and may hold no rows.
+type Chunk struct {
+	Index   int
+	Columns []*Column
+	Rows    []*Row
+	Final   bool
+
+	Resolver  datasource.SQLAdapter
+	Formatter *Formatter
+}
+
+func NewChunk(index int, columns []*Column, capacity int) *Chunk {
+	return &Chunk{
+		Index:   index,
+		Columns: columns,
+		Rows:    make([]*Row, 0, capacity),
+	}
+}
+
+// String implements Body.
+func (c Chunk) String() string {
+	rowStrings := sliceTransform(c.Rows, func(row *Row) string {
+		return row.String()
+	})
+	return fmt.Sprintf("chunk %d (%d rows, final: %t)\n%s", c.Index, len(c.Rows), c.Final, strings.Join(rowStrings, "\n"))
+}
+
+// Get implements Body.
+func (c Chunk) Get() any {
+	return c.Rows
+}
+
+// Table returns the rows of the chunk as a Table.
+func (c Chunk) Table() *Table {
+	table := NewTable(c.Columns, c.Rows)
+	table.Resolver = c.Resolver
+	table.Formatter = c.Formatter
+	return table
+}
@@ -2,37 +2,19 @@ package result
 
 import (
 	"database/sql"
-	"fmt"
-	"time"
 
 	"github.com/ctrl-alt-boop/dribble/datasource"
 )
 
 func ParseRows(dbRows *sql.Rows) ([]*Column, []*Row) {
-	dbColumns, err := dbRows.ColumnTypes()
+	columns, err := ParseColumns(dbRows)
 	if err != nil {
 		return nil, nil
 	}
-	columns := make([]*Column, len(dbColumns))
-	for i := range dbColumns {
-		columns[i] = &Column{
-			Name:     dbColumns[i].Name(),
-			ScanType: dbColumns[i].ScanType(),
-			DBType:   dbColumns[i].DatabaseTypeName(),
-		}
-	}
 
 	rows := make([]*Row, 0)
 	for dbRows.Next() {
-		row := &Row{
-			Values: make([]any, len(dbColumns)),
-		}
-		scanArr := make([]any, len(dbColumns))
-		for i := range row.Values {
-			scanArr[i] = &row.Values[i]
-		}
-
-		err := dbRows.Scan(scanArr...)
+		row, err := ScanRow(dbRows, len(columns))
 		if err != nil {
 			continue
 		}
@@ -47,23 +29,43 @@ func ParseRows(dbRows *sql.Rows) ([]*Column, []*Row) {
 	return columns, rows
 }
 
-func ResolveTypes(resolver datasource.SQLAdapter, rowValue any, column Column) (any, error) {
-	switch value := rowValue.(type) {
-	case string, int, int32, int64, float32, float64, uint, bool:
-		return value, nil
-	case time.Time:
-		return fmt.Sprint(value.Format("2006-01-02 15:04:05.000000-07")), nil
-	case []byte:
-		resolved, err := resolver.ResolveType(column.DBType, value)
-		if err != nil {
-			return "", err
-		} else {
-			return fmt.Sprintf("%v", resolved), nil
+// ParseColumns reads the column metadata of dbRows.
+func ParseColumns(dbRows *sql.Rows) ([]*Column, error) {
+	dbColumns, err := dbRows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]*Column, len(dbColumns))
+	for i := range dbColumns {
+		columns[i] = &Column{
+			Name:     dbColumns[i].Name(),
+			ScanType: dbColumns[i].ScanType(),
+			DBType:   dbColumns[i].DatabaseTypeName(),
 		}
-	case nil:
-		return "null", nil
-	default:
-		err := fmt.Errorf("unknown value type %T for %s", value, column.DBType)
-		return "", err
 	}
+	return columns, nil
+}
+
+// ScanRow scans the current row of dbRows, numColumns wide.
+func ScanRow(dbRows *sql.Rows, numColumns int) (*Row, error) {
+	row := &Row{
+		Values: make([]any, numColumns),
+	}
+	scanArr := make([]any, numColumns)
+	for i := range row.Values {
+		scanArr[i] = &row.Values[i]
+	}
+	if err := dbRows.Scan(scanArr...); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// ResolveTypes decodes the bytes drivers return for the types they leave undecoded with
+// resolver, other values are returned as they are.
+func ResolveTypes(resolver datasource.SQLAdapter, rowValue any, column Column) (any, error) {
+	if value, ok := rowValue.([]byte); ok && resolver != nil {
+		return resolver.ResolveType(column.DBType, value)
+	}
+	return rowValue, nil
 }
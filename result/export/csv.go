@@ -0,0 +1,69 @@
+package export
+
+import (
+	"io"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/result"
+)
+
+var _ Encoder = (*CSVEncoder)(nil)
+
+// CSVEncoder writes a header of column names and a line per row, see WithDelimiter,
+// WithQuoting and WithoutHeader.
+type CSVEncoder struct {
+	encoder
+}
+
+func NewCSVEncoder(w io.Writer, opts ...Option) *CSVEncoder {
+	return &CSVEncoder{newEncoder(w, newOptions("", opts))}
+}
+
+// Begin implements Encoder.
+func (e *CSVEncoder) Begin(columns []*result.Column) error {
+	e.begin(columns)
+	if !e.options.header {
+		return nil
+	}
+	fields := make([]string, len(columns))
+	for i, column := range columns {
+		fields[i] = e.field(column.Name, kindText)
+	}
+	return e.writeLine(fields)
+}
+
+// Encode implements Encoder.
+func (e *CSVEncoder) Encode(row *result.Row) error {
+	if err := e.check(row); err != nil {
+		return err
+	}
+	fields := make([]string, len(row.Values))
+	for i, value := range row.Values {
+		fields[i] = e.field(e.options.text(e.columns[i], value))
+	}
+	return e.writeLine(fields)
+}
+
+// End implements Encoder.
+func (e *CSVEncoder) End() error {
+	return e.end()
+}
+
+func (e *CSVEncoder) writeLine(fields []string) error {
+	_, err := e.w.WriteString(strings.Join(fields, string(e.options.delimiter)) + "\n")
+	return err
+}
+
+func (e *CSVEncoder) field(text string, kind valueKind) string {
+	quote := false
+	switch e.options.quoting {
+	case QuoteAll:
+		quote = kind != kindNull
+	case QuoteNonNumeric:
+		quote = kind == kindText
+	}
+	if quote || strings.ContainsRune(text, e.options.delimiter) || strings.ContainsAny(text, "\"\r\n") {
+		return `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+	}
+	return text
+}
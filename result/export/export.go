@@ -0,0 +1,262 @@
+// Package export writes results to an io.Writer as CSV, JSON, NDJSON, Markdown tables
+// or SQL INSERT statements.
+//
+// Encoders stream: Begin writes what precedes the rows, each row is written as it is
+// encoded and End writes what follows them. The same rows always encode to the same bytes.
+package export
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/result"
+)
+
+var ErrNotBegun = errors.New("encoder has not begun")
+
+type (
+	// Encoder writes rows in one format.
+	Encoder interface {
+		// Begin writes what precedes the rows, like a header, and must be called first.
+		Begin(columns []*result.Column) error
+		Encode(row *result.Row) error
+		// End writes what follows the rows and flushes the writer.
+		End() error
+	}
+
+	// BinaryEncoding is how binary values are written in text formats.
+	BinaryEncoding = result.BinaryEncoding
+
+	// Quoting is which CSV fields are quoted.
+	Quoting int
+
+	Option func(*options)
+
+	options struct {
+		null      string
+		binary    BinaryEncoding
+		delimiter rune
+		quoting   Quoting
+		header    bool
+		batchSize int
+		formatter *result.Formatter
+	}
+)
+
+const (
+	Base64 = result.Base64
+	Hex    = result.Hex
+	Raw    = result.Raw
+)
+
+const (
+	QuoteMinimal    Quoting = iota // Fields holding a delimiter, quote or line break
+	QuoteAll                       // Every field but NULLs, telling them apart from empty strings
+	QuoteNonNumeric                // Every field but numbers and NULLs
+)
+
+const DefaultBatchSize = 100
+
+// WithNull sets the text of NULL values in CSV and Markdown, empty and "NULL" by default.
+// JSON and SQL have a NULL of their own.
+func WithNull(text string) Option {
+	return func(o *options) {
+		o.null = text
+	}
+}
+
+// WithBinary sets how binary values are written in CSV, JSON and Markdown, Base64 by default.
+// SQL statements use the binary literal of their dialect.
+func WithBinary(encoding BinaryEncoding) Option {
+	return func(o *options) {
+		o.binary = encoding
+	}
+}
+
+// WithDelimiter sets the CSV field delimiter, a comma by default.
+func WithDelimiter(delimiter rune) Option {
+	return func(o *options) {
+		o.delimiter = delimiter
+	}
+}
+
+// WithQuoting sets which CSV fields are quoted, QuoteMinimal by default.
+func WithQuoting(quoting Quoting) Option {
+	return func(o *options) {
+		o.quoting = quoting
+	}
+}
+
+// WithoutHeader leaves out the CSV header of column names.
+func WithoutHeader() Option {
+	return func(o *options) {
+		o.header = false
+	}
+}
+
+// WithBatchSize sets the number of rows per INSERT statement, DefaultBatchSize by default.
+func WithBatchSize(rows int) Option {
+	return func(o *options) {
+		o.batchSize = max(rows, 1)
+	}
+}
+
+// WithFormatter formats the values of CSV and Markdown, and the times and binary values of JSON,
+// with formatter in place of the text of WithNull and WithBinary.
+func WithFormatter(formatter *result.Formatter) Option {
+	return func(o *options) {
+		o.formatter = formatter
+	}
+}
+
+func newOptions(null string, opts []Option) *options {
+	o := &options{
+		null:      null,
+		binary:    Base64,
+		delimiter: ',',
+		quoting:   QuoteMinimal,
+		header:    true,
+		batchSize: DefaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.formatter == nil {
+		o.formatter = result.NewFormatter(
+			result.WithNullText(o.null),
+			result.WithBinaryEncoding(o.binary),
+			result.WithTimeLayout(time.RFC3339Nano),
+			result.WithDateLayout(time.RFC3339Nano),
+		)
+	}
+	return o
+}
+
+// EncodeTable encodes every row of table, from Begin to End.
+func EncodeTable(enc Encoder, table *result.Table) error {
+	if err := enc.Begin(table.Columns()); err != nil {
+		return err
+	}
+	for _, row := range table.Rows() {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return enc.End()
+}
+
+// EncodeChunk encodes the rows of one chunk of a stream, beginning at the first chunk and
+// ending at the final one. The chunks must be encoded in order.
+func EncodeChunk(enc Encoder, chunk *result.Chunk) error {
+	if chunk.Index == 0 {
+		if err := enc.Begin(chunk.Columns); err != nil {
+			return err
+		}
+	}
+	for _, row := range chunk.Rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	if chunk.Final {
+		return enc.End()
+	}
+	return nil
+}
+
+// encoder holds what all the encoders share.
+type encoder struct {
+	w       *bufio.Writer
+	options *options
+	columns []*result.Column
+}
+
+func newEncoder(w io.Writer, options *options) encoder {
+	return encoder{
+		w:       bufio.NewWriter(w),
+		options: options,
+	}
+}
+
+func (e *encoder) begin(columns []*result.Column) {
+	e.columns = columns
+}
+
+func (e *encoder) check(row *result.Row) error {
+	if e.columns == nil {
+		return ErrNotBegun
+	}
+	if len(row.Values) != len(e.columns) {
+		return fmt.Errorf("row has %d values for %d columns", len(row.Values), len(e.columns))
+	}
+	return nil
+}
+
+func (e *encoder) end() error {
+	if e.columns == nil {
+		return ErrNotBegun
+	}
+	return e.w.Flush()
+}
+
+type valueKind int
+
+const (
+	kindNull valueKind = iota
+	kindNumber
+	kindText
+)
+
+// normalize resolves driver values and turns the bytes of text columns into strings,
+// as the MySQL driver returns every column as bytes. Decimals are kept, to be written as numbers.
+func normalize(column *result.Column, value any) any {
+	if _, ok := value.(result.Decimal); ok {
+		return value
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		if resolved, err := valuer.Value(); err == nil {
+			value = resolved
+		}
+	}
+	if b, ok := value.([]byte); ok && !column.IsBinary(b) {
+		return string(b)
+	}
+	return value
+}
+
+// text formats a value of column for the text formats.
+func (o *options) text(column *result.Column, value any) (string, valueKind) {
+	value = normalize(column, value)
+	switch {
+	case value == nil:
+		return o.formatter.Null(), kindNull
+	case result.IsNumber(value):
+		return o.formatter.Format(column, value), kindNumber
+	default:
+		return o.formatter.Format(column, value), kindText
+	}
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+func isNumeric(column *result.Column) bool {
+	if column.ScanType == nil {
+		return false
+	}
+	switch column.ScanType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
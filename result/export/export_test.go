@@ -0,0 +1,148 @@
+package export_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/mysql"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/postgres"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/sqlite3"
+	"github.com/ctrl-alt-boop/dribble/result"
+	"github.com/ctrl-alt-boop/dribble/result/export"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func usersTable() *result.Table {
+	columns := []*result.Column{
+		{Name: "id", ScanType: reflect.TypeFor[int64](), DBType: "INTEGER"},
+		{Name: "name", ScanType: reflect.TypeFor[string](), DBType: "VARCHAR"},
+		{Name: "note", ScanType: reflect.TypeFor[[]byte](), DBType: "TEXT"},
+		{Name: "score", ScanType: reflect.TypeFor[float64](), DBType: "REAL"},
+		{Name: "active", ScanType: reflect.TypeFor[bool](), DBType: "BOOLEAN"},
+		{Name: "avatar", ScanType: reflect.TypeFor[[]byte](), DBType: "BLOB"},
+		{Name: "created", ScanType: reflect.TypeFor[time.Time](), DBType: "TIMESTAMP"},
+	}
+	created := time.Date(2024, 3, 1, 12, 30, 0, 500000000, time.UTC)
+	rows := []*result.Row{
+		{Values: []any{int64(1), "Ada", []byte("likes <math> & tea"), 9.5, true, []byte{0x89, 'P', 'N', 'G'}, created}},
+		{Values: []any{int64(2), "O'Brien, \"Bob\"", []byte("line one\nline | two"), 0.1, false, nil, created.Add(time.Hour)}},
+		{Values: []any{int64(3), "", nil, nil, nil, []byte{}, nil}},
+	}
+	return result.NewTable(columns, rows)
+}
+
+func TestEncoders(t *testing.T) {
+	cases := map[string]func(w *bytes.Buffer) export.Encoder{
+		"csv": func(w *bytes.Buffer) export.Encoder { return export.NewCSVEncoder(w) },
+		"csv_semicolon_all": func(w *bytes.Buffer) export.Encoder {
+			return export.NewCSVEncoder(w, export.WithDelimiter(';'), export.WithQuoting(export.QuoteAll))
+		},
+		"csv_nonnumeric": func(w *bytes.Buffer) export.Encoder {
+			return export.NewCSVEncoder(w, export.WithQuoting(export.QuoteNonNumeric), export.WithNull(`\N`), export.WithBinary(export.Hex), export.WithoutHeader())
+		},
+		"json":     func(w *bytes.Buffer) export.Encoder { return export.NewJSONEncoder(w) },
+		"ndjson":   func(w *bytes.Buffer) export.Encoder { return export.NewNDJSONEncoder(w, export.WithBinary(export.Hex)) },
+		"markdown": func(w *bytes.Buffer) export.Encoder { return export.NewMarkdownEncoder(w) },
+		"markdown_formatted": func(w *bytes.Buffer) export.Encoder {
+			return export.NewMarkdownEncoder(w, export.WithFormatter(result.NewFormatter(
+				result.WithLocation(time.FixedZone("CET", 3600)),
+				result.WithTimeLayout(time.DateTime),
+				result.WithNullText("-"),
+				result.WithFloatPrecision(2),
+				result.WithTruncate(10),
+			)))
+		},
+		"insert_postgres": func(w *bytes.Buffer) export.Encoder {
+			return export.NewInsertEncoder(w, postgres.New(nil).(*postgres.Postgres), "public.users")
+		},
+		"insert_mysql": func(w *bytes.Buffer) export.Encoder {
+			return export.NewInsertEncoder(w, mysql.New(nil).(*mysql.MySQL), "users", export.WithBatchSize(2))
+		},
+		"insert_sqlite3": func(w *bytes.Buffer) export.Encoder {
+			return export.NewInsertEncoder(w, sqlite3.New(nil).(*sqlite3.SQLite3), "users", export.WithBatchSize(1))
+		},
+	}
+	for name, newEncoder := range cases {
+		t.Run(name, func(t *testing.T) {
+			var first, second bytes.Buffer
+			if err := export.EncodeTable(newEncoder(&first), usersTable()); err != nil {
+				t.Fatal(err)
+			}
+			if err := export.EncodeTable(newEncoder(&second), usersTable()); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(first.Bytes(), second.Bytes()) {
+				t.Fatal("encoding is not reproducible")
+			}
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, first.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := first.String(); got != string(want) {
+				t.Errorf("output mismatch\n got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestEncodeChunks(t *testing.T) {
+	table := usersTable()
+	var whole, chunked bytes.Buffer
+	if err := export.EncodeTable(export.NewJSONEncoder(&whole), table); err != nil {
+		t.Fatal(err)
+	}
+
+	enc := export.NewJSONEncoder(&chunked)
+	chunks := []*result.Chunk{
+		{Index: 0, Columns: table.Columns(), Rows: table.Rows()[:2]},
+		{Index: 1, Columns: table.Columns(), Rows: table.Rows()[2:]},
+		{Index: 2, Columns: table.Columns(), Final: true},
+	}
+	for _, chunk := range chunks {
+		if err := export.EncodeChunk(enc, chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if whole.String() != chunked.String() {
+		t.Errorf("chunked output differs\n got:\n%s\nwant:\n%s", chunked.String(), whole.String())
+	}
+}
+
+func TestEncoderErrors(t *testing.T) {
+	var buffer bytes.Buffer
+	enc := export.NewNDJSONEncoder(&buffer)
+	if err := enc.Encode(&result.Row{Values: []any{1}}); !errors.Is(err, export.ErrNotBegun) {
+		t.Errorf("Encode before Begin error = %v, want ErrNotBegun", err)
+	}
+	if err := enc.Begin(usersTable().Columns()); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(&result.Row{Values: []any{1}}); err == nil {
+		t.Error("expected an error for a row with too few values")
+	}
+}
+
+func TestEmptyJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := export.EncodeTable(export.NewJSONEncoder(&buffer), result.NewTable(usersTable().Columns(), nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := buffer.String(); got != "[]\n" {
+		t.Errorf("got %q, want %q", got, "[]\n")
+	}
+}
@@ -0,0 +1,90 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/result"
+)
+
+var _ Encoder = (*InsertEncoder)(nil)
+
+// InsertEncoder writes INSERT statements of WithBatchSize rows each, their values
+// rendered as literals by the dialect.
+type InsertEncoder struct {
+	encoder
+	dialect datasource.SQLAdapter
+	table   string
+
+	insert string
+	batch  int
+}
+
+// NewInsertEncoder writes INSERT statements into table, which may be qualified by a schema.
+// The dialect of a target is returned by its SQLAdapter method.
+func NewInsertEncoder(w io.Writer, dialect datasource.SQLAdapter, table string, opts ...Option) *InsertEncoder {
+	return &InsertEncoder{
+		encoder: newEncoder(w, newOptions("", opts)),
+		dialect: dialect,
+		table:   table,
+	}
+}
+
+// Begin implements Encoder.
+func (e *InsertEncoder) Begin(columns []*result.Column) error {
+	e.begin(columns)
+	e.batch = 0
+
+	parts := strings.Split(e.table, ".")
+	for i, part := range parts {
+		parts[i] = e.dialect.Quote(part)
+	}
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = e.dialect.Quote(column.Name)
+	}
+	e.insert = fmt.Sprintf("INSERT INTO %s (%s) VALUES\n\t", strings.Join(parts, "."), strings.Join(names, ", "))
+	return nil
+}
+
+// Encode implements Encoder.
+func (e *InsertEncoder) Encode(row *result.Row) error {
+	if err := e.check(row); err != nil {
+		return err
+	}
+	values := make([]string, len(row.Values))
+	for i, value := range row.Values {
+		values[i] = e.dialect.RenderValue(normalize(e.columns[i], value))
+	}
+
+	prefix := ",\n\t"
+	if e.batch == 0 {
+		prefix = e.insert
+	}
+	if _, err := e.w.WriteString(prefix + "(" + strings.Join(values, ", ") + ")"); err != nil {
+		return err
+	}
+	e.batch++
+	if e.batch == e.options.batchSize {
+		return e.terminate()
+	}
+	return nil
+}
+
+// End implements Encoder.
+func (e *InsertEncoder) End() error {
+	if e.batch > 0 {
+		if err := e.terminate(); err != nil {
+			return err
+		}
+	}
+	return e.end()
+}
+
+func (e *InsertEncoder) terminate() error {
+	e.batch = 0
+	_, err := e.w.WriteString(";\n")
+	return err
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/result"
+)
+
+var (
+	_ Encoder = (*JSONEncoder)(nil)
+	_ Encoder = (*NDJSONEncoder)(nil)
+)
+
+// JSONEncoder writes an array holding an object per row, its keys in column order.
+type JSONEncoder struct {
+	encoder
+	rows int
+}
+
+// NDJSONEncoder writes an object per row, one per line.
+type NDJSONEncoder struct {
+	encoder
+}
+
+func NewJSONEncoder(w io.Writer, opts ...Option) *JSONEncoder {
+	return &JSONEncoder{encoder: newEncoder(w, newOptions("", opts))}
+}
+
+func NewNDJSONEncoder(w io.Writer, opts ...Option) *NDJSONEncoder {
+	return &NDJSONEncoder{newEncoder(w, newOptions("", opts))}
+}
+
+// Begin implements Encoder.
+func (e *JSONEncoder) Begin(columns []*result.Column) error {
+	e.begin(columns)
+	e.rows = 0
+	_, err := e.w.WriteString("[")
+	return err
+}
+
+// Encode implements Encoder.
+func (e *JSONEncoder) Encode(row *result.Row) error {
+	if err := e.check(row); err != nil {
+		return err
+	}
+	object, err := e.object(row)
+	if err != nil {
+		return err
+	}
+	separator := ",\n  "
+	if e.rows == 0 {
+		separator = "\n  "
+	}
+	e.rows++
+	_, err = e.w.WriteString(separator + object)
+	return err
+}
+
+// End implements Encoder.
+func (e *JSONEncoder) End() error {
+	if e.columns == nil {
+		return ErrNotBegun
+	}
+	closing := "\n]\n"
+	if e.rows == 0 {
+		closing = "]\n"
+	}
+	if _, err := e.w.WriteString(closing); err != nil {
+		return err
+	}
+	return e.end()
+}
+
+// Begin implements Encoder.
+func (e *NDJSONEncoder) Begin(columns []*result.Column) error {
+	e.begin(columns)
+	return nil
+}
+
+// Encode implements Encoder.
+func (e *NDJSONEncoder) Encode(row *result.Row) error {
+	if err := e.check(row); err != nil {
+		return err
+	}
+	object, err := e.object(row)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.WriteString(object + "\n")
+	return err
+}
+
+// End implements Encoder.
+func (e *NDJSONEncoder) End() error {
+	return e.end()
+}
+
+// object renders row as a JSON object, written by hand to keep the column order.
+func (e *encoder) object(row *result.Row) (string, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, value := range row.Values {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		if err := writeJSON(&buffer, e.columns[i].Name); err != nil {
+			return "", err
+		}
+		buffer.WriteByte(':')
+		if err := writeJSON(&buffer, e.options.jsonValue(e.columns[i], value)); err != nil {
+			return "", err
+		}
+	}
+	buffer.WriteByte('}')
+	return buffer.String(), nil
+}
+
+// jsonValue returns what value is marshalled as. Binary values are encoded as strings,
+// and NaN and the infinities, which JSON lacks, are written as strings too.
+func (o *options) jsonValue(column *result.Column, value any) any {
+	switch value := normalize(column, value).(type) {
+	case []byte:
+		return o.formatter.Format(column, value)
+	case float32:
+		if !isFinite(float64(value)) {
+			return strconv.FormatFloat(float64(value), 'g', -1, 32)
+		}
+		return value
+	case float64:
+		if !isFinite(value) {
+			return strconv.FormatFloat(value, 'g', -1, 64)
+		}
+		return value
+	case time.Time:
+		return o.formatter.Format(column, value)
+	default:
+		return value
+	}
+}
+
+func writeJSON(buffer *bytes.Buffer, value any) error {
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	buffer.Truncate(buffer.Len() - 1) // The newline Encode ends with
+	return nil
+}
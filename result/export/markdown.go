@@ -0,0 +1,62 @@
+package export
+
+import (
+	"io"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/result"
+)
+
+var _ Encoder = (*MarkdownEncoder)(nil)
+
+// MarkdownEncoder writes a GitHub Flavored Markdown table, numeric columns aligned right.
+type MarkdownEncoder struct {
+	encoder
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+func NewMarkdownEncoder(w io.Writer, opts ...Option) *MarkdownEncoder {
+	return &MarkdownEncoder{newEncoder(w, newOptions("NULL", opts))}
+}
+
+// Begin implements Encoder.
+func (e *MarkdownEncoder) Begin(columns []*result.Column) error {
+	e.begin(columns)
+	names := make([]string, len(columns))
+	alignments := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = markdownEscaper.Replace(column.Name)
+		alignments[i] = "---"
+		if isNumeric(column) {
+			alignments[i] = "---:"
+		}
+	}
+	if err := e.writeRow(names); err != nil {
+		return err
+	}
+	return e.writeRow(alignments)
+}
+
+// Encode implements Encoder.
+func (e *MarkdownEncoder) Encode(row *result.Row) error {
+	if err := e.check(row); err != nil {
+		return err
+	}
+	cells := make([]string, len(row.Values))
+	for i, value := range row.Values {
+		text, _ := e.options.text(e.columns[i], value)
+		cells[i] = markdownEscaper.Replace(text)
+	}
+	return e.writeRow(cells)
+}
+
+// End implements Encoder.
+func (e *MarkdownEncoder) End() error {
+	return e.end()
+}
+
+func (e *MarkdownEncoder) writeRow(cells []string) error {
+	_, err := e.w.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	return err
+}
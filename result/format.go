@@ -0,0 +1,301 @@
+package result
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultFormatter formats the values of tables and lists that have no Formatter of their own.
+var DefaultFormatter = NewFormatter()
+
+const (
+	DefaultTimeLayout = "2006-01-02 15:04:05.000000-07"
+	DefaultDateLayout = time.DateOnly
+	DefaultNull       = "NULL"
+)
+
+// BinaryEncoding is how binary values are written as text.
+type BinaryEncoding int
+
+const (
+	Base64 BinaryEncoding = iota // Standard base64 with padding
+	Hex                          // Lowercase hex digits
+	Raw                          // The bytes as they are, which may not be valid UTF-8
+)
+
+type (
+	// Formatter turns the values of a result into text.
+	Formatter struct {
+		rules      []Rule
+		location   *time.Location
+		timeLayout string
+		dateLayout string
+		null       string
+		precision  int
+		thousands  string
+		binary     BinaryEncoding
+		truncate   int
+	}
+
+	// Rule formats value, a value of column, reporting false to leave it to the next rule
+	// and at last to the Formatter.
+	Rule func(column *Column, value any) (string, bool)
+
+	FormatOption func(*Formatter)
+)
+
+// NewFormatter returns a Formatter keeping times in their own zone, writing them with
+// DefaultTimeLayout and DefaultDateLayout, NULL as DefaultNull, floats in as few digits as
+// tell them apart and bytes as hex.
+func NewFormatter(opts ...FormatOption) *Formatter {
+	f := &Formatter{
+		timeLayout: DefaultTimeLayout,
+		dateLayout: DefaultDateLayout,
+		null:       DefaultNull,
+		precision:  -1,
+		binary:     Hex,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// WithRule adds a rule tried before the built-in formatting, in the order they were added.
+func WithRule(rule Rule) FormatOption {
+	return func(f *Formatter) {
+		f.rules = append(f.rules, rule)
+	}
+}
+
+// WithLocation converts times to loc, such as time.UTC, time.Local or one from time.LoadLocation.
+// The values of DATE columns are not converted, they have no time of day to convert.
+func WithLocation(loc *time.Location) FormatOption {
+	return func(f *Formatter) {
+		f.location = loc
+	}
+}
+
+// WithTimeLayout sets the layout of times.
+func WithTimeLayout(layout string) FormatOption {
+	return func(f *Formatter) {
+		f.timeLayout = layout
+	}
+}
+
+// WithDateLayout sets the layout of the values of DATE columns.
+func WithDateLayout(layout string) FormatOption {
+	return func(f *Formatter) {
+		f.dateLayout = layout
+	}
+}
+
+// WithNullText sets the text of NULL values.
+func WithNullText(text string) FormatOption {
+	return func(f *Formatter) {
+		f.null = text
+	}
+}
+
+// WithFloatPrecision sets the number of digits after the decimal point of floats and decimals,
+// a negative precision uses as few digits as tell floats apart and leaves decimals as they are.
+func WithFloatPrecision(digits int) FormatOption {
+	return func(f *Formatter) {
+		f.precision = digits
+	}
+}
+
+// WithThousandsSeparator groups the integer digits of numbers in thousands.
+func WithThousandsSeparator(separator string) FormatOption {
+	return func(f *Formatter) {
+		f.thousands = separator
+	}
+}
+
+// WithBinaryEncoding sets how binary values are written.
+func WithBinaryEncoding(encoding BinaryEncoding) FormatOption {
+	return func(f *Formatter) {
+		f.binary = encoding
+	}
+}
+
+// WithTruncate cuts text longer than width runes, ending it in an ellipsis. Numbers, times
+// and NULLs are never cut.
+func WithTruncate(width int) FormatOption {
+	return func(f *Formatter) {
+		f.truncate = width
+	}
+}
+
+// Null returns the text of NULL values.
+func (f *Formatter) Null() string {
+	return f.null
+}
+
+// Format returns value, a value of column, as text. Column may be nil.
+func (f *Formatter) Format(column *Column, value any) string {
+	for _, rule := range f.rules {
+		if text, ok := rule(column, value); ok {
+			return text
+		}
+	}
+	switch value := value.(type) {
+	case nil:
+		return f.null
+	case string:
+		return f.cut(value)
+	case []byte:
+		if column != nil && !column.IsBinary(value) {
+			return f.cut(string(value))
+		}
+		return f.cut(f.encodeBinary(value))
+	case json.RawMessage:
+		return f.cut(string(value))
+	case bool:
+		return strconv.FormatBool(value)
+	case int, int8, int16, int32, int64:
+		return f.group(strconv.FormatInt(toInt64(value), 10))
+	case uint, uint8, uint16, uint32, uint64:
+		return f.group(fmt.Sprint(value))
+	case float32:
+		return f.formatFloat(float64(value), 32)
+	case float64:
+		return f.formatFloat(value, 64)
+	case Decimal:
+		return f.formatDecimal(value)
+	case time.Time:
+		return f.formatTime(column, value)
+	case map[string]*string:
+		return f.cut(formatHstore(value))
+	case fmt.Stringer:
+		return f.cut(value.String())
+	case driver.Valuer:
+		resolved, err := value.Value()
+		if err != nil {
+			return f.cut(err.Error())
+		}
+		return f.Format(column, resolved)
+	default:
+		return f.cut(fmt.Sprint(value))
+	}
+}
+
+// IsNumber reports whether Format writes value as a number.
+func IsNumber(value any) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, Decimal:
+		return true
+	default:
+		return false
+	}
+}
+
+func toInt64(value any) int64 {
+	switch value := value.(type) {
+	case int:
+		return int64(value)
+	case int8:
+		return int64(value)
+	case int16:
+		return int64(value)
+	case int32:
+		return int64(value)
+	default:
+		return value.(int64)
+	}
+}
+
+// formatFloat writes floats far from one in exponent notation when no precision is set.
+func (f *Formatter) formatFloat(value float64, bitSize int) string {
+	if f.precision < 0 {
+		if abs := math.Abs(value); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+			return strconv.FormatFloat(value, 'g', -1, bitSize)
+		}
+		return f.group(strconv.FormatFloat(value, 'f', -1, bitSize))
+	}
+	return f.group(strconv.FormatFloat(value, 'f', f.precision, bitSize))
+}
+
+func (f *Formatter) formatDecimal(value Decimal) string {
+	if rat, finite := value.Rat(); finite && f.precision >= 0 {
+		return f.group(rat.FloatString(f.precision))
+	}
+	return f.group(value.String())
+}
+
+// group inserts the thousands separator into the integer digits of number,
+// leaving numbers in exponent notation and the non-finite ones as they are.
+func (f *Formatter) group(number string) string {
+	if f.thousands == "" || strings.ContainsAny(number, "eEnN") {
+		return number
+	}
+	sign := ""
+	if number != "" && (number[0] == '-' || number[0] == '+') {
+		sign, number = number[:1], number[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(number, ".")
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteString(f.thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+	if hasFraction {
+		return sign + grouped.String() + "." + fraction
+	}
+	return sign + grouped.String()
+}
+
+func (f *Formatter) formatTime(column *Column, value time.Time) string {
+	if column != nil && strings.EqualFold(column.DBType, "DATE") {
+		// Drivers return dates as midnight UTC, another zone would move them to another day
+		return value.Format(f.dateLayout)
+	}
+	if f.location != nil {
+		value = value.In(f.location)
+	}
+	return value.Format(f.timeLayout)
+}
+
+func (f *Formatter) encodeBinary(value []byte) string {
+	switch f.binary {
+	case Hex:
+		return hex.EncodeToString(value)
+	case Raw:
+		return string(value)
+	default:
+		return base64.StdEncoding.EncodeToString(value)
+	}
+}
+
+func (f *Formatter) cut(text string) string {
+	if f.truncate <= 0 || utf8.RuneCountInString(text) <= f.truncate {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:max(f.truncate-1, 0)]) + "…"
+}
+
+// formatHstore writes the pairs as postgres does, sorted by key.
+func formatHstore(hstore map[string]*string) string {
+	pairs := make([]string, 0, len(hstore))
+	for _, key := range slices.Sorted(maps.Keys(hstore)) {
+		if hstore[key] == nil {
+			pairs = append(pairs, fmt.Sprintf("%q=>NULL", key))
+		} else {
+			pairs = append(pairs, fmt.Sprintf("%q=>%q", key, *hstore[key]))
+		}
+	}
+	return strings.Join(pairs, ", ")
+}
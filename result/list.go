@@ -2,7 +2,6 @@ package result
 
 import (
 	"database/sql"
-	"fmt"
 	"reflect"
 	"strings"
 )
@@ -14,17 +13,20 @@ type List struct {
 	ScanType   reflect.Type
 	ActualType reflect.Type
 	DBKind     string
+
+	Formatter *Formatter
 }
 
 // String implements Response.
 func (l List) String() string {
+	formatter := l.Formatter
+	if formatter == nil {
+		formatter = DefaultFormatter
+	}
+	column := &Column{Name: l.DBField, ScanType: l.ScanType, DBType: l.DBTypeName}
 	stringValues := make([]string, len(l.Values))
 	for i, v := range l.Values {
-		if v == nil {
-			stringValues[i] = "NULL"
-		} else {
-			stringValues[i] = fmt.Sprintf("%v", v)
-		}
+		stringValues[i] = formatter.Format(column, v)
 	}
 	return strings.Join(stringValues, "\n")
 }
@@ -0,0 +1,424 @@
+package result
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+)
+
+// ScanAll scans every row of the body of response into a T.
+//
+// A struct T is filled field by field: a column goes to the field tagged with its name,
+// as in `db:"name"`, or to the field of the same name ignoring case, fields of embedded
+// structs included. Fields tagged `db:"-"` are skipped, and so are columns without a field.
+// Any other T, or a T implementing sql.Scanner, takes the single column of the row whole.
+//
+// Values are assigned as database/sql would, sql.Null* types and pointers taking NULLs.
+// Bytes a driver returns for values it does not decode are decoded by ResolveType of the
+// dialect first, unless the field takes bytes or strings.
+func ScanAll[T any](response *request.Response) ([]T, error) {
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	scanner, rows, err := newBodyScanner[T](response.Body)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]T, 0, len(rows))
+	for _, row := range rows {
+		value, err := scanner.scan(row)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// ScanOne scans the first row of the body of response into a T, see ScanAll.
+// It returns sql.ErrNoRows when there are no rows.
+func ScanOne[T any](response *request.Response) (T, error) {
+	var zero T
+	if response.Error != nil {
+		return zero, response.Error
+	}
+	scanner, rows, err := newBodyScanner[T](response.Body)
+	if err != nil {
+		return zero, err
+	}
+	if len(rows) == 0 {
+		return zero, sql.ErrNoRows
+	}
+	return scanner.scan(rows[0])
+}
+
+// Iter scans the rows of the chunks of a streamed read into values of T as they arrive,
+// see ScanAll. Iteration stops at the first error, which is yielded with a zero T.
+// The context of the stream should be cancelled when the loop is left early.
+func Iter[T any](responses <-chan *request.Response) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for response := range responses {
+			if response.Error != nil {
+				yield(zero, response.Error)
+				return
+			}
+			scanner, rows, err := newBodyScanner[T](response.Body)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, row := range rows {
+				value, err := scanner.scan(row)
+				if !yield(value, err) || err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+type rowScanner[T any] struct {
+	columns  []*Column
+	resolver datasource.SQLAdapter
+	// fields holds the index path of the field of each column, nil for columns without one.
+	// A nil fields scans the single column into the whole T.
+	fields [][]int
+}
+
+func newBodyScanner[T any](body any) (*rowScanner[T], []*Row, error) {
+	var columns []*Column
+	var rows []*Row
+	var resolver datasource.SQLAdapter
+	switch body := body.(type) {
+	case *Table:
+		columns, rows, resolver = body.columns, body.rows, body.Resolver
+	case Table:
+		columns, rows, resolver = body.columns, body.rows, body.Resolver
+	case *Chunk:
+		columns, rows, resolver = body.Columns, body.Rows, body.Resolver
+	case Chunk:
+		columns, rows, resolver = body.Columns, body.Rows, body.Resolver
+	case *List:
+		columns, rows = body.asRows()
+	case List:
+		columns, rows = body.asRows()
+	default:
+		return nil, nil, fmt.Errorf("cannot scan rows from %T", body)
+	}
+
+	scanner := &rowScanner[T]{columns: columns, resolver: resolver}
+	target := reflect.TypeFor[T]()
+	if !isStructTarget(target) {
+		if len(columns) != 1 {
+			return nil, nil, fmt.Errorf("cannot scan %d columns into %v", len(columns), target)
+		}
+		return scanner, rows, nil
+	}
+
+	fieldsByName := structFields(target)
+	scanner.fields = make([][]int, len(columns))
+	for i, column := range columns {
+		scanner.fields[i] = fieldsByName[strings.ToLower(column.Name)]
+	}
+	return scanner, rows, nil
+}
+
+func (s *rowScanner[T]) scan(row *Row) (T, error) {
+	var value T
+	target := reflect.ValueOf(&value).Elem()
+	if s.fields == nil {
+		if err := s.assign(target, row.Values[0], s.columns[0]); err != nil {
+			return value, fmt.Errorf("column %s: %w", s.columns[0].Name, err)
+		}
+		return value, nil
+	}
+	for i, path := range s.fields {
+		if path == nil {
+			continue
+		}
+		if err := s.assign(fieldByIndex(target, path), row.Values[i], s.columns[i]); err != nil {
+			return value, fmt.Errorf("column %s: %w", s.columns[i].Name, err)
+		}
+	}
+	return value, nil
+}
+
+var (
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	timeType    = reflect.TypeFor[time.Time]()
+	bytesType   = reflect.TypeFor[[]byte]()
+)
+
+// isStructTarget reports whether values of t are filled field by field.
+func isStructTarget(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(scannerType)
+}
+
+var structFieldsCache sync.Map // reflect.Type -> map[string][]int
+
+// structFields maps the lowercase column names of the fields of t to their index paths.
+// Fields of embedded structs are shadowed by those of the same name less deeply embedded.
+func structFields(t reflect.Type) map[string][]int {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.(map[string][]int)
+	}
+	fields := map[string][]int{}
+	depths := map[string]int{}
+	var walk func(t reflect.Type, path []int)
+	walk = func(t reflect.Type, path []int) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			tag, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+			if tag == "-" {
+				continue
+			}
+			index := append(slices.Clone(path), i)
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if field.Anonymous && tag == "" && fieldType.Kind() == reflect.Struct && isStructTarget(fieldType) {
+				// As with encoding/json, a pointer to an unexported struct cannot be allocated.
+				if field.IsExported() || field.Type.Kind() != reflect.Pointer {
+					walk(fieldType, index)
+				}
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.ToLower(field.Name)
+			if tag != "" {
+				name = strings.ToLower(tag)
+			}
+			if depth, seen := depths[name]; !seen || len(index) < depth {
+				fields[name], depths[name] = index, len(index)
+			}
+		}
+	}
+	walk(t, nil)
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// fieldByIndex returns the field at path, allocating nil embedded struct pointers on the way.
+func fieldByIndex(v reflect.Value, path []int) reflect.Value {
+	for i, index := range path {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(index)
+	}
+	return v
+}
+
+// assign sets target to value, a value of column, converting it as database/sql would.
+func (s *rowScanner[T]) assign(target reflect.Value, value any, column *Column) error {
+	// Lists hold the scan types of their drivers, like sql.NullString.
+	if valuer, ok := value.(driver.Valuer); ok && !reflect.TypeOf(value).AssignableTo(target.Type()) {
+		resolved, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		value = resolved
+	}
+	if target.CanAddr() && target.Addr().Type().Implements(scannerType) {
+		if b, ok := value.([]byte); ok {
+			value = append([]byte(nil), b...)
+		}
+		return target.Addr().Interface().(sql.Scanner).Scan(value)
+	}
+
+	if value == nil {
+		switch target.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			target.SetZero()
+			return nil
+		default:
+			return fmt.Errorf("cannot scan NULL into %v", target.Type())
+		}
+	}
+	switch target.Kind() {
+	case reflect.Pointer:
+		element := reflect.New(target.Type().Elem())
+		if err := s.assign(element.Elem(), value, column); err != nil {
+			return err
+		}
+		target.Set(element)
+		return nil
+	case reflect.Interface:
+		if b, ok := value.([]byte); ok {
+			value = append([]byte(nil), b...)
+		}
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	if b, ok := value.([]byte); ok {
+		switch {
+		case target.Type() == bytesType:
+			target.SetBytes(append([]byte(nil), b...))
+			return nil
+		case target.Kind() == reflect.String:
+			target.SetString(string(b))
+			return nil
+		case s.resolver != nil:
+			resolved, err := s.resolver.ResolveType(column.DBType, b)
+			if err != nil {
+				return err
+			}
+			if _, ok := resolved.([]byte); !ok {
+				// Resolved values like Decimal are Valuers, assigned as a driver value would be
+				return s.assign(target, resolved, column)
+			}
+			value = resolved
+		default:
+			value = string(b)
+		}
+	}
+
+	source := reflect.ValueOf(value)
+	if source.Type().AssignableTo(target.Type()) {
+		target.Set(source)
+		return nil
+	}
+	if text, ok := value.(string); ok {
+		return assignString(target, text)
+	}
+	return assignConverted(target, source)
+}
+
+// assignString parses text into the numeric, boolean or time target.
+func assignString(target reflect.Value, text string) error {
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(text, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(text, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(text, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		target.SetBool(parsed)
+	case reflect.String:
+		target.SetString(text)
+	default:
+		if target.Type() == timeType {
+			parsed, err := parseTime(text)
+			if err != nil {
+				return err
+			}
+			target.Set(reflect.ValueOf(parsed))
+			return nil
+		}
+		return fmt.Errorf("cannot scan string into %v", target.Type())
+	}
+	return nil
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+func parseTime(text string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as a time", text)
+}
+
+// assignConverted converts between numeric kinds, failing on overflow, and formats
+// numbers, booleans and times into strings.
+func assignConverted(target, source reflect.Value) error {
+	switch {
+	case target.Kind() == reflect.String:
+		switch value := source.Interface().(type) {
+		case time.Time:
+			target.SetString(value.Format(time.RFC3339Nano))
+		case bool:
+			target.SetString(strconv.FormatBool(value))
+		default:
+			if !source.CanInt() && !source.CanUint() && !source.CanFloat() {
+				return fmt.Errorf("cannot scan %v into %v", source.Type(), target.Type())
+			}
+			target.SetString(fmt.Sprint(value))
+		}
+		return nil
+	case target.CanInt() && source.CanInt():
+		if target.OverflowInt(source.Int()) {
+			return fmt.Errorf("%d overflows %v", source.Int(), target.Type())
+		}
+		target.SetInt(source.Int())
+		return nil
+	case target.CanInt() && source.CanUint():
+		if source.Uint() > 1<<63-1 || target.OverflowInt(int64(source.Uint())) {
+			return fmt.Errorf("%d overflows %v", source.Uint(), target.Type())
+		}
+		target.SetInt(int64(source.Uint()))
+		return nil
+	case target.CanUint() && source.CanInt():
+		if source.Int() < 0 || target.OverflowUint(uint64(source.Int())) {
+			return fmt.Errorf("%d overflows %v", source.Int(), target.Type())
+		}
+		target.SetUint(uint64(source.Int()))
+		return nil
+	case target.CanUint() && source.CanUint():
+		if target.OverflowUint(source.Uint()) {
+			return fmt.Errorf("%d overflows %v", source.Uint(), target.Type())
+		}
+		target.SetUint(source.Uint())
+		return nil
+	case target.CanFloat() && (source.CanInt() || source.CanUint() || source.CanFloat()):
+		target.Set(source.Convert(target.Type()))
+		return nil
+	case target.Kind() == reflect.Bool && source.CanInt():
+		target.SetBool(source.Int() != 0)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %v into %v", source.Type(), target.Type())
+	}
+}
+
+// asRows returns the values of the list as the rows of a single column.
+func (l List) asRows() ([]*Column, []*Row) {
+	column := &Column{Name: l.DBField, ScanType: l.ScanType, DBType: l.DBTypeName}
+	rows := make([]*Row, len(l.Values))
+	for i, value := range l.Values {
+		rows[i] = &Row{Values: []any{value}}
+	}
+	return []*Column{column}, rows
+}
@@ -0,0 +1,55 @@
+package result_test
+
+import (
+	"testing"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/mysql"
+	"github.com/ctrl-alt-boop/dribble/internal/adapters/sql/postgres"
+	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/ctrl-alt-boop/dribble/result"
+)
+
+func TestScanResolvedDecimal(t *testing.T) {
+	for name, test := range map[string]struct {
+		resolver datasource.SQLAdapter
+		dbType   string
+	}{
+		"postgres": {postgres.New(nil).(*postgres.Postgres), "NUMERIC"},
+		"mysql":    {mysql.New(nil).(*mysql.MySQL), "DECIMAL"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			response := func(value string) *request.Response {
+				table := result.NewTable(
+					[]*result.Column{{Name: "price", DBType: test.dbType}},
+					[]*result.Row{{Values: []any{[]byte(value)}}},
+				)
+				table.Resolver = test.resolver
+				return &request.Response{Body: table}
+			}
+
+			prices, err := result.ScanAll[struct{ Price float64 }](response("12.50"))
+			if err != nil || len(prices) != 1 || prices[0].Price != 12.5 {
+				t.Errorf("struct float64 = %v, %v", prices, err)
+			}
+			if price, err := result.ScanOne[float64](response("12.50")); err != nil || price != 12.5 {
+				t.Errorf("float64 = %v, %v", price, err)
+			}
+			if price, err := result.ScanOne[int64](response("-12")); err != nil || price != -12 {
+				t.Errorf("int64 = %v, %v", price, err)
+			}
+			if _, err := result.ScanOne[int64](response("12.50")); err == nil {
+				t.Error("12.50 was scanned into an int64")
+			}
+			if price, err := result.ScanOne[string](response("12.50")); err != nil || price != "12.50" {
+				t.Errorf("string = %q, %v", price, err)
+			}
+			if price, err := result.ScanOne[result.Decimal](response("12.50")); err != nil || price != "12.50" {
+				t.Errorf("Decimal = %q, %v", price, err)
+			}
+			if price, err := result.ScanOne[*float64](response("0.25")); err != nil || price == nil || *price != 0.25 {
+				t.Errorf("*float64 = %v, %v", price, err)
+			}
+		})
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
-	"time"
+	"unicode/utf8"
 
 	"github.com/ctrl-alt-boop/dribble/datasource"
 )
@@ -24,10 +24,23 @@ type (
 		columns []*Column
 		rows    []*Row
 
-		Resolver datasource.SQLAdapter
+		Resolver  datasource.SQLAdapter
+		Formatter *Formatter
 	}
 )
 
+// IsBinary reports whether value, a value of the column, is binary data rather than text,
+// which drivers like MySQL's return as bytes too.
+func (c *Column) IsBinary(value []byte) bool {
+	dbType := strings.ToUpper(c.DBType)
+	for _, binaryType := range []string{"BLOB", "BINARY", "BYTEA"} {
+		if strings.Contains(dbType, binaryType) {
+			return true
+		}
+	}
+	return !utf8.Valid(value)
+}
+
 func (r Row) String() string {
 	return strings.Join(sliceTransform(r.Values, func(value any) string {
 		return fmt.Sprint(value)
@@ -94,30 +107,31 @@ func NewTable(columns []*Column, rows []*Row) *Table {
 	}
 }
 
-func (dt *Table) GetRowColumn(row, column int) (string, error) { // Needs a lookielook to see if other drivers are at least similar to this
-	rowColumn := dt.rows[row].Values[column]
-	switch value := rowColumn.(type) {
-	case string, int, int32, int64, float32, float64, uint, bool:
-		return fmt.Sprint(value), nil
-	case time.Time:
-		return fmt.Sprint(value.Format("2006-01-02 15:04:05.000000-07")), nil
-	case []byte:
-		resolved, err := dt.Resolver.ResolveType(dt.columns[column].DBType, value)
-		if err != nil {
-			return "", err
-		} else {
-			return fmt.Sprintf("%v", resolved), nil
-		}
-	case nil:
-		return "null", nil
-	default:
-		err := fmt.Errorf("unknown value type %T for %s", value, dt.columns[column].DBType)
+// GetRowValue returns the value at row and column, decoding the bytes drivers leave undecoded
+// with the Resolver.
+func (dt *Table) GetRowValue(row, column int) (any, error) {
+	return ResolveTypes(dt.Resolver, dt.rows[row].Values[column], *dt.columns[column])
+}
+
+// GetRowColumn returns the value at row and column as text, formatted by the Formatter
+// of the table or DefaultFormatter.
+func (dt *Table) GetRowColumn(row, column int) (string, error) {
+	value, err := dt.GetRowValue(row, column)
+	if err != nil {
 		return "", err
 	}
+	return dt.formatter().Format(dt.columns[column], value), nil
+}
+
+func (dt *Table) formatter() *Formatter {
+	if dt.Formatter != nil {
+		return dt.Formatter
+	}
+	return DefaultFormatter
 }
 
 func (dt *Table) GetRowStringsAll() [][]string {
-	rows := make([][]string, len(dt.columns))
+	rows := make([][]string, dt.NumRows())
 	for i := range dt.NumRows() {
 		rows[i] = dt.GetRowStrings(i)
 	}
@@ -0,0 +1,93 @@
+package result
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	_ driver.Valuer = Decimal("")
+	_ driver.Valuer = Interval{}
+)
+
+// Decimal is an exact numeric value as its database wrote it, e.g. "-12.50" or "NaN".
+type Decimal string
+
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// Rat returns the value as a rational, false for NaN and the infinities.
+func (d Decimal) Rat() (*big.Rat, bool) {
+	return new(big.Rat).SetString(string(d))
+}
+
+// Float64 returns the nearest float64, which may lose precision.
+func (d Decimal) Float64() (float64, error) {
+	return strconv.ParseFloat(string(d), 64)
+}
+
+// MarshalJSON writes the value as a JSON number, keeping every digit, or as a string
+// when it is not finite.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if _, finite := d.Rat(); !finite {
+		return []byte(strconv.Quote(string(d))), nil
+	}
+	return []byte(d), nil
+}
+
+// Value implements driver.Valuer.
+func (d Decimal) Value() (driver.Value, error) {
+	return string(d), nil
+}
+
+// Interval is a PostgreSQL interval. Months and days are kept apart from the time of day,
+// as their lengths vary.
+type Interval struct {
+	Months       int32
+	Days         int32
+	Microseconds int64
+}
+
+// String formats the interval as PostgreSQL does by default, e.g. "1 year 2 mons 3 days 04:05:06.5".
+func (i Interval) String() string {
+	var parts []string
+	years, months := i.Months/12, i.Months%12
+	if years != 0 {
+		parts = append(parts, plural(int64(years), "year"))
+	}
+	if months != 0 {
+		parts = append(parts, plural(int64(months), "mon"))
+	}
+	if i.Days != 0 {
+		parts = append(parts, plural(int64(i.Days), "day"))
+	}
+	if i.Microseconds != 0 || len(parts) == 0 {
+		sign, micros := "", i.Microseconds
+		if micros < 0 {
+			sign, micros = "-", -micros
+		}
+		seconds := micros / 1e6
+		clock := fmt.Sprintf("%s%02d:%02d:%02d", sign, seconds/3600, seconds/60%60, seconds%60)
+		if fraction := micros % 1e6; fraction != 0 {
+			clock += strings.TrimRight(fmt.Sprintf(".%06d", fraction), "0")
+		}
+		parts = append(parts, clock)
+	}
+	return strings.Join(parts, " ")
+}
+
+func plural(n int64, unit string) string {
+	if n == 1 || n == -1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// Value implements driver.Valuer.
+func (i Interval) Value() (driver.Value, error) {
+	return i.String(), nil
+}
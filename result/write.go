@@ -0,0 +1,44 @@
+package result
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var _ Body = (*Write)(nil)
+
+// Write is the body of an insert, update or delete, and of statements run as they are.
+type Write struct {
+	// RowsAffected is -1 when the driver does not report it.
+	RowsAffected int64
+	// LastInsertID is only valid for inserts on drivers that report it, which postgres does not,
+	// use RETURNING there instead.
+	LastInsertID sql.Null[int64]
+	// Returning holds the rows of a RETURNING clause, nil without one.
+	Returning *Table
+	// Statement and Args are what was run, empty for bulk inserts as those run several statements.
+	Statement string
+	Args      []any
+	Elapsed   time.Duration
+}
+
+// String implements Body.
+func (w Write) String() string {
+	text := fmt.Sprintf("%d rows affected in %v", w.RowsAffected, w.Elapsed)
+	if w.LastInsertID.Valid {
+		text += fmt.Sprintf(", last insert id %d", w.LastInsertID.V)
+	}
+	if w.Returning != nil {
+		text += "\n" + strings.Join(sliceTransform(w.Returning.rows, func(row *Row) string {
+			return row.String()
+		}), "\n")
+	}
+	return text
+}
+
+// Get implements Body.
+func (w Write) Get() any {
+	return w
+}
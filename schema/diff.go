@@ -0,0 +1,378 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+	"github.com/ctrl-alt-boop/dribble/target"
+)
+
+type (
+	// Requester sends requests to named targets, like *dribble.Client.
+	Requester interface {
+		Request(ctx context.Context, targetName string, req datasource.Request) (chan *request.Response, error)
+	}
+
+	ChangeKind string
+	ObjectKind string
+
+	// Change is a difference between the schema of target A and that of target B, read as what
+	// turns A into B: an object only B has is added, and a changed Property goes From A To B.
+	Change struct {
+		Kind   ChangeKind
+		Object ObjectKind
+		Table  string
+		Name   string // The name of the column, index or foreign key, empty for tables and primary keys
+
+		Property string
+		From, To string
+	}
+
+	// Report is the result of Diff, its String is the human-readable form of the changes.
+	Report struct {
+		TargetA, TargetB string
+		Changes          []Change
+	}
+
+	DiffOption func(*diffOptions)
+
+	// requestFunc sends a request to one of the compared targets.
+	requestFunc func(ctx context.Context, req datasource.Request) (chan *request.Response, error)
+
+	diffOptions struct {
+		databaseA, databaseB string
+		tables               []string
+	}
+)
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+const (
+	ObjectTable      ObjectKind = "table"
+	ObjectColumn     ObjectKind = "column"
+	ObjectIndex      ObjectKind = "index"
+	ObjectForeignKey ObjectKind = "foreign key"
+	ObjectPrimaryKey ObjectKind = "primary key"
+)
+
+// WithDatabases sets the databases, or schemas, to compare. By default both targets are read
+// from the database their connection uses.
+func WithDatabases(databaseA, databaseB string) DiffOption {
+	return func(o *diffOptions) {
+		o.databaseA, o.databaseB = databaseA, databaseB
+	}
+}
+
+// WithTables limits the comparison to the named tables.
+func WithTables(tableNames ...string) DiffOption {
+	return func(o *diffOptions) {
+		o.tables = tableNames
+	}
+}
+
+// Diff reads the schemas of the targets named targetA and targetB through client and compares them.
+func Diff(ctx context.Context, client Requester, targetA, targetB string, opts ...DiffOption) (*Report, error) {
+	return diff(ctx, targetA, targetB,
+		func(ctx context.Context, req datasource.Request) (chan *request.Response, error) {
+			return client.Request(ctx, targetA, req)
+		},
+		func(ctx context.Context, req datasource.Request) (chan *request.Response, error) {
+			return client.Request(ctx, targetB, req)
+		}, opts...)
+}
+
+// DiffTargets reads the schemas of targetA and targetB and compares them.
+func DiffTargets(ctx context.Context, targetA, targetB *target.Target, opts ...DiffOption) (*Report, error) {
+	return diff(ctx, targetA.Name, targetB.Name, targetA.Request, targetB.Request, opts...)
+}
+
+func diff(ctx context.Context, targetA, targetB string, requestA, requestB requestFunc, opts ...DiffOption) (*Report, error) {
+	options := &diffOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	a, err := readDatabase(ctx, requestA, targetA, options.databaseA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := readDatabase(ctx, requestB, targetB, options.databaseB)
+	if err != nil {
+		return nil, err
+	}
+	if len(options.tables) > 0 {
+		a.Tables = filterTables(a.Tables, options.tables)
+		b.Tables = filterTables(b.Tables, options.tables)
+	}
+
+	return &Report{
+		TargetA: targetA,
+		TargetB: targetB,
+		Changes: Compare(a, b),
+	}, nil
+}
+
+func readDatabase(ctx context.Context, send requestFunc, targetName, databaseName string) (*Database, error) {
+	responses, err := send(ctx, request.NewReadDatabaseSchema(databaseName))
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema of %s: %w", targetName, err)
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case response, ok := <-responses:
+		if !ok {
+			return nil, fmt.Errorf("error reading schema of %s: no response", targetName)
+		}
+		if response.Error != nil {
+			return nil, fmt.Errorf("error reading schema of %s: %w", targetName, response.Error)
+		}
+		database, ok := response.Body.(*Database)
+		if !ok {
+			return nil, fmt.Errorf("error reading schema of %s: unexpected response %T", targetName, response.Body)
+		}
+		return database, nil
+	}
+}
+
+func filterTables(tables Tables, names []string) Tables {
+	var filtered Tables
+	for _, table := range tables {
+		if slices.Contains(names, table.Name) {
+			filtered = append(filtered, table)
+		}
+	}
+	return filtered
+}
+
+// Compare returns the changes that turn the tables of a into those of b, ordered by table.
+// Foreign keys are matched by their columns rather than by name, as SQLite does not name them.
+func Compare(a, b *Database) []Change {
+	var changes []Change
+	tablesA, tablesB := a.Tables.AsMap(), b.Tables.AsMap()
+	for _, name := range unionNames(a.Tables, b.Tables) {
+		tableA, tableB := tablesA[name], tablesB[name]
+		switch {
+		case tableA == nil:
+			changes = append(changes, Change{Kind: Added, Object: ObjectTable, Table: name})
+		case tableB == nil:
+			changes = append(changes, Change{Kind: Removed, Object: ObjectTable, Table: name})
+		default:
+			changes = append(changes, compareColumns(tableA, tableB)...)
+			changes = append(changes, comparePrimaryKeys(tableA, tableB)...)
+			changes = append(changes, compareIndexes(tableA, tableB)...)
+			changes = append(changes, compareForeignKeys(tableA, tableB)...)
+		}
+	}
+	return changes
+}
+
+func compareColumns(a, b *Table) []Change {
+	var changes []Change
+	fieldsA, fieldsB := a.Fields.AsMap(), b.Fields.AsMap()
+	for _, name := range unionNames(a.Fields, b.Fields) {
+		fieldA, fieldB := fieldsA[name], fieldsB[name]
+		change := Change{Object: ObjectColumn, Table: a.Name, Name: name}
+		switch {
+		case fieldA == nil:
+			change.Kind = Added
+			changes = append(changes, change)
+		case fieldB == nil:
+			change.Kind = Removed
+			changes = append(changes, change)
+		default:
+			change.Kind = Changed
+			if from, to := fieldA.Properties.TypeString(), fieldB.Properties.TypeString(); !strings.EqualFold(from, to) {
+				changes = append(changes, change.with("type", from, to))
+			}
+			if fieldA.Properties.Nullable != fieldB.Properties.Nullable {
+				changes = append(changes, change.with("nullable", fmt.Sprint(fieldA.Properties.Nullable), fmt.Sprint(fieldB.Properties.Nullable)))
+			}
+			if from, to := derefOrEmpty(fieldA.Properties.Default), derefOrEmpty(fieldB.Properties.Default); from != to {
+				changes = append(changes, change.with("default", from, to))
+			}
+		}
+	}
+	return changes
+}
+
+func compareIndexes(a, b *Table) []Change {
+	var changes []Change
+	indexesA, indexesB := CollectionToMap(a.Properties.Indexes), CollectionToMap(b.Properties.Indexes)
+	for _, name := range unionNames(a.Properties.Indexes, b.Properties.Indexes) {
+		indexA, indexB := indexesA[name], indexesB[name]
+		change := Change{Object: ObjectIndex, Table: a.Name, Name: name}
+		switch {
+		case indexA == nil:
+			change.Kind = Added
+		case indexB == nil:
+			change.Kind = Removed
+		case indexA.definition() != indexB.definition():
+			change.Kind = Changed
+			change.Property, change.From, change.To = "definition", indexA.definition(), indexB.definition()
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func comparePrimaryKeys(a, b *Table) []Change {
+	from, to := columnList(a.Properties.PrimaryKeys), columnList(b.Properties.PrimaryKeys)
+	change := Change{Object: ObjectPrimaryKey, Table: a.Name}
+	switch {
+	case from == to:
+		return nil
+	case from == "":
+		change.Kind, change.To = Added, to
+	case to == "":
+		change.Kind, change.From = Removed, from
+	default:
+		change = change.with("columns", from, to)
+		change.Kind = Changed
+	}
+	return []Change{change}
+}
+
+func compareForeignKeys(a, b *Table) []Change {
+	var changes []Change
+	// Keys on the same columns are paired in order.
+	foreignKeysB := map[string][]*ForeignKey{}
+	for _, foreignKey := range b.Properties.ForeignKeys {
+		columns := columnList(foreignKey.Fields)
+		foreignKeysB[columns] = append(foreignKeysB[columns], foreignKey)
+	}
+	for _, foreignKeyA := range a.Properties.ForeignKeys {
+		columns := columnList(foreignKeyA.Fields)
+		if len(foreignKeysB[columns]) == 0 {
+			changes = append(changes, Change{Kind: Removed, Object: ObjectForeignKey, Table: a.Name, Name: foreignKeyA.Name, From: foreignKeyA.reference()})
+			continue
+		}
+		foreignKeyB := foreignKeysB[columns][0]
+		foreignKeysB[columns] = foreignKeysB[columns][1:]
+		for _, property := range []struct{ name, from, to string }{
+			{"references", foreignKeyA.reference(), foreignKeyB.reference()},
+			{"on update", foreignKeyA.OnUpdate, foreignKeyB.OnUpdate},
+			{"on delete", foreignKeyA.OnDelete, foreignKeyB.OnDelete},
+		} {
+			if !strings.EqualFold(property.from, property.to) {
+				changes = append(changes, Change{Kind: Changed, Object: ObjectForeignKey, Table: a.Name, Name: foreignKeyB.Name,
+					Property: property.name, From: property.from, To: property.to})
+			}
+		}
+	}
+	for _, foreignKeyB := range b.Properties.ForeignKeys {
+		if unmatched := foreignKeysB[columnList(foreignKeyB.Fields)]; slices.Contains(unmatched, foreignKeyB) {
+			changes = append(changes, Change{Kind: Added, Object: ObjectForeignKey, Table: a.Name, Name: foreignKeyB.Name, To: foreignKeyB.reference()})
+		}
+	}
+	return changes
+}
+
+// unionNames returns the names of both collections, sorted.
+func unionNames[T Namer](a, b []T) []string {
+	names := make([]string, 0, len(a)+len(b))
+	for _, item := range a {
+		names = append(names, item.GetName())
+	}
+	for _, item := range b {
+		names = append(names, item.GetName())
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func derefOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// TypeString returns the type with its length, or precision and scale, e.g. "varchar(255)".
+func (p FieldProperties) TypeString() string {
+	switch {
+	case p.Length != nil:
+		return fmt.Sprintf("%s(%d)", p.Type, *p.Length)
+	case p.Precision != nil && p.Scale != nil:
+		return fmt.Sprintf("%s(%d,%d)", p.Type, *p.Precision, *p.Scale)
+	case p.Precision != nil:
+		return fmt.Sprintf("%s(%d)", p.Type, *p.Precision)
+	default:
+		return p.Type
+	}
+}
+
+// columnList returns "(a, b)" for columns, empty for none.
+func columnList(columns []string) string {
+	if len(columns) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(%s)", strings.Join(columns, ", "))
+}
+
+func (i *Index) definition() string {
+	definition := fmt.Sprintf("(%s)", strings.Join(i.Fields, ", "))
+	if i.Unique {
+		definition = "UNIQUE " + definition
+	}
+	return definition
+}
+
+func (f *ForeignKey) reference() string {
+	return fmt.Sprintf("(%s) REFERENCES %s (%s)", strings.Join(f.Fields, ", "), f.ToTable, strings.Join(f.ToFields, ", "))
+}
+
+func (c Change) with(property, from, to string) Change {
+	c.Property, c.From, c.To = property, from, to
+	return c
+}
+
+func (c Change) String() string {
+	subject := fmt.Sprintf("%s %s", c.Object, c.Table)
+	if c.Name != "" {
+		subject = fmt.Sprintf("%s %s.%s", c.Object, c.Table, c.Name)
+	}
+	switch c.Kind {
+	case Added:
+		return strings.TrimSpace(fmt.Sprintf("+ %s %s", subject, c.To))
+	case Removed:
+		return strings.TrimSpace(fmt.Sprintf("- %s %s", subject, c.From))
+	default:
+		return fmt.Sprintf("~ %s %s: %s -> %s", subject, c.Property, orNone(c.From), orNone(c.To))
+	}
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// Empty reports whether the schemas are the same.
+func (r *Report) Empty() bool {
+	return len(r.Changes) == 0
+}
+
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", r.TargetA, r.TargetB)
+	if r.Empty() {
+		sb.WriteString("no differences\n")
+	}
+	for _, change := range r.Changes {
+		sb.WriteString(change.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
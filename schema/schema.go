@@ -0,0 +1,302 @@
+// Package schema models the structure of a data source: its databases, tables, views and routines.
+package schema
+
+import "errors"
+
+// ErrTableNotFound is returned when reading the schema of a table that does not exist.
+var ErrTableNotFound = errors.New("table not found")
+
+type (
+	Server struct { // This needs going over.
+		Name string
+
+		Engine    *Engine
+		Databases Databases
+
+		MaxConnections int
+		Timeout        int
+		SecurityModel  string
+	}
+
+	Engine struct {
+		Name             string
+		BuiltinFunctions []*Function
+
+		Properties EngineProperties
+	}
+
+	EngineProperties struct { // This needs going over.
+		Version string
+
+		TransactionSupport   []string
+		ConcurrencyControl   string
+		RecoveryModel        string
+		StorageModel         string
+		IntegrityEnforcement string
+
+		ReplicationCapabilities []string
+		IndexingCapabilities    []string
+		Caching                 []string
+	}
+
+	Database struct {
+		Name        string
+		Description string
+
+		Engine *Engine
+
+		Tables     Tables
+		Views      Views
+		Procedures Functions
+		Triggers   Triggers
+		Roles      Roles
+
+		Properties DatabaseProperties
+	}
+
+	DatabaseProperties struct { // This needs going over.
+		CharacterSet string
+		Collation    string
+		Locale       string
+
+		ConnectionLimit int
+		Owner           string
+
+		DefaultStorageEngine string
+		DefaultSchema        string
+		DefaultNamespace     string
+
+		SecurityModel                 string
+		RecoveryModel, BackupStrategy string
+	}
+
+	Table struct {
+		Name        string
+		Description string
+
+		Engine *Engine
+
+		Fields Fields
+
+		Properties TableProperties
+	}
+
+	TableProperties struct {
+		// PrimaryKeys are the fields of the primary key, in key order.
+		PrimaryKeys []string
+
+		Indexes     []*Index
+		ForeignKeys []*ForeignKey
+		Constraints []*Constraint
+
+		Partitioned bool
+	}
+
+	Index struct {
+		Name        string
+		Description string
+
+		// Fields are the key columns. PostgreSQL gives an expression in parentheses, as in
+		// (lower(email)), the other dialects leave expressions out.
+		Fields    []string
+		Type      string // B-tree, Hash, etc.
+		Primary   bool
+		Unique    bool
+		Clustered bool
+		Spatial   bool
+	}
+
+	// ForeignKey references the ToFields of ToTable with its Fields, in the same order.
+	ForeignKey struct {
+		Name   string
+		Fields []string
+
+		ToTable  string
+		ToFields []string
+
+		OnUpdate string // CASCADE, SET NULL, RESTRICT, etc.
+		OnDelete string
+	}
+
+	Constraint struct {
+		Name string
+		Type string // PRIMARY KEY, UNIQUE, FOREIGN KEY or CHECK
+
+		Fields     []string
+		Definition string // The definition of a CHECK, as the database reports it
+	}
+
+	Field struct {
+		Name        string
+		Description string
+
+		Properties FieldProperties
+	}
+
+	FieldProperties struct {
+		PrimaryKey bool
+
+		// Type is the type without its length, precision or scale, e.g. "character varying".
+		Type      string
+		Length    *int
+		Precision *int
+		Scale     *int
+
+		Default   *string
+		AutoInc   *string // Auto increment seed, nil if AutoIncrement false
+		Virtual   bool    // Generated on read rather than stored
+		Generated *string // Generation expression, nil for ordinary fields
+
+		Unique        bool
+		Index         bool
+		Nullable      bool
+		AllowedValues []string // enum or set (MySQL) values
+		Encrypted     bool
+		Overrides     Overrides
+	}
+
+	// Overrides are the settings of a field that differ from those of its table.
+	Overrides struct {
+		CharacterSet string
+		Collation    string
+	}
+
+	View struct {
+		Name        string
+		Description string
+
+		Fields Fields
+
+		Properties ViewProperties
+	}
+
+	ViewProperties struct {
+		Definition   string
+		Updatable    bool
+		WithCheck    bool
+		Materialized bool
+	}
+
+	Function struct {
+		Name        string
+		Description string
+
+		Properties FunctionProperties
+	}
+
+	FunctionProperties struct {
+		Definition string
+		Type       string // FUNCTION or PROCEDURE
+		Returns    *string
+		Parameters []Parameter
+	}
+
+	Parameter struct {
+		Name        string
+		Description string
+		Type        string
+		Direction   string // IN, OUT, INOUT
+	}
+
+	Trigger struct {
+		Name        string
+		Description string
+
+		Properties TriggerProperties
+	}
+
+	TriggerProperties struct {
+		Table      string
+		Timing     string // BEFORE, AFTER or INSTEAD OF
+		Event      string // INSERT, UPDATE, DELETE or TRUNCATE, joined with OR
+		Definition string
+	}
+
+	Role struct {
+		Name        string
+		Description string
+
+		Permissions []string
+		Inheritance []string
+		Members     []string
+	}
+
+	Servers   []*Server
+	Engines   []*Engine
+	Databases []*Database
+	Tables    []*Table
+	Views     []*View
+	Roles     []*Role
+	Functions []*Function
+	Triggers  []*Trigger
+	Fields    []*Field
+)
+
+type Namer interface {
+	GetName() string
+}
+
+func (s *Server) GetName() string   { return s.Name }
+func (d *Database) GetName() string { return d.Name }
+func (t *Table) GetName() string    { return t.Name }
+func (i *Index) GetName() string    { return i.Name }
+func (v *View) GetName() string     { return v.Name }
+func (r *Role) GetName() string     { return r.Name }
+func (f *Function) GetName() string { return f.Name }
+func (t *Trigger) GetName() string  { return t.Name }
+func (f *Field) GetName() string    { return f.Name }
+
+func CollectionToMap[T Namer](collection []T) map[string]T {
+	m := make(map[string]T, len(collection))
+	for _, item := range collection {
+		m[item.GetName()] = item
+	}
+	return m
+}
+
+func (s *Server) DatabaseMap() map[string]*Database {
+	return CollectionToMap(s.Databases)
+}
+
+func (d Databases) AsMap() map[string]*Database {
+	return CollectionToMap(d)
+}
+
+func (t Tables) AsMap() map[string]*Table {
+	return CollectionToMap(t)
+}
+
+func (v Views) AsMap() map[string]*View {
+	return CollectionToMap(v)
+}
+
+func (r Roles) AsMap() map[string]*Role {
+	return CollectionToMap(r)
+}
+
+func (f Functions) AsMap() map[string]*Function {
+	return CollectionToMap(f)
+}
+
+func (t Triggers) AsMap() map[string]*Trigger {
+	return CollectionToMap(t)
+}
+
+func (f Fields) AsMap() map[string]*Field {
+	return CollectionToMap(f)
+}
+
+// IsAutoIncrement reports whether values of the field are assigned by the database.
+func (p FieldProperties) IsAutoIncrement() bool {
+	return p.AutoInc != nil
+}
+
+// Field returns the field called name, or nil.
+func (t *Table) Field(name string) *Field {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package sql
+
+type SetOperator string
+
+const (
+	SetUnion        SetOperator = "UNION"
+	SetUnionAll     SetOperator = "UNION ALL"
+	SetIntersect    SetOperator = "INTERSECT"
+	SetIntersectAll SetOperator = "INTERSECT ALL"
+	SetExcept       SetOperator = "EXCEPT"
+	SetExceptAll    SetOperator = "EXCEPT ALL"
+)
+
+type commonTableExpression struct {
+	Name  string
+	Query *SelectQuery
+}
+
+type setOperation struct {
+	Operator SetOperator
+	Query    *SelectQuery
+}
+
+// With defines the common table expression name as query, usable as a table by the select.
+// The name can list the columns of the expression, e.g. "totals(region, total)".
+func (s *SelectBuilder) With(name string, query *SelectBuilder) *SelectBuilder {
+	s.with = append(s.with, cte{name: name, query: query})
+	return s
+}
+
+// WithRecursive is With for an expression that refers to itself,
+// typically an anchor select combined with UnionAll.
+func (s *SelectBuilder) WithRecursive(name string, query *SelectBuilder) *SelectBuilder {
+	s.recursive = true
+	return s.With(name, query)
+}
+
+// Union combines the rows of s and other, without duplicates. The order, limit
+// and offset of s apply to the combined rows.
+func (s *SelectBuilder) Union(other *SelectBuilder) *SelectBuilder {
+	return s.combine(SetUnion, other)
+}
+
+func (s *SelectBuilder) UnionAll(other *SelectBuilder) *SelectBuilder {
+	return s.combine(SetUnionAll, other)
+}
+
+func (s *SelectBuilder) Intersect(other *SelectBuilder) *SelectBuilder {
+	return s.combine(SetIntersect, other)
+}
+
+func (s *SelectBuilder) IntersectAll(other *SelectBuilder) *SelectBuilder {
+	return s.combine(SetIntersectAll, other)
+}
+
+func (s *SelectBuilder) Except(other *SelectBuilder) *SelectBuilder {
+	return s.combine(SetExcept, other)
+}
+
+func (s *SelectBuilder) ExceptAll(other *SelectBuilder) *SelectBuilder {
+	return s.combine(SetExceptAll, other)
+}
+
+func (s *SelectBuilder) combine(operator SetOperator, other *SelectBuilder) *SelectBuilder {
+	s.compounds = append(s.compounds, compound{operator: operator, query: other})
+	return s
+}
+
+// cte and compound keep the builders, so they are only built along with the enclosing select.
+type cte struct {
+	name  string
+	query *SelectBuilder
+}
+
+type compound struct {
+	operator SetOperator
+	query    *SelectBuilder
+}
@@ -0,0 +1,652 @@
+package sql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/schema"
+)
+
+// DDL is the statements creating a set of tables in a dialect. Warnings list what did not carry over
+// exactly, like a time zone dropped from a timestamp, one per column or constraint, as "table.column: reason".
+type DDL struct {
+	Statements []string
+	Warnings   []string
+}
+
+// String returns the statements as a script, each terminated by a semicolon.
+func (d *DDL) String() string {
+	var sb strings.Builder
+	for _, statement := range d.Statements {
+		sb.WriteString(statement)
+		sb.WriteString(";\n")
+	}
+	return sb.String()
+}
+
+// GenerateDDL returns the statements creating tables in dialect, whichever dialect they were read from:
+// a CREATE TABLE for each, then their CREATE INDEX statements, then ALTER TABLE ADD CONSTRAINT for unique,
+// check and foreign key constraints, so the tables can reference each other in any order.
+// SQLite cannot add constraints to an existing table, so for it they are declared in CREATE TABLE.
+// Generation expressions and checks are copied as they are, with a warning as they may not run in
+// dialect, use GenerateDDLFrom when the dialect of the tables is known.
+func GenerateDDL(dialect datasource.SQLDialectType, tables ...*schema.Table) (*DDL, error) {
+	return generateDDL(&ddlGenerator{dialect: dialect, source: -1, ddl: &DDL{}}, tables)
+}
+
+// GenerateDDLFrom is GenerateDDL for tables read from source. When source is not dialect, generated
+// columns are created as ordinary columns and check constraints are dropped, each with a warning,
+// as their expressions are written in source.
+func GenerateDDLFrom(source, dialect datasource.SQLDialectType, tables ...*schema.Table) (*DDL, error) {
+	if !isSupportedDialect(source) {
+		return nil, fmt.Errorf("%w: DDL from dialect %d", ErrUnsupported, source)
+	}
+	return generateDDL(&ddlGenerator{dialect: dialect, source: source, ddl: &DDL{}}, tables)
+}
+
+func generateDDL(g *ddlGenerator, tables []*schema.Table) (*DDL, error) {
+	dialect := g.dialect
+	if !isSupportedDialect(dialect) {
+		return nil, fmt.Errorf("%w: DDL for dialect %d", ErrUnsupported, dialect)
+	}
+	var indexes, constraints []string
+	for _, table := range tables {
+		if len(table.Fields) == 0 {
+			return nil, fmt.Errorf("table %s has no columns", table.Name)
+		}
+		tableConstraints := g.constraints(table)
+		if dialect == datasource.SQLite3 {
+			g.ddl.Statements = append(g.ddl.Statements, g.createTable(table, tableConstraints))
+		} else {
+			g.ddl.Statements = append(g.ddl.Statements, g.createTable(table, nil))
+			for _, constraint := range tableConstraints {
+				constraints = append(constraints, fmt.Sprintf("ALTER TABLE %s ADD %s", g.quote(table.Name), constraint))
+			}
+		}
+		indexes = append(indexes, g.createIndexes(table)...)
+	}
+	g.ddl.Statements = append(g.ddl.Statements, indexes...)
+	g.ddl.Statements = append(g.ddl.Statements, constraints...)
+	return g.ddl, nil
+}
+
+type ddlGenerator struct {
+	dialect datasource.SQLDialectType
+	// source is the dialect the tables were read from, -1 when unknown.
+	source datasource.SQLDialectType
+	ddl    *DDL
+	// columnTypes are the types of the columns of the table being generated.
+	columnTypes map[string]string
+}
+
+func isSupportedDialect(dialect datasource.SQLDialectType) bool {
+	return dialect >= datasource.PostgreSQL && dialect < datasource.NumSupportedSQLDialects
+}
+
+// expression returns whether expressions of the source dialect are kept, warning about them when
+// the source dialect is unknown. When it is known to differ, reason is warned about instead.
+func (g *ddlGenerator) expression(table, name, reason string) bool {
+	switch g.source {
+	case g.dialect:
+		return true
+	case -1:
+		g.warnf(table, name, "expression is copied as is and may not run in %v", g.dialect)
+		return true
+	}
+	g.warnf(table, name, "%s, its expression is written in %v", reason, g.source)
+	return false
+}
+
+func (g *ddlGenerator) warnf(table, name, format string, args ...any) {
+	g.ddl.Warnings = append(g.ddl.Warnings, fmt.Sprintf("%s.%s: %s", table, name, fmt.Sprintf(format, args...)))
+}
+
+func (g *ddlGenerator) quote(name string) string {
+	if g.dialect == datasource.MySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (g *ddlGenerator) quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = g.quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (g *ddlGenerator) createTable(table *schema.Table, constraints []string) string {
+	// SQLite only auto increments a single INTEGER PRIMARY KEY, declared with the column.
+	inlineKey := ""
+	if g.dialect == datasource.SQLite3 && len(table.Properties.PrimaryKeys) == 1 {
+		if key := table.Field(table.Properties.PrimaryKeys[0]); key != nil && isAutoIncrement(key.Properties) {
+			inlineKey = key.Name
+		}
+	}
+
+	g.columnTypes = make(map[string]string, len(table.Fields))
+	definitions := make([]string, 0, len(table.Fields)+len(constraints)+1)
+	for _, field := range table.Fields {
+		definitions = append(definitions, g.column(table, field, field.Name == inlineKey))
+	}
+	if len(table.Properties.PrimaryKeys) > 0 && inlineKey == "" {
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", g.quoteAll(table.Properties.PrimaryKeys)))
+	}
+	definitions = append(definitions, constraints...)
+	return fmt.Sprintf("CREATE TABLE %s (\n\t%s\n)", g.quote(table.Name), strings.Join(definitions, ",\n\t"))
+}
+
+func (g *ddlGenerator) column(table *schema.Table, field *schema.Field, inlineKey bool) string {
+	properties := field.Properties
+	columnType, kind := g.columnType(table, field)
+	g.columnTypes[field.Name] = columnType
+	definition := g.quote(field.Name) + " " + columnType
+
+	autoIncrement := isAutoIncrement(properties)
+	switch {
+	case inlineKey:
+		definition += " PRIMARY KEY AUTOINCREMENT"
+	case autoIncrement && g.dialect == datasource.SQLite3:
+		g.warnf(table.Name, field.Name, "auto increment needs a single INTEGER PRIMARY KEY in SQLite and is dropped")
+	case autoIncrement && g.dialect == datasource.PostgreSQL && !isIntegerKind(kind):
+		g.warnf(table.Name, field.Name, "auto increment of type %s is dropped", properties.Type)
+	}
+
+	generated := properties.Generated != nil
+	if generated {
+		if *properties.Generated == "" {
+			g.warnf(table.Name, field.Name, "generation expression unknown, created as an ordinary column")
+		} else if g.expression(table.Name, field.Name, "created as an ordinary column") {
+			storage := "STORED"
+			if properties.Virtual && g.dialect != datasource.PostgreSQL {
+				storage = "VIRTUAL"
+			} else if properties.Virtual {
+				g.warnf(table.Name, field.Name, "virtual generated column is stored")
+			}
+			definition += fmt.Sprintf(" GENERATED ALWAYS AS (%s) %s", trimParentheses(*properties.Generated), storage)
+		}
+	}
+	if !properties.Nullable && !inlineKey {
+		definition += " NOT NULL"
+	}
+	if properties.Default != nil && !generated && !autoIncrement {
+		if value := g.defaultValue(*properties.Default, kind); value != "" {
+			// MySQL only takes expression defaults for TEXT, BLOB and JSON.
+			if g.dialect == datasource.MySQL && !strings.HasPrefix(value, "(") && isMySQLLargeObject(columnType) {
+				value = "(" + value + ")"
+			}
+			definition += " DEFAULT " + value
+		}
+	}
+	if kind == kindEnum && g.dialect != datasource.MySQL {
+		definition += fmt.Sprintf(" CHECK (%s IN (%s))", g.quote(field.Name), quoteValues(properties.AllowedValues))
+	}
+	if autoIncrement && g.dialect == datasource.MySQL {
+		definition += " AUTO_INCREMENT"
+	}
+	return definition
+}
+
+// constraints returns the unique, check and foreign key constraints of table, as added to it.
+func (g *ddlGenerator) constraints(table *schema.Table) []string {
+	var constraints []string
+	for _, constraint := range table.Properties.Constraints {
+		switch constraint.Type {
+		case "UNIQUE":
+			constraints = append(constraints, fmt.Sprintf("CONSTRAINT %s UNIQUE (%s)",
+				g.quote(constraintName(table, constraint.Name, constraint.Fields, "key")), g.quoteAll(constraint.Fields)))
+		case "CHECK":
+			if !g.expression(table.Name, constraint.Name, "check is dropped") {
+				continue
+			}
+			check := strings.TrimSpace(constraint.Definition)
+			if !strings.HasPrefix(strings.ToUpper(check), "CHECK") {
+				check = fmt.Sprintf("CHECK (%s)", trimParentheses(check))
+			}
+			constraints = append(constraints, fmt.Sprintf("CONSTRAINT %s %s", g.quote(constraint.Name), check))
+		}
+	}
+	for _, foreignKey := range table.Properties.ForeignKeys {
+		constraint := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+			g.quote(constraintName(table, foreignKey.Name, foreignKey.Fields, "fkey")),
+			g.quoteAll(foreignKey.Fields), g.quote(foreignKey.ToTable), g.quoteAll(foreignKey.ToFields))
+		if action := strings.ToUpper(foreignKey.OnUpdate); action != "" && action != "NO ACTION" {
+			constraint += " ON UPDATE " + action
+		}
+		if action := strings.ToUpper(foreignKey.OnDelete); action != "" && action != "NO ACTION" {
+			constraint += " ON DELETE " + action
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints
+}
+
+// createIndexes returns the CREATE INDEX statements of the indexes of table that are not
+// created with its primary key or unique constraints.
+func (g *ddlGenerator) createIndexes(table *schema.Table) []string {
+	constraintNames := map[string]bool{}
+	for _, constraint := range table.Properties.Constraints {
+		constraintNames[constraint.Name] = true
+	}
+
+	var statements []string
+	for _, index := range table.Properties.Indexes {
+		if index.Primary || constraintNames[index.Name] || strings.HasPrefix(index.Name, "sqlite_autoindex_") {
+			continue
+		}
+		unique := ""
+		if index.Unique {
+			unique = "UNIQUE "
+		}
+		if g.dialect == datasource.MySQL {
+			if field, large := g.largeObjectField(index.Fields); large {
+				g.warnf(table.Name, index.Name, "index is dropped, MySQL cannot index the %s column %s as a whole", g.columnTypes[field], field)
+				continue
+			}
+		}
+		method := ""
+		switch strings.ToLower(index.Type) {
+		case "", "btree":
+		default:
+			if g.dialect == datasource.PostgreSQL {
+				method = " USING " + strings.ToLower(index.Type)
+			} else {
+				g.warnf(table.Name, index.Name, "index method %s is dropped", index.Type)
+			}
+		}
+		statements = append(statements, fmt.Sprintf("CREATE %sINDEX %s ON %s%s (%s)",
+			unique, g.quote(index.Name), g.quote(table.Name), method, g.quoteAll(index.Fields)))
+	}
+	return statements
+}
+
+// largeObjectField returns the first of fields whose type MySQL only indexes by a prefix, or not at all.
+func (g *ddlGenerator) largeObjectField(fields []string) (string, bool) {
+	for _, field := range fields {
+		if isMySQLLargeObject(g.columnTypes[field]) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// constraintName returns name, or one made up like PostgreSQL does for the names SQLite makes up.
+func constraintName(table *schema.Table, name string, fields []string, suffix string) string {
+	if name != "" && !strings.HasPrefix(name, "sqlite_autoindex_") && !sqliteForeignKeyName.MatchString(name) {
+		return name
+	}
+	return fmt.Sprintf("%s_%s_%s", table.Name, strings.Join(fields, "_"), suffix)
+}
+
+var sqliteForeignKeyName = regexp.MustCompile(`^fk_\d+$`)
+
+type typeKind int
+
+const (
+	kindUnknown typeKind = iota
+	kindSmallInt
+	kindInteger
+	kindBigInt
+	kindBoolean
+	kindReal
+	kindDouble
+	kindDecimal
+	kindVarchar
+	kindChar
+	kindText
+	kindBlob
+	kindDate
+	kindTime
+	kindTimestamp
+	kindTimestampTZ
+	kindJSON
+	kindUUID
+	kindEnum
+	kindSet
+	kindArray
+)
+
+// typeKinds are the type names of PostgreSQL, MySQL and SQLite3, as introspected or declared.
+var typeKinds = map[string]typeKind{
+	"smallint": kindSmallInt, "int2": kindSmallInt, "smallserial": kindSmallInt, "tinyint": kindSmallInt,
+	"integer": kindInteger, "int": kindInteger, "int4": kindInteger, "serial": kindInteger, "mediumint": kindInteger,
+	"bigint": kindBigInt, "int8": kindBigInt, "bigserial": kindBigInt,
+	"boolean": kindBoolean, "bool": kindBoolean,
+	"real": kindReal, "float4": kindReal, "float": kindReal,
+	"double precision": kindDouble, "double": kindDouble, "float8": kindDouble,
+	"numeric": kindDecimal, "decimal": kindDecimal,
+	"character varying": kindVarchar, "varchar": kindVarchar, "nvarchar": kindVarchar,
+	"character": kindChar, "char": kindChar, "bpchar": kindChar, "nchar": kindChar,
+	"text": kindText, "tinytext": kindText, "mediumtext": kindText, "longtext": kindText, "clob": kindText,
+	"bytea": kindBlob, "blob": kindBlob, "tinyblob": kindBlob, "mediumblob": kindBlob, "longblob": kindBlob,
+	"binary": kindBlob, "varbinary": kindBlob,
+	"date": kindDate,
+	"time": kindTime, "time without time zone": kindTime,
+	"timestamp": kindTimestamp, "timestamp without time zone": kindTimestamp, "datetime": kindTimestamp,
+	"timestamptz": kindTimestampTZ, "timestamp with time zone": kindTimestampTZ,
+	"json": kindJSON, "jsonb": kindJSON,
+	"uuid": kindUUID,
+	"enum": kindEnum,
+	"set":  kindSet,
+}
+
+// columnType maps the type of field to dialect, warning about what is lost on the way.
+func (g *ddlGenerator) columnType(table *schema.Table, field *schema.Field) (string, typeKind) {
+	properties := field.Properties
+	typeName := strings.ToLower(strings.Join(strings.Fields(properties.Type), " "))
+	typeName = strings.TrimSuffix(typeName, " zerofill")
+	typeName, unsigned := strings.CutSuffix(typeName, " unsigned")
+
+	kind, known := typeKinds[typeName]
+	switch {
+	case strings.HasPrefix(typeName, "_") || strings.HasSuffix(typeName, "[]"):
+		kind = kindArray
+	case typeName == "tinyint" && properties.Length != nil && *properties.Length == 1:
+		kind = kindBoolean
+	case !known && len(properties.AllowedValues) > 0:
+		// An enum type of PostgreSQL, named by its user.
+		kind = kindEnum
+	}
+
+	if unsigned && g.dialect != datasource.MySQL {
+		switch kind {
+		case kindSmallInt:
+			kind = kindInteger
+		case kindInteger:
+			kind = kindBigInt
+		case kindBigInt:
+			g.warnf(table.Name, field.Name, "unsigned values above 9223372036854775807 do not fit in a signed BIGINT")
+		}
+	}
+
+	switch g.dialect {
+	case datasource.PostgreSQL:
+		return g.postgresType(table, field, kind), kind
+	case datasource.MySQL:
+		columnType := g.mysqlType(table, field, kind)
+		if unsigned && isIntegerKind(kind) {
+			columnType += " UNSIGNED"
+		}
+		return columnType, kind
+	default:
+		return g.sqliteType(table, field, kind), kind
+	}
+}
+
+func (g *ddlGenerator) postgresType(table *schema.Table, field *schema.Field, kind typeKind) string {
+	properties := field.Properties
+	autoIncrement := isAutoIncrement(properties)
+	switch kind {
+	case kindSmallInt:
+		return ifElse(autoIncrement, "smallserial", "smallint")
+	case kindInteger:
+		return ifElse(autoIncrement, "serial", "integer")
+	case kindBigInt:
+		return ifElse(autoIncrement, "bigserial", "bigint")
+	case kindBoolean:
+		return "boolean"
+	case kindReal:
+		return "real"
+	case kindDouble:
+		return "double precision"
+	case kindDecimal:
+		return "numeric" + precisionScale(properties)
+	case kindVarchar:
+		return ifElse(properties.Length != nil, "varchar"+length(properties), "text")
+	case kindChar:
+		return "char" + length(properties)
+	case kindText:
+		return "text"
+	case kindBlob:
+		return "bytea"
+	case kindDate:
+		return "date"
+	case kindTime:
+		return "time"
+	case kindTimestamp:
+		return "timestamp"
+	case kindTimestampTZ:
+		return "timestamptz"
+	case kindJSON:
+		return "jsonb"
+	case kindUUID:
+		return "uuid"
+	case kindEnum:
+		return "text"
+	case kindSet:
+		g.warnf(table.Name, field.Name, "set values are stored as text and not checked")
+		return "text"
+	case kindArray:
+		if element, isArray := strings.CutPrefix(properties.Type, "_"); isArray {
+			return element + "[]"
+		}
+		return properties.Type
+	default:
+		return g.unknownType(table, field)
+	}
+}
+
+func (g *ddlGenerator) mysqlType(table *schema.Table, field *schema.Field, kind typeKind) string {
+	properties := field.Properties
+	switch kind {
+	case kindSmallInt:
+		return "SMALLINT"
+	case kindInteger:
+		return "INT"
+	case kindBigInt:
+		return "BIGINT"
+	case kindBoolean:
+		return "BOOLEAN"
+	case kindReal:
+		return "FLOAT"
+	case kindDouble:
+		return "DOUBLE"
+	case kindDecimal:
+		return "DECIMAL" + precisionScale(properties)
+	case kindVarchar:
+		return ifElse(properties.Length != nil, "VARCHAR"+length(properties), "LONGTEXT")
+	case kindChar:
+		return "CHAR" + length(properties)
+	case kindText:
+		switch typeName := strings.ToUpper(properties.Type); typeName {
+		case "TINYTEXT", "MEDIUMTEXT":
+			return typeName
+		}
+		// TEXT holds 64KB, the text of PostgreSQL and SQLite is unbounded.
+		return "LONGTEXT"
+	case kindBlob:
+		switch typeName := strings.ToUpper(properties.Type); typeName {
+		case "BINARY", "VARBINARY":
+			return typeName + length(properties)
+		case "TINYBLOB", "MEDIUMBLOB":
+			return typeName
+		}
+		return "LONGBLOB"
+	case kindDate:
+		return "DATE"
+	case kindTime:
+		return "TIME"
+	case kindTimestamp:
+		return "DATETIME"
+	case kindTimestampTZ:
+		g.warnf(table.Name, field.Name, "time zone is dropped, DATETIME has none")
+		return "DATETIME"
+	case kindJSON:
+		return "JSON"
+	case kindUUID:
+		return "CHAR(36)"
+	case kindEnum:
+		return fmt.Sprintf("ENUM(%s)", quoteValues(properties.AllowedValues))
+	case kindSet:
+		return fmt.Sprintf("SET(%s)", quoteValues(properties.AllowedValues))
+	case kindArray:
+		g.warnf(table.Name, field.Name, "array of %s is stored as JSON", strings.TrimPrefix(properties.Type, "_"))
+		return "JSON"
+	default:
+		return g.unknownType(table, field)
+	}
+}
+
+func (g *ddlGenerator) sqliteType(table *schema.Table, field *schema.Field, kind typeKind) string {
+	properties := field.Properties
+	switch kind {
+	case kindSmallInt, kindInteger, kindBigInt:
+		return "INTEGER"
+	case kindBoolean:
+		return "BOOLEAN"
+	case kindReal, kindDouble:
+		return "REAL"
+	case kindDecimal:
+		g.warnf(table.Name, field.Name, "decimal values may be stored as REAL and lose precision")
+		return "NUMERIC" + precisionScale(properties)
+	case kindVarchar:
+		return ifElse(properties.Length != nil, "VARCHAR"+length(properties), "TEXT")
+	case kindChar:
+		return "CHAR" + length(properties)
+	case kindText:
+		return "TEXT"
+	case kindJSON:
+		g.warnf(table.Name, field.Name, "JSON is stored as text and not checked, SQLite has no JSON type")
+		return "TEXT"
+	case kindBlob:
+		return "BLOB"
+	case kindDate:
+		return "DATE"
+	case kindTime:
+		return "TIME"
+	case kindTimestamp:
+		return "TIMESTAMP"
+	case kindTimestampTZ:
+		g.warnf(table.Name, field.Name, "time zone is dropped, TIMESTAMP has none")
+		return "TIMESTAMP"
+	case kindUUID:
+		return "CHAR(36)"
+	case kindEnum:
+		return "TEXT"
+	case kindSet:
+		g.warnf(table.Name, field.Name, "set values are stored as text and not checked")
+		return "TEXT"
+	case kindArray:
+		g.warnf(table.Name, field.Name, "array of %s is stored as JSON text", strings.TrimPrefix(properties.Type, "_"))
+		return "TEXT"
+	default:
+		return g.unknownType(table, field)
+	}
+}
+
+func (g *ddlGenerator) unknownType(table *schema.Table, field *schema.Field) string {
+	g.warnf(table.Name, field.Name, "unknown type %s is copied as is", field.Properties.Type)
+	return field.Properties.TypeString()
+}
+
+var (
+	castPattern        = regexp.MustCompile(`::[a-z_ ]+(\[\])?$`)
+	numberPattern      = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+	currentTimestampOf = map[string]bool{
+		"NOW()": true, "CURRENT_TIMESTAMP": true, "CURRENT_TIMESTAMP()": true,
+		"LOCALTIMESTAMP": true, "TRANSACTION_TIMESTAMP()": true,
+	}
+)
+
+// defaultValue translates a default as introspected, a PostgreSQL expression with casts,
+// a bare MySQL literal or a SQLite expression, into dialect. A NULL default is left out.
+func (g *ddlGenerator) defaultValue(value string, kind typeKind) string {
+	value = strings.TrimSpace(value)
+	for castPattern.MatchString(value) {
+		value = trimParentheses(castPattern.ReplaceAllString(value, ""))
+	}
+	upper := strings.ToUpper(value)
+	switch {
+	case upper == "NULL" || upper == "":
+		return ""
+	case currentTimestampOf[upper]:
+		return "CURRENT_TIMESTAMP"
+	case upper == "CURRENT_DATE" || upper == "CURRENT_TIME":
+		return upper
+	case kind == kindBoolean && (value == "0" || value == "1" || upper == "TRUE" || upper == "FALSE"):
+		return ifElse(value == "1" || upper == "TRUE", "TRUE", "FALSE")
+	case numberPattern.MatchString(value), strings.HasPrefix(value, "'"):
+		return value
+	case strings.HasPrefix(value, "("):
+		return value
+	case strings.Contains(value, "("):
+		// Function calls are expressions, which MySQL and SQLite only take in parentheses.
+		if g.dialect == datasource.PostgreSQL {
+			return value
+		}
+		return "(" + value + ")"
+	default:
+		// MySQL reports literal defaults without quotes.
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+}
+
+func isAutoIncrement(properties schema.FieldProperties) bool {
+	return properties.IsAutoIncrement() || strings.HasSuffix(strings.ToLower(properties.Type), "serial")
+}
+
+func isMySQLLargeObject(columnType string) bool {
+	return strings.HasSuffix(columnType, "TEXT") || strings.HasSuffix(columnType, "BLOB") || columnType == "JSON"
+}
+
+func isIntegerKind(kind typeKind) bool {
+	return kind == kindSmallInt || kind == kindInteger || kind == kindBigInt
+}
+
+func length(properties schema.FieldProperties) string {
+	if properties.Length == nil {
+		return ""
+	}
+	return "(" + strconv.Itoa(*properties.Length) + ")"
+}
+
+func precisionScale(properties schema.FieldProperties) string {
+	switch {
+	case properties.Precision == nil:
+		return ""
+	case properties.Scale == nil:
+		return fmt.Sprintf("(%d)", *properties.Precision)
+	default:
+		return fmt.Sprintf("(%d, %d)", *properties.Precision, *properties.Scale)
+	}
+}
+
+func quoteValues(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// trimParentheses removes the parentheses around all of expression, which databases add when reporting it.
+func trimParentheses(expression string) string {
+	expression = strings.TrimSpace(expression)
+	for strings.HasPrefix(expression, "(") && strings.HasSuffix(expression, ")") {
+		depth := 0
+		for i, char := range expression {
+			switch char {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 && i < len(expression)-1 {
+				return expression
+			}
+		}
+		expression = strings.TrimSpace(expression[1 : len(expression)-1])
+	}
+	return expression
+}
+
+func ifElse(condition bool, then, otherwise string) string {
+	if condition {
+		return then
+	}
+	return otherwise
+}
@@ -0,0 +1,184 @@
+package sql_test
+
+import (
+	gosql "database/sql"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/schema"
+	"github.com/ctrl-alt-boop/dribble/sql"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func ptr[T any](value T) *T { return &value }
+
+// accountsTable is laid out as the PostgreSQL adapter introspects it.
+func accountsTable() *schema.Table {
+	return &schema.Table{
+		Name: "accounts",
+		Fields: schema.Fields{
+			{Name: "id", Properties: schema.FieldProperties{Type: "integer", AutoInc: ptr("1")}},
+			{Name: "email", Properties: schema.FieldProperties{Type: "character varying", Length: ptr(255)}},
+			{Name: "group_id", Properties: schema.FieldProperties{Type: "bigint", Nullable: true}},
+			{Name: "balance", Properties: schema.FieldProperties{Type: "numeric", Precision: ptr(10), Scale: ptr(2), Default: ptr("0.00")}},
+			{Name: "status", Properties: schema.FieldProperties{Type: "mood", Default: ptr("'active'::mood"), AllowedValues: []string{"active", "closed"}}},
+			{Name: "active", Properties: schema.FieldProperties{Type: "boolean", Default: ptr("true")}},
+			{Name: "external_id", Properties: schema.FieldProperties{Type: "uuid", Nullable: true}},
+			{Name: "profile", Properties: schema.FieldProperties{Type: "jsonb", Nullable: true, Default: ptr("'{}'::jsonb")}},
+			{Name: "tags", Properties: schema.FieldProperties{Type: "_text", Nullable: true}},
+			{Name: "created", Properties: schema.FieldProperties{Type: "timestamp with time zone", Default: ptr("now()")}},
+			{Name: "domain", Properties: schema.FieldProperties{Type: "text", Nullable: true, Generated: ptr("split_part(email, '@', 2)")}},
+		},
+		Properties: schema.TableProperties{
+			PrimaryKeys: []string{"id"},
+			Indexes: []*schema.Index{
+				{Name: "accounts_created", Fields: []string{"created"}, Type: "btree"},
+				{Name: "accounts_email_key", Fields: []string{"email"}, Type: "btree", Unique: true},
+				{Name: "accounts_pkey", Fields: []string{"id"}, Type: "btree", Unique: true, Primary: true},
+				{Name: "accounts_profile", Fields: []string{"profile"}, Type: "gin"},
+			},
+			ForeignKeys: []*schema.ForeignKey{
+				{Name: "accounts_group_id_fkey", Fields: []string{"group_id"}, ToTable: "groups", ToFields: []string{"id"}, OnUpdate: "NO ACTION", OnDelete: "SET NULL"},
+			},
+			Constraints: []*schema.Constraint{
+				{Name: "accounts_email_key", Type: "UNIQUE", Fields: []string{"email"}},
+				{Name: "accounts_balance_check", Type: "CHECK", Fields: []string{"balance"}, Definition: "CHECK ((balance >= 0))"},
+			},
+		},
+	}
+}
+
+func TestGenerateDDL(t *testing.T) {
+	dialects := map[string]datasource.SQLDialectType{
+		"postgres": datasource.PostgreSQL,
+		"mysql":    datasource.MySQL,
+		"sqlite3":  datasource.SQLite3,
+	}
+	for name, dialect := range dialects {
+		t.Run(name, func(t *testing.T) {
+			ddl, err := sql.GenerateDDLFrom(datasource.PostgreSQL, dialect, accountsTable())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ddl.String() + "\n-- warnings\n" + strings.Join(ddl.Warnings, "\n") + "\n"
+			golden := filepath.Join("testdata", "ddl."+name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("ddl mismatch\n got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+// TestGenerateDDLSQLite3Golden runs the SQLite golden, so a golden that does not run cannot be accepted.
+func TestGenerateDDLSQLite3Golden(t *testing.T) {
+	golden, err := os.ReadFile(filepath.Join("testdata", "ddl.sqlite3.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, _, _ := strings.Cut(string(golden), "\n-- warnings\n")
+	db, err := gosql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(script); err != nil {
+		t.Fatalf("golden does not run: %v\n%s", err, script)
+	}
+}
+
+func TestGenerateDDLExpressions(t *testing.T) {
+	table := &schema.Table{
+		Name: "totals",
+		Fields: schema.Fields{
+			{Name: "amount", Properties: schema.FieldProperties{Type: "integer"}},
+			{Name: "doubled", Properties: schema.FieldProperties{Type: "integer", Generated: ptr("amount * 2")}},
+		},
+		Properties: schema.TableProperties{
+			Constraints: []*schema.Constraint{{Name: "totals_amount_check", Type: "CHECK", Definition: "amount >= 0"}},
+		},
+	}
+	tests := []struct {
+		name     string
+		generate func() (*sql.DDL, error)
+		want     string
+		warnings int
+	}{
+		{"same dialect", func() (*sql.DDL, error) {
+			return sql.GenerateDDLFrom(datasource.SQLite3, datasource.SQLite3, table)
+		}, `CREATE TABLE "totals" (
+	"amount" INTEGER NOT NULL,
+	"doubled" INTEGER GENERATED ALWAYS AS (amount * 2) STORED NOT NULL,
+	CONSTRAINT "totals_amount_check" CHECK (amount >= 0)
+)`, 0},
+		{"unknown dialect", func() (*sql.DDL, error) {
+			return sql.GenerateDDL(datasource.SQLite3, table)
+		}, `CREATE TABLE "totals" (
+	"amount" INTEGER NOT NULL,
+	"doubled" INTEGER GENERATED ALWAYS AS (amount * 2) STORED NOT NULL,
+	CONSTRAINT "totals_amount_check" CHECK (amount >= 0)
+)`, 2},
+		{"other dialect", func() (*sql.DDL, error) {
+			return sql.GenerateDDLFrom(datasource.PostgreSQL, datasource.SQLite3, table)
+		}, `CREATE TABLE "totals" (
+	"amount" INTEGER NOT NULL,
+	"doubled" INTEGER NOT NULL
+)`, 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ddl, err := tc.generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ddl.Statements) != 1 || ddl.Statements[0] != tc.want {
+				t.Errorf("got\n%s\nwant\n%s", ddl, tc.want)
+			}
+			if len(ddl.Warnings) != tc.warnings {
+				t.Errorf("got warnings %q, want %d", ddl.Warnings, tc.warnings)
+			}
+		})
+	}
+}
+
+func TestGenerateDDLDefaults(t *testing.T) {
+	table := &schema.Table{
+		Name: "flags",
+		Fields: schema.Fields{
+			// MySQL reports literal defaults unquoted, and booleans as tinyint(1).
+			{Name: "label", Properties: schema.FieldProperties{Type: "varchar", Length: ptr(20), Default: ptr("it's on")}},
+			{Name: "enabled", Properties: schema.FieldProperties{Type: "tinyint", Length: ptr(1), Default: ptr("1")}},
+			{Name: "hits", Properties: schema.FieldProperties{Type: "int unsigned", Default: ptr("NULL"), Nullable: true}},
+		},
+	}
+	ddl, err := sql.GenerateDDL(datasource.PostgreSQL, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `CREATE TABLE "flags" (
+	"label" varchar(20) NOT NULL DEFAULT 'it''s on',
+	"enabled" boolean NOT NULL DEFAULT TRUE,
+	"hits" bigint
+)`
+	if len(ddl.Statements) != 1 || ddl.Statements[0] != want {
+		t.Errorf("got\n%s\nwant\n%s", ddl, want)
+	}
+	if len(ddl.Warnings) != 0 {
+		t.Errorf("got warnings %v, want none", ddl.Warnings)
+	}
+}
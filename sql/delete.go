@@ -1 +1,72 @@
 package sql
+
+import (
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+)
+
+type DeleteQuery struct {
+	Table string
+
+	Where     Exprs
+	Returning []string
+	all       bool
+}
+
+// ReturnsRows reports whether the query has a RETURNING clause.
+func (q *DeleteQuery) ReturnsRows() bool {
+	return len(q.Returning) > 0
+}
+
+// Validate refuses queries without a where clause unless All was called.
+func (q *DeleteQuery) Validate() error {
+	if len(q.Where) == 0 && !q.all {
+		return ErrNoWhereClause
+	}
+	return nil
+}
+
+type DeleteBuilder struct {
+	table string
+
+	where     Exprs
+	returning []string
+	all       bool
+}
+
+func DeleteFrom(table string) *DeleteBuilder {
+	return &DeleteBuilder{
+		table: table,
+	}
+}
+
+func (d *DeleteBuilder) Where(expr ...Expr) *DeleteBuilder {
+	d.where = expr
+	return d
+}
+
+// All allows the delete to render without a where clause, deleting every row of the table.
+func (d *DeleteBuilder) All() *DeleteBuilder {
+	d.all = true
+	return d
+}
+
+// Returning makes the query return columns of the affected rows, as a result.Table.
+// Supported by PostgreSQL and SQLite 3.35 or later.
+func (d *DeleteBuilder) Returning(columns ...string) *DeleteBuilder {
+	d.returning = columns
+	return d
+}
+
+func (d *DeleteBuilder) ToRequest() datasource.Request {
+	operation := &DeleteQuery{
+		Table:     d.table,
+		Where:     d.where,
+		Returning: d.returning,
+		all:       d.all,
+	}
+	return &request.Intent{
+		Type:      datasource.Delete,
+		Operation: operation,
+	}
+}
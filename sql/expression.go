@@ -1,27 +1,41 @@
 package sql
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
+// ErrInvalidOperator is returned for a comparison operator Any or All does not accept.
+var ErrInvalidOperator = errors.New("invalid comparison operator")
+
 var _ Expr = (*compExpr)(nil)
 var _ Expr = (*logicExpr)(nil)
 var _ Expr = (*notExpr)(nil)
-
-var Parameterized = false
+var _ Expr = (*nullExpr)(nil)
+var _ Expr = (*inExpr)(nil)
+var _ Expr = (*betweenExpr)(nil)
+var _ Expr = (*existsExpr)(nil)
+var _ Expr = (*quantifiedExpr)(nil)
+var _ Expr = (*rawExpr)(nil)
 
 type (
+	// Expr is a condition of a where clause. Values are always bound as
+	// parameters and columns are quoted by the dialect they are rendered in.
+	// A value can also be a *SelectBuilder, rendered as a subquery, or a Col.
 	Expr interface {
-		ToSQL() (string, []any)
+		Render(*Renderer) string
 	}
 	Exprs []Expr
 
+	// Col is a column used as a value, e.g. to correlate a subquery with its enclosing query.
+	Col string
+
 	compExpr struct {
 		column string
 		op     string
 		value  any
-		wordOp bool
 	}
 
 	logicExpr struct {
@@ -30,70 +44,268 @@ type (
 	}
 
 	notExpr struct {
-		expr *compExpr
+		expr Expr
+	}
+
+	nullExpr struct {
+		column string
+		not    bool
+	}
+
+	inExpr struct {
+		column string
+		values []any
+		not    bool
+	}
+
+	betweenExpr struct {
+		column    string
+		low, high any
+		not       bool
+	}
+
+	existsExpr struct {
+		subquery *SelectBuilder
+		not      bool
+	}
+
+	quantifiedExpr struct {
+		column     string
+		op         string
+		quantifier string
+		subquery   *SelectBuilder
+	}
+
+	rawExpr struct {
+		fragment string
+		args     []any
 	}
 )
 
-func (e Exprs) ToSQL() (string, []any) {
+// negatedOps are the operators a compExpr is negated with by Not.
+var negatedOps = map[string]string{
+	"=":                    "<>",
+	"!=":                   "=",
+	"<>":                   "=",
+	">":                    "<=",
+	"<":                    ">=",
+	">=":                   "<",
+	"<=":                   ">",
+	"LIKE":                 "NOT LIKE",
+	"NOT LIKE":             "LIKE",
+	"ILIKE":                "NOT ILIKE",
+	"NOT ILIKE":            "ILIKE",
+	"IS DISTINCT FROM":     "IS NOT DISTINCT FROM",
+	"IS NOT DISTINCT FROM": "IS DISTINCT FROM",
+}
+
+// quantifiedOps are the comparisons Any and All accept.
+var quantifiedOps = map[string]bool{"=": true, "<>": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// Render implements Expr, joining the expressions with AND.
+func (e Exprs) Render(r *Renderer) string {
 	parts := make([]string, 0, len(e))
-	var params []any
 	for _, expr := range e {
-		part, partParams := expr.ToSQL()
-		parts = append(parts, part)
-		params = append(params, partParams...)
+		parts = append(parts, expr.Render(r))
 	}
-	return strings.Join(parts, " AND "), params
+	return strings.Join(parts, " AND ")
 }
 
-func (n *notExpr) ToSQL() (string, []any) {
-	if n.expr.wordOp {
-		return fmt.Sprintf("%s NOT %s ?", n.expr.column, n.expr.op), []any{n.expr.value}
+// ToSQL renders the expressions without a dialect, with ? placeholders and unquoted columns.
+func (e Exprs) ToSQL() (string, []any) {
+	r := NewRenderer(nil)
+	return e.Render(r), r.Args()
+}
+
+// renderValue binds value, unless it is a column or a subquery.
+func renderValue(r *Renderer, value any) string {
+	switch value := value.(type) {
+	case Col:
+		return r.Ident(string(value))
+	case *SelectBuilder:
+		return "(" + r.Subquery(value) + ")"
+	default:
+		return r.Bind(value)
 	}
-	return fmt.Sprintf("%s !%s ?", n.expr.column, n.expr.op), nil
 }
 
-func (c *compExpr) ToSQL() (string, []any) {
-	if Parameterized {
-		return fmt.Sprintf("%s %s ?", c.column, c.op), []any{c.value}
+func (n *notExpr) Render(r *Renderer) string {
+	switch expr := n.expr.(type) {
+	case *compExpr:
+		if op, ok := negatedOps[expr.op]; ok {
+			return (&compExpr{column: expr.column, op: op, value: expr.value}).Render(r)
+		}
+	case *nullExpr:
+		return (&nullExpr{column: expr.column, not: !expr.not}).Render(r)
+	case *inExpr:
+		return (&inExpr{column: expr.column, values: expr.values, not: !expr.not}).Render(r)
+	case *betweenExpr:
+		return (&betweenExpr{column: expr.column, low: expr.low, high: expr.high, not: !expr.not}).Render(r)
+	case *existsExpr:
+		return (&existsExpr{subquery: expr.subquery, not: !expr.not}).Render(r)
+	case *logicExpr:
+		return fmt.Sprintf("NOT %s", expr.Render(r))
 	}
-	value := c.resolveValueType()
-	return fmt.Sprintf("%s %s %v", c.column, c.op, value), nil
+	return fmt.Sprintf("NOT (%s)", n.expr.Render(r))
 }
 
-func (c *compExpr) resolveValueType() string {
-	switch c.value.(type) {
-	case string:
-		return fmt.Sprintf("'%s'", c.value)
-	case bool:
-		return fmt.Sprintf("%t", c.value)
-	default:
-		return fmt.Sprint(c.value)
+func (c *compExpr) Render(r *Renderer) string {
+	return r.Operator(c.op, "%[1]s "+c.op+" %[2]s", r.Ident(c.column), renderValue(r, c.value))
+}
+
+func (n *nullExpr) Render(r *Renderer) string {
+	if n.not {
+		return fmt.Sprintf("%s IS NOT NULL", r.Ident(n.column))
 	}
+	return fmt.Sprintf("%s IS NULL", r.Ident(n.column))
 }
 
-func (l *logicExpr) ToSQL() (string, []any) {
+func (i *inExpr) Render(r *Renderer) string {
+	op := "IN"
+	if i.not {
+		op = "NOT IN"
+	}
+	if len(i.values) == 1 {
+		if subquery, ok := i.values[0].(*SelectBuilder); ok {
+			return fmt.Sprintf("%s %s (%s)", r.Ident(i.column), op, r.Subquery(subquery))
+		}
+	}
+	if len(i.values) == 0 {
+		// Nothing is in an empty list, and "IN ()" is not valid SQL.
+		if i.not {
+			return "1 = 1"
+		}
+		return "1 = 0"
+	}
+	placeholders := make([]string, len(i.values))
+	for j, value := range i.values {
+		placeholders[j] = renderValue(r, value)
+	}
+	return fmt.Sprintf("%s %s (%s)", r.Ident(i.column), op, strings.Join(placeholders, ", "))
+}
+
+func (b *betweenExpr) Render(r *Renderer) string {
+	op := "BETWEEN"
+	if b.not {
+		op = "NOT BETWEEN"
+	}
+	return fmt.Sprintf("%s %s %s AND %s", r.Ident(b.column), op, renderValue(r, b.low), renderValue(r, b.high))
+}
+
+func (e *existsExpr) Render(r *Renderer) string {
+	op := "EXISTS"
+	if e.not {
+		op = "NOT EXISTS"
+	}
+	return fmt.Sprintf("%s (%s)", op, r.Subquery(e.subquery))
+}
+
+func (q *quantifiedExpr) Render(r *Renderer) string {
+	if !quantifiedOps[q.op] {
+		r.Fail(fmt.Errorf("%w: %q", ErrInvalidOperator, q.op))
+		return ""
+	}
+	return r.Operator(q.quantifier, "%[1]s %[2]s "+q.quantifier+" (%[3]s)", r.Ident(q.column), q.op, r.Subquery(q.subquery))
+}
+
+func (e *rawExpr) Render(r *Renderer) string {
+	return r.Raw(e.fragment, e.args)
+}
+
+func (l *logicExpr) Render(r *Renderer) string {
 	parts := make([]string, 0, len(l.exprs))
-	var params []any
 	for _, expr := range l.exprs {
-		part, partParams := expr.ToSQL()
-		parts = append(parts, part)
-		params = append(params, partParams...)
+		parts = append(parts, expr.Render(r))
 	}
-	return fmt.Sprintf("(%s)", strings.Join(parts, fmt.Sprintf(" %s ", l.op))), params
+	return fmt.Sprintf("(%s)", strings.Join(parts, fmt.Sprintf(" %s ", l.op)))
+}
+
+// flatten expands a single slice argument, so both In("id", 1, 2) and In("id", ids) work.
+func flatten(values []any) []any {
+	if len(values) != 1 {
+		return values
+	}
+	value := reflect.ValueOf(values[0])
+	if value.Kind() != reflect.Slice || value.Type().Elem().Kind() == reflect.Uint8 {
+		return values
+	}
+	flat := make([]any, value.Len())
+	for i := range flat {
+		flat[i] = value.Index(i).Interface()
+	}
+	return flat
 }
 
 func Eq(column string, value any) *compExpr { return &compExpr{column: column, op: "=", value: value} }
 func Ne(column string, value any) *compExpr { return &compExpr{column: column, op: "!=", value: value} }
 func Gt(column string, value any) *compExpr { return &compExpr{column: column, op: ">", value: value} }
 func Lt(column string, value any) *compExpr { return &compExpr{column: column, op: "<", value: value} }
+func Ge(column string, value any) *compExpr { return &compExpr{column: column, op: ">=", value: value} }
+func Le(column string, value any) *compExpr { return &compExpr{column: column, op: "<=", value: value} }
 func Like(column string, value any) *compExpr {
-	return &compExpr{column: column, op: "LIKE", value: value, wordOp: true}
+	return &compExpr{column: column, op: "LIKE", value: value}
+}
+func NotLike(column string, value any) *compExpr {
+	return &compExpr{column: column, op: "NOT LIKE", value: value}
+}
+
+// Ilike is a case-insensitive LIKE, emulated with LOWER on dialects without ILIKE.
+func Ilike(column string, value any) *compExpr {
+	return &compExpr{column: column, op: "ILIKE", value: value}
+}
+func NotIlike(column string, value any) *compExpr {
+	return &compExpr{column: column, op: "NOT ILIKE", value: value}
 }
-func Null(column string) *compExpr {
-	return &compExpr{column: column, op: "IS", value: "NULL", wordOp: true}
+
+// IsDistinctFrom is a null-safe Ne.
+func IsDistinctFrom(column string, value any) *compExpr {
+	return &compExpr{column: column, op: "IS DISTINCT FROM", value: value}
+}
+
+// IsNotDistinctFrom is a null-safe Eq.
+func IsNotDistinctFrom(column string, value any) *compExpr {
+	return &compExpr{column: column, op: "IS NOT DISTINCT FROM", value: value}
+}
+
+func Null(column string) *nullExpr      { return &nullExpr{column: column} }
+func IsNotNull(column string) *nullExpr { return &nullExpr{column: column, not: true} }
+
+// In matches any of values, a single slice is expanded and a single *SelectBuilder is used as a subquery.
+func In(column string, values ...any) *inExpr {
+	return &inExpr{column: column, values: flatten(values)}
+}
+func NotIn(column string, values ...any) *inExpr {
+	return &inExpr{column: column, values: flatten(values), not: true}
+}
+
+func Between(column string, low, high any) *betweenExpr {
+	return &betweenExpr{column: column, low: low, high: high}
+}
+func NotBetween(column string, low, high any) *betweenExpr {
+	return &betweenExpr{column: column, low: low, high: high, not: true}
+}
+
+func Exists(subquery *SelectBuilder) *existsExpr { return &existsExpr{subquery: subquery} }
+func NotExists(subquery *SelectBuilder) *existsExpr {
+	return &existsExpr{subquery: subquery, not: true}
+}
+
+// Raw is fragment as it is written, binding args to its ? placeholders in order, e.g. Raw("n + ?", 1)
+// or Raw("NOW()"). Its identifiers are not quoted, nor is it checked to run in the dialect.
+func Raw(fragment string, args ...any) *rawExpr { return &rawExpr{fragment: fragment, args: args} }
+
+// Any compares column with op to the rows of subquery, true if any comparison is, e.g. Any("price", ">", sub).
+// op is one of =, <>, !=, <, <=, > and >=, rendering fails with ErrInvalidOperator for any other.
+func Any(column, op string, subquery *SelectBuilder) *quantifiedExpr {
+	return &quantifiedExpr{column: column, op: op, quantifier: "ANY", subquery: subquery}
+}
+
+// All compares column with op to the rows of subquery, true if every comparison is.
+func All(column, op string, subquery *SelectBuilder) *quantifiedExpr {
+	return &quantifiedExpr{column: column, op: op, quantifier: "ALL", subquery: subquery}
 }
 
 func And(exprs ...Expr) *logicExpr { return &logicExpr{op: "AND", exprs: exprs} }
 func Or(exprs ...Expr) *logicExpr  { return &logicExpr{op: "OR", exprs: exprs} }
 
-func Not(expr *compExpr) *notExpr { return &notExpr{expr: expr} }
+func Not(expr Expr) *notExpr { return &notExpr{expr: expr} }
@@ -1 +1,282 @@
 package sql
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+)
+
+var (
+	ErrNoValues         = errors.New("insert has no values")
+	ErrValuesAndSelect  = errors.New("insert has both values and a select")
+	ErrColumnMismatch   = errors.New("number of values does not match number of columns")
+	ErrUnknownColumn    = errors.New("value for a column that is not inserted")
+	ErrUnsupportedValue = errors.New("unsupported row value")
+	ErrNoConflictTarget = errors.New("on conflict do update needs the conflicting columns")
+)
+
+type InsertQuery struct {
+	Table   string
+	Columns []string
+	Rows    [][]any
+	Select  *SelectQuery
+
+	OnConflict *conflictClause
+	Returning  []string
+
+	err error
+}
+
+// ReturnsRows reports whether the insert has a RETURNING clause.
+func (q *InsertQuery) ReturnsRows() bool {
+	return len(q.Returning) > 0
+}
+
+// Validate reports any error the query was built with.
+func (q *InsertQuery) Validate() error {
+	return q.err
+}
+
+type InsertBuilder struct {
+	table   string
+	columns []string
+	rows    [][]any
+	selectQ *SelectBuilder
+
+	onConflict *conflictClause
+	returning  []string
+
+	err error
+}
+
+func Insert(table string) *InsertBuilder {
+	return &InsertBuilder{
+		table: table,
+		rows:  [][]any{},
+	}
+}
+
+// Columns sets the inserted columns, which rows already added must match.
+func (i *InsertBuilder) Columns(columns ...string) *InsertBuilder {
+	i.columns = columns
+	for _, row := range i.rows {
+		if len(row) != len(columns) {
+			i.setErr(fmt.Errorf("%w: %d values for %d columns", ErrColumnMismatch, len(row), len(columns)))
+			break
+		}
+	}
+	return i
+}
+
+// Values adds a row, one value per column. Without Columns, it must have as many values as the first row.
+func (i *InsertBuilder) Values(values ...any) *InsertBuilder {
+	width := len(i.columns)
+	if width == 0 && len(i.rows) > 0 {
+		width = len(i.rows[0])
+	}
+	if width > 0 && len(values) != width {
+		i.setErr(fmt.Errorf("%w: %d values for %d columns", ErrColumnMismatch, len(values), width))
+		return i
+	}
+	i.rows = append(i.rows, values)
+	return i
+}
+
+// Maps adds a row per map. Without Columns, the sorted keys of the first map are used.
+// Every map must have a key for each column and no other, nil inserts NULL.
+func (i *InsertBuilder) Maps(rows ...map[string]any) *InsertBuilder {
+	for _, row := range rows {
+		if len(i.columns) == 0 {
+			i.columns = slices.Sorted(maps.Keys(row))
+		}
+		for _, key := range slices.Sorted(maps.Keys(row)) {
+			if !slices.Contains(i.columns, key) {
+				i.setErr(fmt.Errorf("%w: %s", ErrUnknownColumn, key))
+				return i
+			}
+		}
+		values := make([]any, len(i.columns))
+		for j, column := range i.columns {
+			value, ok := row[column]
+			if !ok {
+				i.setErr(fmt.Errorf("%w: no value for %s", ErrColumnMismatch, column))
+				return i
+			}
+			values[j] = value
+		}
+		i.rows = append(i.rows, values)
+	}
+	return i
+}
+
+// Structs adds a row per struct, or pointer to struct. Columns are named by the
+// db tag of each exported field or its lowercased name, fields tagged db:"-" are skipped.
+func (i *InsertBuilder) Structs(rows ...any) *InsertBuilder {
+	for _, row := range rows {
+		columns, values, err := structColumns(row)
+		if err != nil {
+			i.setErr(err)
+			return i
+		}
+		if len(i.columns) == 0 {
+			i.columns = columns
+		}
+		byColumn := make(map[string]any, len(columns))
+		for j, column := range columns {
+			byColumn[column] = values[j]
+		}
+		i.Maps(byColumn)
+	}
+	return i
+}
+
+// FromSelect inserts the rows selected by s instead of values.
+func (i *InsertBuilder) FromSelect(s *SelectBuilder) *InsertBuilder {
+	i.selectQ = s
+	return i
+}
+
+// OnConflict handles rows conflicting with existing ones on a unique or primary key of columns.
+// MySQL handles conflicts on any unique key, columns are only used for DoNothing there.
+func (i *InsertBuilder) OnConflict(columns ...string) *ConflictBuilder {
+	return &ConflictBuilder{
+		insert:  i,
+		columns: columns,
+	}
+}
+
+// Returning makes the insert return columns of the inserted rows, as a result.Table.
+// Supported by PostgreSQL and SQLite 3.35 or later.
+func (i *InsertBuilder) Returning(columns ...string) *InsertBuilder {
+	i.returning = columns
+	return i
+}
+
+func (i *InsertBuilder) setErr(err error) {
+	if i.err == nil {
+		i.err = err
+	}
+}
+
+func (i *InsertBuilder) query() *InsertQuery {
+	operation := &InsertQuery{
+		Table:   i.table,
+		Columns: i.columns,
+		Rows:    i.rows,
+
+		Returning: i.returning,
+		err:       i.err,
+	}
+	if i.selectQ != nil {
+		operation.Select = i.selectQ.query()
+	}
+	if i.onConflict != nil {
+		onConflict := *i.onConflict
+		if !onConflict.DoNothing && len(onConflict.Update) == 0 {
+			for _, column := range i.columns {
+				if !slices.Contains(onConflict.Columns, column) {
+					onConflict.Update = append(onConflict.Update, column)
+				}
+			}
+		}
+		operation.OnConflict = &onConflict
+	}
+	if operation.err == nil {
+		switch {
+		case operation.Select != nil && len(operation.Rows) > 0:
+			operation.err = ErrValuesAndSelect
+		case operation.Select == nil && len(operation.Rows) == 0:
+			operation.err = ErrNoValues
+		case operation.OnConflict != nil && !operation.OnConflict.DoNothing && len(operation.OnConflict.Columns) == 0:
+			operation.err = ErrNoConflictTarget
+		case operation.OnConflict != nil && !operation.OnConflict.DoNothing && len(operation.OnConflict.Update) == 0:
+			operation.err = ErrNoAssignments
+		}
+	}
+	return operation
+}
+
+func (i *InsertBuilder) ToRequest() datasource.Request {
+	return &request.Intent{
+		Type:      datasource.Create,
+		Operation: i.query(),
+	}
+}
+
+type conflictClause struct {
+	Columns   []string
+	Update    []string
+	DoNothing bool
+}
+
+type ConflictBuilder struct {
+	insert  *InsertBuilder
+	columns []string
+}
+
+// DoUpdate overwrites columns of the existing row with the values of the inserted one,
+// all inserted columns but the conflicting ones when none are given.
+func (c *ConflictBuilder) DoUpdate(columns ...string) *InsertBuilder {
+	c.insert.onConflict = &conflictClause{
+		Columns: c.columns,
+		Update:  columns,
+	}
+	return c.insert
+}
+
+// DoNothing skips the conflicting rows.
+func (c *ConflictBuilder) DoNothing() *InsertBuilder {
+	c.insert.onConflict = &conflictClause{
+		Columns:   c.columns,
+		DoNothing: true,
+	}
+	return c.insert
+}
+
+func structColumns(row any) ([]string, []any, error) {
+	value := reflect.ValueOf(row)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, nil, fmt.Errorf("%w: nil %T", ErrUnsupportedValue, row)
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("%w: %T is not a struct", ErrUnsupportedValue, row)
+	}
+
+	var columns []string
+	var values []any
+	valueType := value.Type()
+	for j := range valueType.NumField() {
+		field := valueType.Field(j)
+		if field.Anonymous && field.IsExported() && field.Type.Kind() == reflect.Struct {
+			embeddedColumns, embeddedValues, err := structColumns(value.Field(j).Interface())
+			if err != nil {
+				return nil, nil, err
+			}
+			columns = append(columns, embeddedColumns...)
+			values = append(values, embeddedValues...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name, hasTag := field.Tag.Lookup("db")
+		if name == "-" {
+			continue
+		}
+		if !hasTag || name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		columns = append(columns, name)
+		values = append(values, value.Field(j).Interface())
+	}
+	return columns, values, nil
+}
@@ -0,0 +1,165 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrUnsupported is returned when a statement uses a feature its dialect lacks.
+var ErrUnsupported = errors.New("unsupported by dialect")
+
+// Dialect is the part of a datasource.SQLAdapter a statement is rendered with.
+type Dialect interface {
+	Quote(string) string
+	QuoteRune() rune
+	RenderPlaceholder(index int) string
+}
+
+// OperatorDialect is implemented by dialects that render some keywords differently from standard SQL.
+// RenderOperator returns the format of keyword for its operands, e.g. "LOWER(%[1]s) LIKE LOWER(%[2]s)"
+// for ILIKE, an empty format to use the standard form, or an error wrapping ErrUnsupported.
+type OperatorDialect interface {
+	RenderOperator(keyword string) (string, error)
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)*$`)
+
+// Renderer collects the bound parameters of a statement while it is rendered,
+// handing out the placeholder of its dialect for each one.
+// Without a dialect it renders ? placeholders and leaves identifiers unquoted.
+type Renderer struct {
+	dialect Dialect
+	args    []any
+	err     error
+
+	subquery func(*SelectQuery) (string, error)
+}
+
+func NewRenderer(dialect Dialect) *Renderer {
+	return &Renderer{
+		dialect: dialect,
+		args:    []any{},
+	}
+}
+
+// Bind adds value to the parameters and returns its placeholder.
+func (r *Renderer) Bind(value any) string {
+	r.args = append(r.args, value)
+	if r.dialect == nil {
+		return "?"
+	}
+	return r.dialect.RenderPlaceholder(len(r.args))
+}
+
+// Ident quotes a plain, optionally dotted, identifier, as well as both sides of
+// "name AS alias" and the table of "table.*".
+// Anything else, like *, function calls or already quoted names, is returned as is.
+func (r *Renderer) Ident(name string) string {
+	if r.dialect == nil || strings.HasPrefix(name, string(r.dialect.QuoteRune())) {
+		return name
+	}
+	if index := strings.Index(strings.ToUpper(name), " AS "); index > 0 {
+		return r.Ident(name[:index]) + " AS " + r.Ident(name[index+len(" AS "):])
+	}
+	if table, isWildcard := strings.CutSuffix(name, ".*"); isWildcard {
+		return r.Ident(table) + ".*"
+	}
+	if !identifierPattern.MatchString(name) {
+		return name
+	}
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = r.dialect.Quote(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// Expr renders expr, binding its values.
+func (r *Renderer) Expr(expr Expr) string {
+	return expr.Render(r)
+}
+
+// Value renders the value of an assignment: an Expr as it renders, a Col or a *SelectBuilder
+// as in a comparison, and anything else bound.
+func (r *Renderer) Value(value any) string {
+	if expr, ok := value.(Expr); ok {
+		return expr.Render(r)
+	}
+	return renderValue(r, value)
+}
+
+// Raw binds args to the ? placeholders of fragment, in order.
+func (r *Renderer) Raw(fragment string, args []any) string {
+	if len(args) == 0 {
+		return fragment
+	}
+	var sb strings.Builder
+	for _, char := range fragment {
+		if char == '?' && len(args) > 0 {
+			sb.WriteString(r.Bind(args[0]))
+			args = args[1:]
+			continue
+		}
+		sb.WriteRune(char)
+	}
+	return sb.String()
+}
+
+// Operator renders keyword for its operands with the format of the dialect, or with standard when it has none.
+func (r *Renderer) Operator(keyword, standard string, operands ...any) string {
+	format := standard
+	if dialect, ok := r.dialect.(OperatorDialect); ok {
+		dialectFormat, err := dialect.RenderOperator(keyword)
+		if err != nil {
+			r.Fail(err)
+			return ""
+		}
+		if dialectFormat != "" {
+			format = dialectFormat
+		}
+	}
+	return fmt.Sprintf(format, operands...)
+}
+
+// SetSubquery sets how selects nested in a statement are rendered.
+func (r *Renderer) SetSubquery(render func(*SelectQuery) (string, error)) {
+	r.subquery = render
+}
+
+// Subquery renders s, binding its values along with those of the enclosing statement.
+func (r *Renderer) Subquery(s *SelectBuilder) string {
+	return r.Query(s.query())
+}
+
+// Query renders a built select on a single line, like Subquery.
+func (r *Renderer) Query(q *SelectQuery) string {
+	if r.subquery == nil {
+		r.Fail(errors.New("subqueries need a dialect to render"))
+		return ""
+	}
+	query, err := r.subquery(q)
+	if err != nil {
+		r.Fail(err)
+		return ""
+	}
+	return strings.ReplaceAll(query, "\n", " ")
+}
+
+// Fail records err, only the first error is kept.
+func (r *Renderer) Fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Err returns the first error met while rendering.
+func (r *Renderer) Err() error {
+	return r.err
+}
+
+// Args returns the parameters bound so far.
+func (r *Renderer) Args() []any {
+	return r.args
+}
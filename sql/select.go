@@ -6,6 +6,9 @@ import (
 )
 
 type SelectQuery struct {
+	With      []commonTableExpression
+	Recursive bool
+
 	AsDistinct bool
 	IsCount    bool
 
@@ -14,11 +17,13 @@ type SelectQuery struct {
 
 	Joins []joinClause
 
-	WhereClause string
-	args        []any
+	Where Exprs
 
 	GroupByClause []string
 	HavingClause  string
+
+	SetOperations []setOperation
+
 	OrderByClause []orderByClause
 
 	LimitClause  *int
@@ -26,6 +31,9 @@ type SelectQuery struct {
 }
 
 type SelectBuilder struct {
+	with      []cte
+	recursive bool
+
 	asDistinct bool
 	isCount    bool
 
@@ -34,11 +42,13 @@ type SelectBuilder struct {
 
 	joins []joinClause
 
-	whereClause string
-	params      []any
+	where Exprs
 
 	groupByClause []string
 	havingClause  string
+
+	compounds []compound
+
 	orderByClause []orderByClause
 
 	limitClause  *int
@@ -84,21 +94,22 @@ func (b From) From(table string, joins ...joinClause) *SelectBuilder {
 		fields:        b.fields,
 		table:         table,
 		joins:         joins,
-		params:        []any{},
 		orderByClause: []orderByClause{},
 	}
 }
 
 func (s *SelectBuilder) Copy() *SelectBuilder {
 	return &SelectBuilder{
+		with:          s.with,
+		recursive:     s.recursive,
 		asDistinct:    s.asDistinct,
 		fields:        s.fields,
 		table:         s.table,
 		joins:         s.joins,
-		whereClause:   s.whereClause,
-		params:        s.params,
+		where:         s.where,
 		groupByClause: s.groupByClause,
 		havingClause:  s.havingClause,
+		compounds:     s.compounds,
 		orderByClause: s.orderByClause,
 		limitClause:   s.limitClause,
 		offsetClause:  s.offsetClause,
@@ -125,7 +136,7 @@ func (s *SelectBuilder) ShouldReturn() int {
 }
 
 func (s *SelectBuilder) Where(expr ...Expr) *SelectBuilder {
-	s.whereClause, s.params = Exprs(expr).ToSQL()
+	s.where = expr
 	return s
 }
 
@@ -134,7 +145,7 @@ type orderByClause struct {
 	Desc  bool
 }
 
-func (s *SelectBuilder) OrderBy(field string, desc bool) *SelectBuilder { // FIXME: Something funky, is broken
+func (s *SelectBuilder) OrderBy(field string, desc bool) *SelectBuilder {
 	s.orderByClause = append(s.orderByClause, orderByClause{
 		Field: field,
 		Desc:  desc,
@@ -162,28 +173,33 @@ func (s *SelectBuilder) Offset(offset int) *SelectBuilder {
 	return s
 }
 
-func (s *SelectBuilder) ToRequest() datasource.Request {
-	operation := &SelectQuery{
+func (s *SelectBuilder) query() *SelectQuery {
+	query := &SelectQuery{
+		Recursive:     s.recursive,
 		AsDistinct:    s.asDistinct,
 		IsCount:       s.isCount,
 		Fields:        s.fields,
 		Table:         s.table,
 		Joins:         s.joins,
-		WhereClause:   s.whereClause,
-		args:          s.params,
+		Where:         s.where,
 		GroupByClause: s.groupByClause,
 		HavingClause:  s.havingClause,
 		OrderByClause: s.orderByClause,
 		LimitClause:   s.limitClause,
 		OffsetClause:  s.offsetClause,
 	}
-	return &request.Intent{
-		Type:      datasource.Read,
-		Operation: operation,
-		Args:      s.params,
+	for _, with := range s.with {
+		query.With = append(query.With, commonTableExpression{Name: with.name, Query: with.query.query()})
 	}
+	for _, compound := range s.compounds {
+		query.SetOperations = append(query.SetOperations, setOperation{Operator: compound.operator, Query: compound.query.query()})
+	}
+	return query
 }
 
-func (s *SelectBuilder) Parameters() []any {
-	return s.params
+func (s *SelectBuilder) ToRequest() datasource.Request {
+	return &request.Intent{
+		Type:      datasource.Read,
+		Operation: s.query(),
+	}
 }
@@ -22,7 +22,7 @@ func FromString(query string, args ...any) (datasource.Request, error) {
 	operationType := datasource.NoOp
 
 	switch {
-	case strings.HasPrefix(query, "SELECT"):
+	case strings.HasPrefix(query, "SELECT"), strings.HasPrefix(query, "WITH"):
 		operationType = datasource.Read
 	case strings.HasPrefix(query, "INSERT"):
 		operationType = datasource.Create
@@ -1 +1,100 @@
 package sql
+
+import (
+	"errors"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+)
+
+var (
+	ErrNoWhereClause = errors.New("refusing to render without a where clause, use All to affect every row")
+	ErrNoAssignments = errors.New("update has no assignments")
+)
+
+type Assignment struct {
+	Column string
+	Value  any
+}
+
+type UpdateQuery struct {
+	Table       string
+	Assignments []Assignment
+
+	Where     Exprs
+	Returning []string
+	all       bool
+}
+
+// ReturnsRows reports whether the query has a RETURNING clause.
+func (q *UpdateQuery) ReturnsRows() bool {
+	return len(q.Returning) > 0
+}
+
+// Validate refuses queries without assignments, and those without a where clause unless All was called.
+func (q *UpdateQuery) Validate() error {
+	if len(q.Assignments) == 0 {
+		return ErrNoAssignments
+	}
+	if len(q.Where) == 0 && !q.all {
+		return ErrNoWhereClause
+	}
+	return nil
+}
+
+type UpdateBuilder struct {
+	table       string
+	assignments []Assignment
+
+	where     Exprs
+	returning []string
+	all       bool
+}
+
+func Update(table string) *UpdateBuilder {
+	return &UpdateBuilder{
+		table: table,
+	}
+}
+
+// Set assigns value to column. value is bound, unless it is an Expr, rendered as the new value,
+// e.g. Set("n", Raw("n + 1")) or Set("updated", Raw("NOW()")), a Col or a *SelectBuilder.
+func (u *UpdateBuilder) Set(column string, value any) *UpdateBuilder {
+	u.assignments = append(u.assignments, Assignment{
+		Column: column,
+		Value:  value,
+	})
+	return u
+}
+
+func (u *UpdateBuilder) Where(expr ...Expr) *UpdateBuilder {
+	u.where = expr
+	return u
+}
+
+// All allows the update to render without a where clause, updating every row of the table.
+func (u *UpdateBuilder) All() *UpdateBuilder {
+	u.all = true
+	return u
+}
+
+// Returning makes the query return columns of the affected rows, as a result.Table.
+// Supported by PostgreSQL and SQLite 3.35 or later.
+func (u *UpdateBuilder) Returning(columns ...string) *UpdateBuilder {
+	u.returning = columns
+	return u
+}
+
+func (u *UpdateBuilder) ToRequest() datasource.Request {
+	operation := &UpdateQuery{
+		Table:       u.table,
+		Assignments: u.assignments,
+		Where:       u.where,
+		Returning:   u.returning,
+		all:         u.all,
+	}
+	return &request.Intent{
+		Type:      datasource.Update,
+		Operation: operation,
+	}
+}
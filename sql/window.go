@@ -0,0 +1,67 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WindowBuilder builds a window function call for the field list of a select, e.g.
+//
+//	Window("ROW_NUMBER()").PartitionBy("dept").OrderBy("salary", true).As("rank")
+type WindowBuilder struct {
+	function    string
+	partitionBy []string
+	orderBy     []orderByClause
+	frame       string
+}
+
+func Window(function string) *WindowBuilder {
+	return &WindowBuilder{
+		function: function,
+	}
+}
+
+func (w *WindowBuilder) PartitionBy(fields ...string) *WindowBuilder {
+	w.partitionBy = append(w.partitionBy, fields...)
+	return w
+}
+
+func (w *WindowBuilder) OrderBy(field string, desc bool) *WindowBuilder {
+	w.orderBy = append(w.orderBy, orderByClause{
+		Field: field,
+		Desc:  desc,
+	})
+	return w
+}
+
+// Frame sets the frame clause, e.g. "ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW".
+func (w *WindowBuilder) Frame(frame string) *WindowBuilder {
+	w.frame = frame
+	return w
+}
+
+// As returns the window as a field named alias.
+func (w *WindowBuilder) As(alias string) string {
+	return fmt.Sprintf("%s AS %s", w.String(), alias)
+}
+
+func (w *WindowBuilder) String() string {
+	var clauses []string
+	if len(w.partitionBy) > 0 {
+		clauses = append(clauses, "PARTITION BY "+strings.Join(w.partitionBy, ", "))
+	}
+	if len(w.orderBy) > 0 {
+		orders := make([]string, len(w.orderBy))
+		for i, order := range w.orderBy {
+			orders[i] = order.Field
+			if order.Desc {
+				orders[i] += " DESC"
+			}
+		}
+		clauses = append(clauses, "ORDER BY "+strings.Join(orders, ", "))
+	}
+	if w.frame != "" {
+		clauses = append(clauses, w.frame)
+	}
+	return fmt.Sprintf("%s OVER (%s)", w.function, strings.Join(clauses, " "))
+}
@@ -0,0 +1,133 @@
+package target
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+)
+
+// ActiveRequest is a request running on a Target, see Target.ActiveRequests.
+type ActiveRequest struct {
+	RequestID int64
+	Target    string
+	// Statement is the SQL of the request, that of every request in order for chains,
+	// batches and transactions, or its type when it has none.
+	Statement string
+	Started   time.Time
+	Elapsed   time.Duration
+}
+
+type activeRequest struct {
+	ActiveRequest
+	cancel context.CancelFunc
+}
+
+// timeoutKey holds the timeout of a TimeoutRequest for the requests it wraps.
+type timeoutKey struct{}
+
+// renderer is implemented by SQL data sources.
+type renderer interface {
+	Render(datasource.Request) (datasource.RequestType, string, []any, error)
+}
+
+// Cancel cancels the context of a running request, responding with its error.
+func (t *Target) Cancel(requestID int64) error {
+	t.activeMu.Lock()
+	active, ok := t.active[requestID]
+	t.activeMu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrRequestNotActive, requestID)
+	}
+	active.cancel()
+	return nil
+}
+
+// ActiveRequests returns the requests running on the target, ordered by RequestID.
+func (t *Target) ActiveRequests() []ActiveRequest {
+	t.activeMu.Lock()
+	defer t.activeMu.Unlock()
+	now := time.Now()
+	requests := make([]ActiveRequest, 0, len(t.active))
+	for _, active := range t.active {
+		listed := active.ActiveRequest
+		listed.Elapsed = now.Sub(listed.Started)
+		requests = append(requests, listed)
+	}
+	slices.SortFunc(requests, func(a, b ActiveRequest) int {
+		return cmp.Compare(a.RequestID, b.RequestID)
+	})
+	return requests
+}
+
+// track derives the context of a request from ctx, done when Cancel is called with requestID,
+// when the timeout of a wrapping TimeoutRequest or of the target has passed, or when the
+// returned done is called once the request has finished.
+func (t *Target) track(ctx context.Context, requestID int64, req datasource.Request) (context.Context, func()) {
+	timeout := t.requestTimeout
+	if requestTimeout, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		timeout = requestTimeout
+	}
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	t.activeMu.Lock()
+	if t.active == nil {
+		t.active = make(map[int64]*activeRequest)
+	}
+	t.active[requestID] = &activeRequest{
+		ActiveRequest: ActiveRequest{
+			RequestID: requestID,
+			Target:    t.Name,
+			Statement: t.statement(req),
+			Started:   time.Now(),
+		},
+		cancel: cancel,
+	}
+	t.activeMu.Unlock()
+
+	return ctx, func() {
+		t.activeMu.Lock()
+		delete(t.active, requestID)
+		t.activeMu.Unlock()
+		cancel()
+	}
+}
+
+func (t *Target) statement(req datasource.Request) string {
+	var requests []datasource.Request
+	switch req := req.(type) {
+	case request.ChainRequest:
+		requests = req
+	case request.BatchRequest:
+		requests = req
+	case request.TransactionRequest:
+		requests = req.Requests
+	case request.StreamRequest:
+		return t.statement(req.Request)
+	case request.TimeoutRequest:
+		return t.statement(req.Request)
+	default:
+		if r, ok := t.dataSource.(renderer); ok {
+			if _, statement, _, err := r.Render(req); err == nil {
+				return statement
+			}
+		}
+		return fmt.Sprintf("%T", req)
+	}
+
+	statements := make([]string, len(requests))
+	for i, req := range requests {
+		statements[i] = t.statement(req)
+	}
+	return strings.Join(statements, ";\n")
+}
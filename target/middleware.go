@@ -0,0 +1,183 @@
+package target
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+)
+
+type (
+	// Call is a request on its way to the data source, as middleware sees it. Chains, batches
+	// and transactions make a Call per request they hold, sharing their RequestID in chains
+	// and transactions. A stream makes one for the read it streams.
+	Call struct {
+		Target    string
+		RequestID int64
+		// Request is what is sent on, a middleware may replace it to rewrite the request.
+		Request datasource.Request
+		// ChunkSize is the number of rows per chunk of a stream, zero for other requests.
+		// The handler of a stream responds with a nil body once the chunks are sent.
+		ChunkSize int
+
+		dataSource datasource.DataSource
+		requester  requester
+		emit       func(chunk any) error
+	}
+
+	// Handler sends a Call, responding with the body and error of the response.
+	Handler func(ctx context.Context, call *Call) (any, error)
+
+	// Middleware wraps the Handler of a Target, it may act before and after calling next,
+	// or respond without calling it.
+	Middleware func(next Handler) Handler
+
+	// Layer sends requests to a Target through middleware of its own, which sees each Call before
+	// the middleware of the target. The target is left as it is, so it may be shared by several
+	// layers, as it is by the clients that open it.
+	Layer struct {
+		target  *Target
+		handler Handler
+	}
+)
+
+// handlerKey holds the Handler of a Layer for the requests sent through it.
+type handlerKey struct{}
+
+// SQL renders Request, false when the data source is not SQL or renders it on its own,
+// as it does for schema reads and bulk inserts.
+func (c *Call) SQL() (string, []any, bool) {
+	r, ok := c.dataSource.(renderer)
+	if !ok {
+		return "", nil, false
+	}
+	_, statement, args, err := r.Render(c.Request)
+	if err != nil {
+		return "", nil, false
+	}
+	return statement, args, true
+}
+
+// WithMiddleware adds middleware to the target, the first added being the first to see a Call.
+func WithMiddleware(middleware ...Middleware) TargetOption {
+	return func(ctx context.Context, t *Target) error {
+		t.middleware = append(t.middleware, middleware...)
+		t.handler = wrap(t.send, t.middleware)
+		return nil
+	}
+}
+
+// Through returns a Layer sending requests to t through middleware, the first being the first
+// to see a Call. Middleware added to t later is still seen after it.
+func (t *Target) Through(middleware ...Middleware) *Layer {
+	return &Layer{
+		target: t,
+		handler: wrap(func(ctx context.Context, call *Call) (any, error) {
+			return t.ownHandler()(ctx, call)
+		}, middleware),
+	}
+}
+
+// Target returns the target the layer sends requests to.
+func (l *Layer) Target() *Target {
+	return l.target
+}
+
+// Request is Target.Request through the middleware of the layer.
+func (l *Layer) Request(ctx context.Context, req datasource.Request) (chan *request.Response, error) {
+	return l.target.Request(context.WithValue(ctx, handlerKey{}, l.handler), req)
+}
+
+// Begin is Target.Begin, the requests of the returned Tx going through the middleware of the layer.
+func (l *Layer) Begin(ctx context.Context, opts datasource.TxOptions) (*Tx, error) {
+	tx, err := l.target.Begin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	tx.handler = l.handler
+	return tx, nil
+}
+
+// Blocking
+// PerformWithHandler is Target.PerformWithHandler through the middleware of the layer.
+func (l *Layer) PerformWithHandler(ctx context.Context, handler func(*request.Response), req datasource.Request) error {
+	return performWithHandler(ctx, l.Request, handler, req)
+}
+
+// Non-Blocking
+// RequestWithHandler is Target.RequestWithHandler through the middleware of the layer.
+func (l *Layer) RequestWithHandler(ctx context.Context, handler func(*request.Response), req datasource.Request) error {
+	return requestWithHandler(ctx, l.Request, handler, req)
+}
+
+// wrap wraps handler in middleware, the first being the outermost.
+func wrap(handler Handler, middleware []Middleware) Handler {
+	for _, middleware := range slices.Backward(middleware) {
+		handler = middleware(handler)
+	}
+	return handler
+}
+
+// send is the innermost Handler of a target, sending the call through its requester.
+func (t *Target) send(ctx context.Context, call *Call) (any, error) {
+	if call.emit != nil {
+		streamer, ok := call.requester.(datasource.Streamer)
+		if !ok {
+			return nil, ErrStreamingUnsupported
+		}
+		return nil, t.classify(streamer.Stream(ctx, call.Request, call.ChunkSize, call.emit))
+	}
+	body, err := call.requester.Request(ctx, call.Request)
+	return body, t.classify(err)
+}
+
+// handlerOf returns the Handler of the Layer a request was sent through, or that of the target.
+func (t *Target) handlerOf(ctx context.Context) Handler {
+	if handler, ok := ctx.Value(handlerKey{}).(Handler); ok {
+		return handler
+	}
+	return t.ownHandler()
+}
+
+// ownHandler returns the Handler of the target, wrapped by its middleware.
+func (t *Target) ownHandler() Handler {
+	if t.handler == nil {
+		return t.send
+	}
+	return t.handler
+}
+
+// perform sends req through the handler of r, cancelling it after its timeout when it is
+// a TimeoutRequest, and retrying it as the retry policy of the target allows when it is idempotent
+// and not part of a transaction.
+func (t *Target) perform(ctx context.Context, r requester, requestID int64, req datasource.Request) (any, error) {
+	if timeout, ok := req.(request.TimeoutRequest); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout.Timeout)
+		defer cancel()
+		req = timeout.Request
+	}
+	handler := t.handlerOf(ctx)
+	attempt := func() (any, error) {
+		body, err := handler(ctx, &Call{
+			Target:     t.Name,
+			RequestID:  requestID,
+			Request:    req,
+			dataSource: t.dataSource,
+			requester:  r,
+		})
+		// Middleware may respond with errors of the driver of its own
+		return body, t.classify(err)
+	}
+	if _, inTx := r.(datasource.Tx); inTx || !idempotent(req) {
+		return attempt()
+	}
+	return t.retry.do(ctx, attempt)
+}
+
+// classify wraps err in a *datasource.Error when the category of it is known.
+func (t *Target) classify(err error) error {
+	classifier, _ := t.dataSource.(datasource.ErrorClassifier)
+	return datasource.ClassifyWith(classifier, err)
+}
@@ -0,0 +1,118 @@
+package target
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+)
+
+// DefaultRetryPolicy makes three attempts, waiting up to 50ms and then up to 100ms in between.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   50 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+}
+
+// RetryPolicy runs a request again when it fails with an error worth retrying, waiting BaseDelay
+// doubled for each attempt made, capped at MaxDelay, and jittered by up to half of it.
+//
+// Only requests that can safely run twice are retried: reads made with the sql builders, prefabs
+// and whole transactions. Reads given as strings, as sql.FromString makes them, are not, as a
+// SELECT or WITH may write through a data-modifying WITH, nextval or FOR UPDATE. Neither are
+// requests sent through a Tx, streams, or writes outside a transaction. A built read whose fields
+// call functions with side effects is retried all the same.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt, a policy of one or less never retries.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Retryable reports whether a failed attempt is retried, IsRetryable when nil. A transaction
+	// failing with ErrCommitUnknown is never retried, it may have been committed.
+	Retryable func(err error) bool
+}
+
+// IsRetryable reports whether err is Transient, Conflict or Connection.
+func IsRetryable(err error) bool {
+	switch datasource.Classify(err) {
+	case datasource.ErrorTransient, datasource.ErrorConflict, datasource.ErrorConnection:
+		return true
+	}
+	return false
+}
+
+// WithRetry retries the requests of the target that fail as policy allows.
+func WithRetry(policy RetryPolicy) TargetOption {
+	return func(ctx context.Context, t *Target) error {
+		if policy.BaseDelay < 0 || policy.MaxDelay < 0 {
+			return errors.New("retry delays cannot be negative")
+		}
+		t.retry = policy
+		return nil
+	}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// backoff returns the time to wait after attempt, counted from 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for range attempt - 1 {
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+		delay *= 2
+	}
+	if p.MaxDelay > 0 {
+		delay = min(delay, p.MaxDelay)
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// do calls attempt until it succeeds, its error is not retryable or the attempts are used up.
+// It stops waiting once ctx is done, responding with the error of the last attempt.
+func (p RetryPolicy) do(ctx context.Context, attempt func() (any, error)) (any, error) {
+	for i := 1; ; i++ {
+		body, err := attempt()
+		if err == nil || i >= p.MaxAttempts || errors.Is(err, ErrCommitUnknown) || !p.retryable(err) {
+			return body, err
+		}
+		timer := time.NewTimer(p.backoff(i))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return body, err
+		case <-timer.C:
+		}
+	}
+}
+
+// idempotent reports whether req can run again without changing what it does.
+func idempotent(req datasource.Request) bool {
+	switch req := req.(type) {
+	case request.Intent:
+		return builtRead(&req)
+	case *request.Intent:
+		return builtRead(req)
+	}
+	// Every prefab is a read of the schema or of properties
+	return req.IsPrefab()
+}
+
+// builtRead reports whether intent is a read made by a builder, as the text of a query could be
+// anything that starts with SELECT or WITH.
+func builtRead(intent *request.Intent) bool {
+	_, text := intent.Operation.(string)
+	return intent.Type == datasource.Read && !text
+}
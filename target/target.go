@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/ctrl-alt-boop/dribble/datasource"
 	"github.com/ctrl-alt-boop/dribble/internal/adapters"
@@ -24,7 +25,16 @@ const (
 	TypeTable
 )
 
-var ErrNoRequests = errors.New("no requests provided")
+var (
+	ErrNoRequests           = errors.New("no requests provided")
+	ErrStreamingUnsupported = errors.New("data source does not support streaming")
+
+	ErrTransactionsUnsupported = errors.New("data source does not support transactions")
+	ErrNestedTransaction       = errors.New("transactions cannot be nested")
+
+	ErrRequestNotActive = errors.New("request is not running")
+	ErrCommitUnknown    = errors.New("connection lost during commit, the transaction may have been committed")
+)
 
 type Target struct {
 	nextRequestID atomic.Int64
@@ -34,6 +44,14 @@ type Target struct {
 	DBType        datasource.Type
 
 	dataSource datasource.DataSource
+
+	middleware []Middleware
+	handler    Handler
+
+	requestTimeout time.Duration
+	retry          RetryPolicy
+	activeMu       sync.Mutex
+	active         map[int64]*activeRequest
 }
 
 func New(name string, dsn datasource.Namer) (*Target, error) {
@@ -76,24 +94,47 @@ func (t *Target) Close(ctx context.Context) error {
 }
 
 func (t *Target) Request(ctx context.Context, req datasource.Request) (chan *request.Response, error) {
-	switch r := req.(type) {
+	return t.dispatch(ctx, t.dataSource, req)
+}
+
+// SQLAdapter returns the dialect of the target, false when it is not an SQL data source.
+func (t *Target) SQLAdapter() (datasource.SQLAdapter, bool) {
+	adapter, ok := t.dataSource.(datasource.SQLAdapter)
+	return adapter, ok
+}
+
+// requester is what requests are sent through, either the data source itself or an open transaction on it.
+type requester interface {
+	Request(ctx context.Context, req datasource.Request) (any, error)
+}
+
+func (t *Target) dispatch(ctx context.Context, r requester, req datasource.Request) (chan *request.Response, error) {
+	switch req := req.(type) {
+	case request.TimeoutRequest:
+		return t.dispatch(context.WithValue(ctx, timeoutKey{}, req.Timeout), r, req.Request)
 	case request.ChainRequest:
-		return t.chainedRequest(ctx, r)
+		return t.chainedRequest(ctx, r, req)
 	case request.BatchRequest:
-		return t.batchRequest(ctx, r)
+		return t.batchRequest(ctx, r, req)
+	case request.StreamRequest:
+		return t.streamRequest(ctx, r, req)
+	case request.TransactionRequest:
+		return t.transactionRequest(ctx, r, req)
 	default:
-		return t.simpleRequest(ctx, req)
+		return t.simpleRequest(ctx, r, req)
 	}
 }
 
-func (t *Target) simpleRequest(ctx context.Context, req datasource.Request) (chan *request.Response, error) {
+func (t *Target) simpleRequest(ctx context.Context, r requester, req datasource.Request) (chan *request.Response, error) {
 	requestID := t.nextRequestID.Add(1)
+	ctx, done := t.track(ctx, requestID, req)
 	resultChan := make(chan *request.Response, 1)
 
 	go func() {
 		defer close(resultChan)
 
-		requestResult, err := t.dataSource.Request(ctx, req)
+		requestResult, err := t.perform(ctx, r, requestID, req)
+		done()
 		var resp datasource.Response
 		if err != nil {
 			resp = req.ResponseOnError()
@@ -112,96 +153,272 @@ func (t *Target) simpleRequest(ctx context.Context, req datasource.Request) (cha
 	return resultChan, nil
 }
 
-func (t *Target) chainedRequest(ctx context.Context, requestChain request.ChainRequest) (chan *request.Response, error) {
+func (t *Target) chainedRequest(ctx context.Context, r requester, requestChain request.ChainRequest) (chan *request.Response, error) {
 	if len(requestChain) == 0 {
 		return nil, ErrNoRequests
 	}
 	requestID := t.nextRequestID.Add(1)
+	ctx, done := t.track(ctx, requestID, requestChain)
 	resultChan := make(chan *request.Response, 1)
-	responses := make([]*request.Response, len(requestChain))
 
 	go func() {
-		defer func() {
-			resultChan <- &request.Response{
-				Status:        request.Status(requestChain.ResponseOnError().Code()),
-				RequestID:     requestID,
-				RequestTarget: t.Name,
-				Body:          responses,
-				Error:         nil,
-			}
-			close(resultChan)
-		}()
-		for i, req := range requestChain {
-			requestResult, err := t.dataSource.Request(ctx, req)
-			var resp datasource.Response
-			if err != nil {
-				resp = req.ResponseOnError()
-			} else {
-				resp = req.ResponseOnSuccess()
-			}
-			responses[i] = &request.Response{
-				Status:        request.Status(resp.Code()),
-				RequestID:     int64(i),
-				RequestTarget: t.Name,
-				Body:          requestResult,
-				Error:         err,
-			}
+		defer close(resultChan)
 
-			if err != nil {
-				// If an error occurs in a chained request, stop the chain.
-				return
-			}
+		responses, err := t.performChain(ctx, r, requestID, requestChain)
+		done()
+		resp := requestChain.ResponseOnSuccess()
+		if err != nil {
+			resp = requestChain.ResponseOnError()
+		}
+		resultChan <- &request.Response{
+			Status:        request.Status(resp.Code()),
+			RequestID:     requestID,
+			RequestTarget: t.Name,
+			Body:          responses,
+			Error:         err,
 		}
 	}()
 
-	// requestID := t.nextRequestID.Add(1)
 	return resultChan, nil
 }
 
-func (t *Target) batchRequest(ctx context.Context, requestBatch request.BatchRequest) (chan *request.Response, error) {
+// performChain runs the requests of requestChain in order and stops at the first error.
+// The responses hold one entry per request, left nil for the requests that never ran.
+func (t *Target) performChain(ctx context.Context, r requester, requestID int64, requestChain request.ChainRequest) ([]*request.Response, error) {
+	responses := make([]*request.Response, len(requestChain))
+	for i, req := range requestChain {
+		requestResult, err := t.perform(ctx, r, requestID, req)
+		var resp datasource.Response
+		if err != nil {
+			resp = req.ResponseOnError()
+		} else {
+			resp = req.ResponseOnSuccess()
+		}
+		responses[i] = &request.Response{
+			Status:        request.Status(resp.Code()),
+			RequestID:     int64(i),
+			RequestTarget: t.Name,
+			Body:          requestResult,
+			Error:         err,
+		}
+
+		if err != nil {
+			// If an error occurs in a chained request, stop the chain.
+			return responses, err
+		}
+	}
+	return responses, nil
+}
+
+// batchRequest sends the requests of the batch concurrently, responding to each as it completes.
+// In a transaction they are sent one after another, as a transaction runs one statement at a time.
+func (t *Target) batchRequest(ctx context.Context, r requester, requestBatch request.BatchRequest) (chan *request.Response, error) {
 	numRequests := len(requestBatch)
 	if numRequests == 0 {
 		return nil, ErrNoRequests
 	}
 
 	resultChan := make(chan *request.Response, len(requestBatch))
+	send := func(req datasource.Request) {
+		requestID := t.nextRequestID.Add(1)
+		ctx, done := t.track(ctx, requestID, req)
+		requestResult, err := t.perform(ctx, r, requestID, req)
+		done()
+		var resp datasource.Response
+		if err != nil {
+			resp = req.ResponseOnError()
+		} else {
+			resp = req.ResponseOnSuccess()
+		}
+		resultChan <- &request.Response{
+			Status:        request.Status(resp.Code()),
+			RequestID:     requestID,
+			RequestTarget: t.Name,
+			Body:          requestResult,
+			Error:         err,
+		}
+	}
 
 	go func() {
 		defer close(resultChan)
 
+		if _, inTx := r.(datasource.Tx); inTx {
+			for _, req := range requestBatch {
+				send(req)
+			}
+			return
+		}
+
 		var wg sync.WaitGroup
 		wg.Add(numRequests)
 
 		for _, req := range requestBatch {
-			go func(r datasource.Request) {
+			go func(req datasource.Request) {
 				defer wg.Done()
-				requestID := t.nextRequestID.Add(1)
-				requestResult, err := t.dataSource.Request(ctx, r)
-				var resp datasource.Response
-				if err != nil {
-					resp = r.ResponseOnError()
-				} else {
-					resp = r.ResponseOnSuccess()
-				}
-				resultChan <- &request.Response{
-					Status:        request.Status(resp.Code()),
+				send(req)
+			}(req)
+		}
+		wg.Wait()
+	}()
+	return resultChan, nil
+}
+
+// streamRequest sends one response per chunk of the read, all sharing the same RequestID.
+// The read goes through the middleware of the target as a single Call.
+// Cancelling ctx stops the read, the channel is closed once the final chunk or an error has been sent.
+func (t *Target) streamRequest(ctx context.Context, r requester, stream request.StreamRequest) (chan *request.Response, error) {
+	if _, ok := r.(datasource.Streamer); !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	chunkSize := stream.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = request.DefaultChunkSize
+	}
+	requestID := t.nextRequestID.Add(1)
+	streamCtx, done := t.track(ctx, requestID, stream)
+	resultChan := make(chan *request.Response, 1)
+
+	go func() {
+		defer close(resultChan)
+
+		_, err := t.handlerOf(ctx)(streamCtx, &Call{
+			Target:     t.Name,
+			RequestID:  requestID,
+			Request:    stream.Request,
+			ChunkSize:  chunkSize,
+			dataSource: t.dataSource,
+			requester:  r,
+			emit: func(chunk any) error {
+				select {
+				case <-streamCtx.Done():
+					return streamCtx.Err()
+				case resultChan <- &request.Response{
+					Status:        request.Status(stream.ResponseOnSuccess().Code()),
 					RequestID:     requestID,
 					RequestTarget: t.Name,
-					Body:          requestResult,
-					Error:         err,
+					Body:          chunk,
+				}:
+					return nil
 				}
-			}(req)
+			},
+		})
+		done()
+		if err != nil {
+			err = t.classify(err)
+			select {
+			case <-ctx.Done():
+			case resultChan <- &request.Response{
+				Status:        request.Status(stream.ResponseOnError().Code()),
+				RequestID:     requestID,
+				RequestTarget: t.Name,
+				Error:         err,
+			}:
+			}
+		}
+	}()
+
+	return resultChan, nil
+}
+
+// transactionRequest runs the chain of txRequest in a transaction of its own, running the whole
+// transaction again as the retry policy of the target allows.
+func (t *Target) transactionRequest(ctx context.Context, r requester, txRequest request.TransactionRequest) (chan *request.Response, error) {
+	if len(txRequest.Requests) == 0 {
+		return nil, ErrNoRequests
+	}
+	if _, inTx := r.(datasource.Tx); inTx {
+		return nil, ErrNestedTransaction
+	}
+	transactor, ok := r.(datasource.Transactor)
+	if !ok {
+		return nil, ErrTransactionsUnsupported
+	}
+	requestID := t.nextRequestID.Add(1)
+	ctx, done := t.track(ctx, requestID, txRequest)
+	resultChan := make(chan *request.Response, 1)
+
+	go func() {
+		defer close(resultChan)
+
+		var responses []*request.Response
+		_, err := t.retry.do(ctx, func() (any, error) {
+			var err error
+			responses, err = t.performTransaction(ctx, transactor, requestID, txRequest)
+			return nil, err
+		})
+		done()
+
+		resp := txRequest.ResponseOnSuccess()
+		if err != nil {
+			resp = txRequest.ResponseOnError()
+		}
+		resultChan <- &request.Response{
+			Status:        request.Status(resp.Code()),
+			RequestID:     requestID,
+			RequestTarget: t.Name,
+			Body:          responses,
+			Error:         err,
 		}
-		wg.Wait()
 	}()
+
 	return resultChan, nil
 }
 
+// performTransaction runs the chain of txRequest in a transaction of its own, rolling it back
+// on the first error and committing it otherwise.
+func (t *Target) performTransaction(ctx context.Context, transactor datasource.Transactor, requestID int64, txRequest request.TransactionRequest) ([]*request.Response, error) {
+	tx, err := transactor.Begin(ctx, txRequest.Options)
+	if err != nil {
+		return nil, t.classify(err)
+	}
+	responses, err := t.performChain(ctx, tx, requestID, txRequest.Requests)
+	if err != nil {
+		return responses, errors.Join(err, tx.Rollback())
+	}
+	if err := t.classify(tx.Commit()); err != nil {
+		if datasource.Classify(err) == datasource.ErrorConnection {
+			// The commit may have reached the data source, running the transaction again could apply it twice
+			err = fmt.Errorf("%w: %w", ErrCommitUnknown, err)
+		}
+		return responses, err
+	}
+	return responses, nil
+}
+
+// Begin starts a transaction on the target, requests sent through the returned Tx
+// take effect when it is committed.
+func (t *Target) Begin(ctx context.Context, opts datasource.TxOptions) (*Tx, error) {
+	transactor, ok := t.dataSource.(datasource.Transactor)
+	if !ok {
+		return nil, ErrTransactionsUnsupported
+	}
+	tx, err := transactor.Begin(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{
+		target: t,
+		tx:     tx,
+	}, nil
+}
+
 // Blocking
 // PerformWithHandler sends requests and processes responses synchronously using a handler.
 // It blocks until all responses are received and handled or the context is cancelled.
 func (t *Target) PerformWithHandler(ctx context.Context, handler func(*request.Response), req datasource.Request) error {
-	resultChan, err := t.Request(ctx, req)
+	return performWithHandler(ctx, t.Request, handler, req)
+}
+
+// Non-Blocking
+// RequestWithHandler sends requests and processes responses asynchronously using a handler.
+func (t *Target) RequestWithHandler(ctx context.Context, handler func(*request.Response), req datasource.Request) error {
+	return requestWithHandler(ctx, t.Request, handler, req)
+}
+
+type requestFunc func(context.Context, datasource.Request) (chan *request.Response, error)
+
+func performWithHandler(ctx context.Context, requestFn requestFunc, handler func(*request.Response), req datasource.Request) error {
+	resultChan, err := requestFn(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -225,10 +442,8 @@ func (t *Target) PerformWithHandler(ctx context.Context, handler func(*request.R
 	}
 }
 
-// Non-Blocking
-// RequestWithHandler sends requests and processes responses asynchronously using a handler.
-func (t *Target) RequestWithHandler(ctx context.Context, handler func(*request.Response), req datasource.Request) error {
-	resultChan, err := t.Request(ctx, req)
+func requestWithHandler(ctx context.Context, requestFn requestFunc, handler func(*request.Response), req datasource.Request) error {
+	resultChan, err := requestFn(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -285,6 +500,15 @@ func WithDataSource(dsn datasource.Namer) TargetOption {
 	}
 }
 
+// WithRequestTimeout cancels the requests of the target that run for longer than timeout,
+// unless they are wrapped in a request.TimeoutRequest of their own. Zero means no timeout.
+func WithRequestTimeout(timeout time.Duration) TargetOption {
+	return func(ctx context.Context, t *Target) error {
+		t.requestTimeout = timeout
+		return nil
+	}
+}
+
 func WithName(newName string) TargetOption {
 	return func(ctx context.Context, t *Target) error {
 		t.Name = newName
@@ -0,0 +1,44 @@
+package target
+
+import (
+	"context"
+
+	"github.com/ctrl-alt-boop/dribble/datasource"
+	"github.com/ctrl-alt-boop/dribble/request"
+)
+
+// Tx is a transaction on a Target, see Target.Begin.
+// It is used like the Target itself until Commit or Rollback is called.
+type Tx struct {
+	target *Target
+	tx     datasource.Tx
+	// handler is that of the Layer that began the transaction, nil for the target itself.
+	handler Handler
+}
+
+func (tx *Tx) Request(ctx context.Context, req datasource.Request) (chan *request.Response, error) {
+	if tx.handler != nil {
+		ctx = context.WithValue(ctx, handlerKey{}, tx.handler)
+	}
+	return tx.target.dispatch(ctx, tx.tx, req)
+}
+
+// Blocking
+// PerformWithHandler sends requests in the transaction and processes responses synchronously using a handler.
+func (tx *Tx) PerformWithHandler(ctx context.Context, handler func(*request.Response), req datasource.Request) error {
+	return performWithHandler(ctx, tx.Request, handler, req)
+}
+
+// Non-Blocking
+// RequestWithHandler sends requests in the transaction and processes responses asynchronously using a handler.
+func (tx *Tx) RequestWithHandler(ctx context.Context, handler func(*request.Response), req datasource.Request) error {
+	return requestWithHandler(ctx, tx.Request, handler, req)
+}
+
+func (tx *Tx) Commit() error {
+	return tx.tx.Commit()
+}
+
+func (tx *Tx) Rollback() error {
+	return tx.tx.Rollback()
+}